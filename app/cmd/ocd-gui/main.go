@@ -1,17 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
+	"syscall"
 	"time"
 
 	configurationpkg "app/internal/config"
+	"app/internal/deploymap"
 	"app/internal/executor"
 	httpapi "app/internal/http"
 	"app/internal/jenkins"
+	"app/internal/jenkins/services"
+	"app/internal/kube"
 	"app/internal/logging"
 	"app/internal/ui"
 )
@@ -31,10 +38,19 @@ func main() {
 
 	fmt.Printf("[%s] Loading configuration...\n", time.Now().Format("15:04:05.000"))
 	configuration := configurationpkg.Load()
-	runner := executor.NewRunner(executor.NewCommandExecutor(configuration))
-	jenkinsClient, err := jenkins.NewClient(configuration.Jenkins)
+	mappings, err := deploymap.NewStore(configuration.Kube.MappingsFile)
 	if err != nil {
-		log.Fatalf("Failed to create Jenkins client: %v", err)
+		log.Fatalf("Failed to load microservice mappings: %v", err)
+	}
+	kubeClients := kube.NewProvider(configuration.Kube.KubeconfigPath, configuration.Kube.Context, configuration.Kube.MicroserviceAPI, executor.KubeShell(configuration))
+	runner := executor.NewRunner(executor.NewCommandExecutor(configuration, mappings, kubeClients))
+	jenkinsPool, err := jenkins.NewPool(configuration.JenkinsInstances)
+	if err != nil {
+		log.Fatalf("Failed to create Jenkins client pool: %v", err)
+	}
+	serviceManager, err := services.NewServiceManager(configuration, jenkinsPool, logger)
+	if err != nil {
+		log.Fatalf("Failed to create Jenkins services: %v", err)
 	}
 	fmt.Printf("[%s] Configuration loaded in %v\n", time.Now().Format("15:04:05.000"), time.Since(startTime))
 
@@ -45,28 +61,35 @@ func main() {
 	mux.HandleFunc("/api/browse", httpapi.HandleBrowse)
 	mux.HandleFunc("/api/deploy", httpapi.HandleDeploy(runner))
 	mux.HandleFunc("/api/health", httpapi.HandleHealth)
+	mux.HandleFunc("/api/health/dependencies", httpapi.HandleHealthDependencies(serviceManager.GetMonitoringService()))
 
 	// Jenkins routes using new service architecture
-	jenkinsHandlers := httpapi.NewJenkinsHandlers(configuration, jenkinsClient)
+	jenkinsHandlers := httpapi.NewJenkinsHandlers(configuration, serviceManager)
 	jenkinsHandlers.RegisterJenkinsRoutes(mux)
 
 	// AWS EKS routes
-	mux.HandleFunc("/api/eks/clusters", httpapi.HandleEKSClusters)
+	mux.HandleFunc("/api/eks/clusters", httpapi.HandleEKSClusters(serviceManager.GetClusterInventory()))
+
+	// Kubernetes routes
+	mux.HandleFunc("/api/kube/namespaces", httpapi.HandleKubeNamespaces(configuration, kubeClients))
+	mux.HandleFunc("/api/kube/microservices", httpapi.HandleKubeMicroservices(configuration, kubeClients))
+	mux.HandleFunc("/api/kube/helm-releases", httpapi.HandleKubeHelmReleases(kubeClients))
 
 	// Git routes
-	mux.HandleFunc("/api/git/branches/customization", httpapi.HandleGitBranchesCustomization(configuration))
+	mux.HandleFunc("/api/git/branches/customization", httpapi.HandleGitBranchesCustomization(configuration, serviceManager.GetBitbucketClient()))
 
 	// RN Creation routes
 	mux.HandleFunc("/api/rn/create", httpapi.HandleRNCreate(configuration))
 
 	// HF Adoption routes
 	mux.HandleFunc("/api/hf/parse-email", httpapi.HandleHFParseEmail)
-	mux.HandleFunc("/api/hf/update-pom", httpapi.HandleHFUpdatePOM)
+	mux.HandleFunc("/api/hf/update-pom", httpapi.HandleHFUpdatePOM(configuration, serviceManager.GetBitbucketClient()))
 
 	// SSE-based deployment routes
 	mux.HandleFunc("/api/deploy/start", httpapi.HandleDeployStart(configuration, runner))
 	mux.HandleFunc("/api/deploy/stream/", httpapi.HandleDeployStream)
 	mux.HandleFunc("/api/deploy/cancel/", httpapi.HandleDeployCancel)
+	mux.HandleFunc("/api/deploy/mappings", httpapi.HandleDeployMappings(configuration, mappings))
 	mux.HandleFunc("/api/config/public", httpapi.HandlePublicConfig(configuration))
 
 	logger.Info("Routes configured successfully")
@@ -90,7 +113,29 @@ func main() {
 		IdleTimeout:       90 * time.Second,
 	}
 
-	log.Fatal(server.ListenAndServe())
+	// Stop the server and background Jenkins work on Ctrl+C or termination
+	stopped := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+
+		logger.Info("Shutting down...")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Errorf("Server shutdown: %v", err)
+		}
+		if err := serviceManager.Shutdown(ctx); err != nil {
+			logger.Errorf("Service shutdown: %v", err)
+		}
+		close(stopped)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+	<-stopped
 }
 
 func openBrowser(url string) {
@@ -0,0 +1,255 @@
+// Package bitbucket is a client for the Bitbucket Server REST API covering the
+// repository operations OCD needs: branches, commits, tags, file content and
+// pull requests. Paged endpoints are followed to the last page.
+package bitbucket
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout  = 30 * time.Second
+	defaultPageSize = 100
+
+	// maxPages bounds how many pages one listing follows
+	maxPages = 100
+)
+
+// Config describes how to reach a Bitbucket Server
+type Config struct {
+	BaseURL            string
+	ProjectKey         string // default project for repository calls
+	Username           string // default credentials, overridable per request with WithCredentials
+	Token              string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+}
+
+// Client talks to one Bitbucket Server. It is safe for concurrent use;
+// WithCredentials returns copies sharing the same connections.
+type Client struct {
+	baseURL    string
+	projectKey string
+	username   string
+	token      string
+	httpClient *http.Client
+}
+
+// New creates a Bitbucket client
+func New(config Config) *Client {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	if config.InsecureSkipVerify {
+		httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		projectKey: config.ProjectKey,
+		username:   config.Username,
+		token:      config.Token,
+		httpClient: httpClient,
+	}
+}
+
+// WithCredentials returns a client using the given credentials. Blank
+// credentials keep the configured defaults.
+func (c *Client) WithCredentials(username, token string) *Client {
+	username = strings.TrimSpace(username)
+	token = strings.TrimSpace(token)
+	if username == "" || token == "" {
+		return c
+	}
+	scoped := *c
+	scoped.username = username
+	scoped.token = token
+	return &scoped
+}
+
+// WithProject returns a client whose repository calls target another
+// project. A blank key keeps the configured default.
+func (c *Client) WithProject(projectKey string) *Client {
+	projectKey = strings.TrimSpace(projectKey)
+	if projectKey == "" || projectKey == c.projectKey {
+		return c
+	}
+	scoped := *c
+	scoped.projectKey = projectKey
+	return &scoped
+}
+
+// HasCredentials reports whether the client will authenticate its requests
+func (c *Client) HasCredentials() bool {
+	return c.username != "" && c.token != ""
+}
+
+// CredentialsFingerprint identifies the credentials in use without revealing
+// them, for keying per-user caches
+func (c *Client) CredentialsFingerprint() string {
+	sum := sha256.Sum256([]byte(c.username + "\x00" + c.token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Username returns the user requests are made as
+func (c *Client) Username() string {
+	return c.username
+}
+
+// BaseURL returns the Bitbucket Server URL
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// ProjectKey returns the default project
+func (c *Client) ProjectKey() string {
+	return c.projectKey
+}
+
+// ParseCloneURL extracts the project key and repository slug from a clone
+// URL (https://host/scm/PROJECT/repo.git) or a repository browse URL
+// (https://host/projects/PROJECT/repos/repo)
+func ParseCloneURL(rawURL string) (projectKey, repo string, err error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid repository URL %q: %w", rawURL, err)
+	}
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i := range segments {
+		switch {
+		case segments[i] == "scm" && i+2 < len(segments):
+			projectKey, repo = segments[i+1], segments[i+2]
+		case segments[i] == "projects" && i+3 < len(segments) && segments[i+2] == "repos":
+			projectKey, repo = segments[i+1], segments[i+3]
+		default:
+			continue
+		}
+		return strings.ToUpper(projectKey), strings.TrimSuffix(repo, ".git"), nil
+	}
+	return "", "", fmt.Errorf("repository URL %q is not a Bitbucket Server repository URL", rawURL)
+}
+
+// page is one page of a Bitbucket paged API response
+type page struct {
+	Size          int               `json:"size"`
+	Limit         int               `json:"limit"`
+	IsLastPage    bool              `json:"isLastPage"`
+	Start         int               `json:"start"`
+	NextPageStart int               `json:"nextPageStart"`
+	Values        []json.RawMessage `json:"values"`
+}
+
+// getAll follows a paged endpoint until its last page, or until max values
+// have been read when max is positive, decoding every value into T
+func getAll[T any](ctx context.Context, c *Client, path string, params url.Values, max int) ([]T, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+	pageSize := defaultPageSize
+	if max > 0 && max < pageSize {
+		pageSize = max
+	}
+	params.Set("limit", strconv.Itoa(pageSize))
+
+	var values []T
+	start := 0
+	for pages := 0; pages < maxPages; pages++ {
+		params.Set("start", strconv.Itoa(start))
+		body, err := c.do(ctx, http.MethodGet, path+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var current page
+		if err := json.Unmarshal(body, &current); err != nil {
+			return nil, fmt.Errorf("failed to decode Bitbucket response from %s: %w", path, err)
+		}
+		for _, raw := range current.Values {
+			var value T
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("failed to decode Bitbucket value from %s: %w", path, err)
+			}
+			values = append(values, value)
+			if max > 0 && len(values) >= max {
+				return values, nil
+			}
+		}
+
+		// Guard against servers that report no progress
+		if current.IsLastPage || len(current.Values) == 0 || current.NextPageStart <= start {
+			return values, nil
+		}
+		start = current.NextPageStart
+	}
+	return values, fmt.Errorf("Bitbucket listing %s returned more than %d pages", path, maxPages)
+}
+
+// repoPath returns the REST path of a repository in the default project
+func (c *Client) repoPath(repo string) string {
+	return "/rest/api/1.0/projects/" + url.PathEscape(c.projectKey) + "/repos/" + url.PathEscape(repo)
+}
+
+// getJSON fetches a single resource and decodes it into target
+func (c *Client) getJSON(ctx context.Context, path string, target interface{}) error {
+	body, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("failed to decode Bitbucket response from %s: %w", path, err)
+	}
+	return nil
+}
+
+// do sends a request and returns the body of a 2xx response, or an *Error
+func (c *Client) do(ctx context.Context, method, path string, payload interface{}) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		body = strings.NewReader(string(encoded))
+	}
+
+	target := c.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Bitbucket request: %w", err)
+	}
+	if c.HasCredentials() {
+		req.SetBasicAuth(c.username, c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Bitbucket request to %s failed: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Bitbucket response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newError(method, target, resp.StatusCode, data)
+	}
+	return data, nil
+}
@@ -0,0 +1,80 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is a non-2xx response from Bitbucket
+type Error struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Messages   []string // from the Bitbucket error body, if any
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	detail := strings.Join(e.Messages, "; ")
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return "authentication failed for Bitbucket (status 401). Please check your credentials"
+	case http.StatusForbidden:
+		return fmt.Sprintf("access denied by Bitbucket (status 403): %s", detail)
+	case http.StatusNotFound:
+		return fmt.Sprintf("not found in Bitbucket (status 404): %s", detail)
+	}
+	if detail == "" {
+		detail = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("Bitbucket API request failed with status %d: %s", e.StatusCode, detail)
+}
+
+// newError builds an *Error from a response, reading Bitbucket's error envelope
+func newError(method, url string, statusCode int, body []byte) *Error {
+	bitbucketErr := &Error{Method: method, URL: url, StatusCode: statusCode}
+
+	var envelope struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		for _, item := range envelope.Errors {
+			if item.Message != "" {
+				bitbucketErr.Messages = append(bitbucketErr.Messages, item.Message)
+			}
+		}
+	}
+	if len(bitbucketErr.Messages) == 0 && len(body) > 0 && len(body) < 512 {
+		bitbucketErr.Messages = []string{strings.TrimSpace(string(body))}
+	}
+	return bitbucketErr
+}
+
+// StatusCode returns the HTTP status of a Bitbucket error, or 0 for other errors
+func StatusCode(err error) int {
+	var bitbucketErr *Error
+	if errors.As(err, &bitbucketErr) {
+		return bitbucketErr.StatusCode
+	}
+	return 0
+}
+
+// IsUnauthorized reports whether Bitbucket rejected the credentials
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+// IsNotFound reports whether the repository, branch or resource does not exist
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether the request conflicts with existing state, e.g. a duplicate pull request
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
@@ -0,0 +1,288 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Branch is a repository branch
+type Branch struct {
+	ID           string `json:"id"` // refs/heads/...
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+	IsDefault    bool   `json:"isDefault"`
+	Metadata     struct {
+		LatestCommit *Commit      `json:"com.atlassian.bitbucket.server.bitbucket-branch:latest-commit-metadata,omitempty"`
+		AheadBehind  *AheadBehind `json:"com.atlassian.bitbucket.server.bitbucket-branch:ahead-behind-metadata-provider,omitempty"`
+	} `json:"metadata"`
+}
+
+// AheadBehind counts the commits a branch has that the default branch lacks
+// (ahead) and the other way round (behind)
+type AheadBehind struct {
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+}
+
+// LatestCommitTime returns when the branch head was committed, zero unless
+// the branch was listed with details
+func (b Branch) LatestCommitTime() time.Time {
+	if b.Metadata.LatestCommit == nil {
+		return time.Time{}
+	}
+	return b.Metadata.LatestCommit.CommitterTime()
+}
+
+// User is a Bitbucket user or commit author
+type User struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+	Slug         string `json:"slug,omitempty"`
+}
+
+// Commit is a repository commit
+type Commit struct {
+	ID                 string `json:"id"`
+	DisplayID          string `json:"displayId"`
+	Message            string `json:"message"`
+	Author             User   `json:"author"`
+	AuthorTimestamp    int64  `json:"authorTimestamp"`    // milliseconds
+	CommitterTimestamp int64  `json:"committerTimestamp"` // milliseconds
+	Parents            []struct {
+		ID string `json:"id"`
+	} `json:"parents,omitempty"`
+}
+
+// CommitterTime returns the commit time
+func (c Commit) CommitterTime() time.Time {
+	return time.UnixMilli(c.CommitterTimestamp)
+}
+
+// Tag is a repository tag
+type Tag struct {
+	ID           string `json:"id"` // refs/tags/...
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+	Hash         string `json:"hash,omitempty"` // annotated tags only
+}
+
+// Ref is one side of a pull request
+type Ref struct {
+	ID           string         `json:"id"`
+	DisplayID    string         `json:"displayId,omitempty"`
+	LatestCommit string         `json:"latestCommit,omitempty"`
+	Repository   *RepositoryRef `json:"repository,omitempty"`
+}
+
+// RepositoryRef identifies a repository by project key and slug
+type RepositoryRef struct {
+	Slug    string `json:"slug"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+}
+
+// PullRequest is a Bitbucket pull request
+type PullRequest struct {
+	ID          int    `json:"id"`
+	Version     int    `json:"version"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"` // OPEN, MERGED, DECLINED
+	Open        bool   `json:"open"`
+	FromRef     Ref    `json:"fromRef"`
+	ToRef       Ref    `json:"toRef"`
+	Author      struct {
+		User User `json:"user"`
+	} `json:"author"`
+	CreatedDate int64 `json:"createdDate"` // milliseconds
+	UpdatedDate int64 `json:"updatedDate"`
+	Links       struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+// URL returns the web URL of the pull request
+func (p PullRequest) URL() string {
+	if len(p.Links.Self) > 0 {
+		return p.Links.Self[0].Href
+	}
+	return ""
+}
+
+// BranchQuery filters a branch listing
+type BranchQuery struct {
+	Filter  string // substring of the branch name
+	Details bool   // include latest commit metadata
+	OrderBy string // "ALPHABETICAL" or "MODIFICATION"
+	Max     int    // 0 lists every branch
+}
+
+// CommitQuery selects commits, newest first
+type CommitQuery struct {
+	Until string // branch, tag or commit to list back from
+	Since string // exclusive lower bound
+	Path  string // only commits touching this path
+	Max   int    // 0 lists every commit
+}
+
+// PullRequestQuery filters a pull request listing
+type PullRequestQuery struct {
+	State     string // OPEN (default), MERGED, DECLINED or ALL
+	At        string // fully qualified ref the pull requests involve, e.g. refs/heads/main
+	Direction string // INCOMING (default, At is the target) or OUTGOING (At is the source)
+	Max       int
+}
+
+// Branches lists the branches of a repository
+func (c *Client) Branches(ctx context.Context, repo string, query BranchQuery) ([]Branch, error) {
+	params := url.Values{}
+	if query.Filter != "" {
+		params.Set("filterText", query.Filter)
+	}
+	if query.Details {
+		params.Set("details", "true")
+	}
+	if query.OrderBy != "" {
+		params.Set("orderBy", query.OrderBy)
+	}
+	return getAll[Branch](ctx, c, c.repoPath(repo)+"/branches", params, query.Max)
+}
+
+// DefaultBranch returns the default branch of a repository
+func (c *Client) DefaultBranch(ctx context.Context, repo string) (*Branch, error) {
+	var branch Branch
+	if err := c.getJSON(ctx, c.repoPath(repo)+"/branches/default", &branch); err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+// Commits lists commits of a repository, newest first
+func (c *Client) Commits(ctx context.Context, repo string, query CommitQuery) ([]Commit, error) {
+	params := url.Values{}
+	if query.Until != "" {
+		params.Set("until", query.Until)
+	}
+	if query.Since != "" {
+		params.Set("since", query.Since)
+	}
+	if query.Path != "" {
+		params.Set("path", query.Path)
+	}
+	return getAll[Commit](ctx, c, c.repoPath(repo)+"/commits", params, query.Max)
+}
+
+// Tags lists the tags of a repository, optionally filtered by name
+func (c *Client) Tags(ctx context.Context, repo, filter string, max int) ([]Tag, error) {
+	params := url.Values{}
+	if filter != "" {
+		params.Set("filterText", filter)
+	}
+	params.Set("orderBy", "MODIFICATION")
+	return getAll[Tag](ctx, c, c.repoPath(repo)+"/tags", params, max)
+}
+
+// FileContent returns the raw content of a file at a branch, tag or commit.
+// An empty ref reads the default branch.
+func (c *Client) FileContent(ctx context.Context, repo, path, ref string) ([]byte, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	target := c.repoPath(repo) + "/raw/" + strings.Join(segments, "/")
+	if ref != "" {
+		target += "?at=" + url.QueryEscape(ref)
+	}
+	return c.do(ctx, http.MethodGet, target, nil)
+}
+
+// PullRequests lists pull requests of a repository
+func (c *Client) PullRequests(ctx context.Context, repo string, query PullRequestQuery) ([]PullRequest, error) {
+	params := url.Values{}
+	if query.State != "" {
+		params.Set("state", query.State)
+	}
+	if query.At != "" {
+		params.Set("at", query.At)
+	}
+	if query.Direction != "" {
+		params.Set("direction", query.Direction)
+	}
+	return getAll[PullRequest](ctx, c, c.repoPath(repo)+"/pull-requests", params, query.Max)
+}
+
+// PullRequest returns one pull request by ID
+func (c *Client) PullRequest(ctx context.Context, repo string, id int) (*PullRequest, error) {
+	var pullRequest PullRequest
+	if err := c.getJSON(ctx, c.repoPath(repo)+"/pull-requests/"+strconv.Itoa(id), &pullRequest); err != nil {
+		return nil, err
+	}
+	return &pullRequest, nil
+}
+
+// NewPullRequest describes a pull request to open between two branches of
+// the same repository
+type NewPullRequest struct {
+	Title       string
+	Description string
+	FromBranch  string
+	ToBranch    string
+	Reviewers   []string // Bitbucket user names
+}
+
+// CreatePullRequest opens a pull request and returns it as created
+func (c *Client) CreatePullRequest(ctx context.Context, repo string, input NewPullRequest) (*PullRequest, error) {
+	repository := &RepositoryRef{Slug: repo}
+	repository.Project.Key = c.projectKey
+
+	type reviewer struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+	reviewers := make([]reviewer, 0, len(input.Reviewers))
+	for _, name := range input.Reviewers {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		var r reviewer
+		r.User.Name = name
+		reviewers = append(reviewers, r)
+	}
+
+	payload := map[string]interface{}{
+		"title":       input.Title,
+		"description": input.Description,
+		"fromRef":     Ref{ID: branchRef(input.FromBranch), Repository: repository},
+		"toRef":       Ref{ID: branchRef(input.ToBranch), Repository: repository},
+		"reviewers":   reviewers,
+	}
+	body, err := c.do(ctx, http.MethodPost, c.repoPath(repo)+"/pull-requests", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var pullRequest PullRequest
+	if err := json.Unmarshal(body, &pullRequest); err != nil {
+		return nil, fmt.Errorf("failed to decode created pull request: %w", err)
+	}
+	return &pullRequest, nil
+}
+
+// branchRef returns the fully qualified ref of a branch name
+func branchRef(branch string) string {
+	if strings.HasPrefix(branch, "refs/") {
+		return branch
+	}
+	return "refs/heads/" + branch
+}
@@ -1,22 +1,31 @@
 package config
 
 import (
-	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
 type Config struct {
-	Port           string
-	WSLUser        string
-	ScriptName     string
-	AllowedOrigins []string
-	MaxOutputLines int
-	CommandTimeout int // seconds
-	Jenkins        JenkinsConfig
-	TLS            TLSConfig
-	Endpoints      Endpoints
+	Port             string
+	WSLUser          string
+	ScriptName       string
+	AllowedOrigins   []string
+	MaxOutputLines   int
+	CommandTimeout   int // seconds
+	Jenkins          JenkinsConfig
+	JenkinsInstances map[string]JenkinsInstanceConfig
+	TLS              TLSConfig
+	Endpoints        Endpoints
+	ArtifactCache    ArtifactCacheConfig
+	Nexus            NexusConfig
+	Bitbucket        BitbucketConfig
+	HF               HFConfig
+	Scaling          ScalingConfig
+	EKS              EKSConfig
+	Kube             KubeConfig
 }
 
 type JenkinsConfig struct {
@@ -25,6 +34,88 @@ type JenkinsConfig struct {
 	Token    string
 }
 
+// Names of the Jenkins instances OCD talks to
+const (
+	JenkinsInstanceDelivery = "delivery"
+	JenkinsInstanceStorage  = "storage"
+)
+
+// Where a Jenkins instance gets its credentials from
+const (
+	CredentialsFromEnv     = "env"     // configured username/token, request credentials still take precedence
+	CredentialsFromRequest = "request" // only credentials supplied with each request
+)
+
+// JenkinsInstanceConfig describes one named Jenkins server
+type JenkinsInstanceConfig struct {
+	Name               string
+	URL                string
+	CredentialsSource  string
+	Username           string
+	Token              string
+	InsecureSkipVerify bool
+	TimeoutSeconds     int // 0 uses the jobs.json default
+}
+
+// BitbucketConfig holds default Bitbucket credentials; requests may supply their own
+type BitbucketConfig struct {
+	Username string
+	Token    string
+}
+
+// How HF adoption delivers parent-pom changes
+const (
+	HFUpdateModePush        = "push"         // commit straight to the target branch
+	HFUpdateModePullRequest = "pull_request" // push a feature branch and open a pull request
+)
+
+// HFConfig controls HF adoption parent-pom updates
+type HFConfig struct {
+	UpdateMode string   // default mode when a request does not choose one
+	Reviewers  []string // Bitbucket user names added to every HF pull request
+}
+
+// NexusConfig holds optional Nexus credentials; anonymous access is used without them
+type NexusConfig struct {
+	Username string
+	Password string
+}
+
+// EKSConfig controls cluster discovery
+type EKSConfig struct {
+	Regions             []string // regions searched for clusters; empty uses the AWS CLI default
+	InventoryTTLSeconds int
+}
+
+// KubeConfig controls direct access to the cluster OCD deploys to
+type KubeConfig struct {
+	KubeconfigPath       string // empty uses the kubeconfig kubectl sees in the deploy shell: the WSL user's on Windows
+	Context              string // empty uses the kubeconfig's current context
+	MicroserviceAPI      string // group/version of the microservice resource; discovered when empty
+	DefaultNamespace     string // namespace a deploy patches unless another is chosen, as in the scripts
+	MappingsFile         string // service to microservice mappings confirmed by the user
+	VerifyRollout        bool   // watch the patched pods become ready after a deploy
+	VerifyTimeoutSeconds int
+}
+
+// ScalingConfig controls scheduled cluster scaling and the scaling policy.
+// Cluster patterns are case-insensitive globs such as *prod*.
+type ScalingConfig struct {
+	SchedulesFile         string   // where scaling schedules and their run history are kept
+	AuditFile             string   // JSON lines record of every scaling attempt
+	GroupsFile            string   // JSON object of group name -> cluster names or globs, for batch scaling
+	ProtectedClusters     []string // never scaled down
+	ConfirmClusters       []string // production-like clusters that need a typed confirmation phrase
+	BusinessHours         string   // e.g. "mon-fri 08:00-19:00": no scale-down in this window; empty disables
+	BusinessHoursTimezone string   // IANA name, defaults to the local timezone
+}
+
+// ArtifactCacheConfig controls the local content-addressed artifact cache
+type ArtifactCacheConfig struct {
+	Dir       string
+	MaxSizeMB int // least recently used artifacts are evicted beyond this size
+}
+
 type TLSConfig struct {
 	InsecureSkipVerify bool
 }
@@ -33,6 +124,7 @@ type Endpoints struct {
 	StorageJenkinsBaseURL       string
 	StorageJenkinsJobPath       string
 	CustomizationJenkinsBaseURL string
+	CustomizationJenkinsJobPath string
 	ScalingJenkinsJobPath       string
 	BitbucketBaseURL            string
 	BitbucketProjectKey         string
@@ -44,22 +136,124 @@ type Endpoints struct {
 }
 
 func Load() *Config {
+	jenkins := JenkinsConfig{
+		URL:      getEnvOrDefault("OCD_JENKINS_URL", "https://jenkins-delivery.oss.corp.amdocs.aws"),
+		Username: getEnvOrDefault("OCD_JENKINS_USERNAME", ""),
+		Token:    getEnvOrDefault("OCD_JENKINS_TOKEN", ""),
+	}
+	tlsConfig := TLSConfig{
+		InsecureSkipVerify: getEnvBoolOrDefault("OCD_TLS_INSECURE_SKIP_VERIFY", false),
+	}
+	endpoints := defaultEndpoints()
+	instances := loadJenkinsInstances(jenkins, tlsConfig, endpoints)
+
+	// Keep the public endpoints in line with the instance registry
+	endpoints.CustomizationJenkinsBaseURL = instances[JenkinsInstanceDelivery].URL
+	endpoints.StorageJenkinsBaseURL = instances[JenkinsInstanceStorage].URL
+
 	return &Config{
-		Port:           getEnvOrDefault("OCD_PORT", "2111"),
-		WSLUser:        getEnvOrDefault("OCD_WSL_USER", "k8s"),
-		ScriptName:     getEnvOrDefault("OCD_SCRIPT_NAME", "OCD.sh"),
-		AllowedOrigins: getAllowedOrigins(),
-		CommandTimeout: getEnvIntOrDefault("OCD_COMMAND_TIMEOUT", 1800),
-		Jenkins: JenkinsConfig{
-			URL:      getEnvOrDefault("OCD_JENKINS_URL", "https://jenkins-delivery.oss.corp.amdocs.aws"),
-			Username: getEnvOrDefault("OCD_JENKINS_USERNAME", ""),
-			Token:    getEnvOrDefault("OCD_JENKINS_TOKEN", ""),
+		Port:             getEnvOrDefault("OCD_PORT", "2111"),
+		WSLUser:          getEnvOrDefault("OCD_WSL_USER", "k8s"),
+		ScriptName:       getEnvOrDefault("OCD_SCRIPT_NAME", "OCD.sh"),
+		AllowedOrigins:   getAllowedOrigins(),
+		CommandTimeout:   getEnvIntOrDefault("OCD_COMMAND_TIMEOUT", 1800),
+		Jenkins:          jenkins,
+		JenkinsInstances: instances,
+		TLS:              tlsConfig,
+		Endpoints:        endpoints,
+		ArtifactCache: ArtifactCacheConfig{
+			Dir:       getEnvOrDefault("OCD_ARTIFACT_CACHE_DIR", defaultArtifactCacheDir()),
+			MaxSizeMB: getEnvIntOrDefault("OCD_ARTIFACT_CACHE_MAX_MB", 2048),
 		},
-		TLS: TLSConfig{
-			InsecureSkipVerify: getEnvBoolOrDefault("OCD_TLS_INSECURE_SKIP_VERIFY", false),
+		Bitbucket: BitbucketConfig{
+			Username: getEnvOrDefault("BITBUCKET_USERNAME", ""),
+			Token:    getEnvOrDefault("BITBUCKET_TOKEN", ""),
 		},
-		Endpoints: defaultEndpoints(),
+		EKS: EKSConfig{
+			Regions:             getEnvListOrDefault("OCD_EKS_REGIONS", nil),
+			InventoryTTLSeconds: getEnvIntOrDefault("OCD_EKS_INVENTORY_TTL", 300),
+		},
+		Kube: KubeConfig{
+			KubeconfigPath:       getEnvOrDefault("OCD_KUBECONFIG", ""),
+			Context:              getEnvOrDefault("OCD_KUBE_CONTEXT", ""),
+			MicroserviceAPI:      getEnvOrDefault("OCD_KUBE_MICROSERVICE_API", ""),
+			DefaultNamespace:     getEnvOrDefault("OCD_KUBE_NAMESPACE", "dop"),
+			MappingsFile:         getEnvOrDefault("OCD_MICROSERVICE_MAPPINGS_FILE", defaultOCDFile("microservice-mappings.json")),
+			VerifyRollout:        getEnvBoolOrDefault("OCD_DEPLOY_VERIFY", true),
+			VerifyTimeoutSeconds: getEnvIntOrDefault("OCD_DEPLOY_VERIFY_TIMEOUT", 600),
+		},
+		Scaling: ScalingConfig{
+			SchedulesFile:         getEnvOrDefault("OCD_SCALING_SCHEDULES_FILE", defaultOCDFile("scaling-schedules.json")),
+			AuditFile:             getEnvOrDefault("OCD_SCALING_AUDIT_FILE", defaultOCDFile("scaling-audit.jsonl")),
+			GroupsFile:            getEnvOrDefault("OCD_SCALING_GROUPS_FILE", defaultOCDFile("scaling-groups.json")),
+			ProtectedClusters:     getEnvListOrDefault("OCD_SCALING_PROTECTED_CLUSTERS", nil),
+			ConfirmClusters:       getEnvListOrDefault("OCD_SCALING_CONFIRM_CLUSTERS", []string{"*prod*", "*prd*", "*shared*"}),
+			BusinessHours:         getEnvOrDefault("OCD_SCALING_BUSINESS_HOURS", ""),
+			BusinessHoursTimezone: getEnvOrDefault("OCD_SCALING_BUSINESS_HOURS_TZ", ""),
+		},
+		HF: HFConfig{
+			UpdateMode: strings.ToLower(getEnvOrDefault("OCD_HF_UPDATE_MODE", HFUpdateModePullRequest)),
+			Reviewers:  getEnvListOrDefault("OCD_HF_PR_REVIEWERS", nil),
+		},
+		Nexus: NexusConfig{
+			Username: getEnvOrDefault("OCD_NEXUS_USERNAME", ""),
+			Password: getEnvOrDefault("OCD_NEXUS_PASSWORD", ""),
+		},
+	}
+}
+
+// defaultOCDFile places a file OCD keeps across runs under the user config directory
+func defaultOCDFile(name string) string {
+	if configDir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(configDir, "ocd", name)
+	}
+	return filepath.Join(os.TempDir(), "ocd-"+name)
+}
+
+// defaultArtifactCacheDir places the artifact cache under the user cache directory
+func defaultArtifactCacheDir() string {
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cacheDir, "ocd", "artifacts")
+	}
+	return filepath.Join(os.TempDir(), "ocd-artifacts")
+}
+
+// loadJenkinsInstances builds the Jenkins instance registry. Each instance can be
+// overridden with OCD_JENKINS_<NAME>_{URL,CREDENTIALS,USERNAME,TOKEN,TLS_INSECURE_SKIP_VERIFY,TIMEOUT}.
+func loadJenkinsInstances(jenkins JenkinsConfig, tlsConfig TLSConfig, endpoints Endpoints) map[string]JenkinsInstanceConfig {
+	defaults := []JenkinsInstanceConfig{
+		{
+			Name:               JenkinsInstanceDelivery,
+			URL:                jenkins.URL,
+			CredentialsSource:  CredentialsFromEnv,
+			Username:           jenkins.Username,
+			Token:              jenkins.Token,
+			InsecureSkipVerify: tlsConfig.InsecureSkipVerify,
+		},
+		{
+			Name:               JenkinsInstanceStorage,
+			URL:                endpoints.StorageJenkinsBaseURL,
+			CredentialsSource:  CredentialsFromRequest,
+			InsecureSkipVerify: tlsConfig.InsecureSkipVerify,
+		},
+	}
+
+	instances := make(map[string]JenkinsInstanceConfig, len(defaults))
+	for _, instance := range defaults {
+		prefix := "OCD_JENKINS_" + strings.ToUpper(instance.Name) + "_"
+		instance.URL = getEnvOrDefault(prefix+"URL", instance.URL)
+		instance.CredentialsSource = strings.ToLower(getEnvOrDefault(prefix+"CREDENTIALS", instance.CredentialsSource))
+		instance.Username = getEnvOrDefault(prefix+"USERNAME", instance.Username)
+		instance.Token = getEnvOrDefault(prefix+"TOKEN", instance.Token)
+		instance.InsecureSkipVerify = getEnvBoolOrDefault(prefix+"TLS_INSECURE_SKIP_VERIFY", instance.InsecureSkipVerify)
+		instance.TimeoutSeconds = getEnvIntOrDefault(prefix+"TIMEOUT", instance.TimeoutSeconds)
+		if instance.CredentialsSource == CredentialsFromRequest {
+			instance.Username = ""
+			instance.Token = ""
+		}
+		instances[instance.Name] = instance
 	}
+	return instances
 }
 
 func DefaultEndpoints() Endpoints {
@@ -71,6 +265,7 @@ func defaultEndpoints() Endpoints {
 		StorageJenkinsBaseURL:       "http://ilososp030.corp.amdocs.com:7070",
 		StorageJenkinsJobPath:       "/job/ATT_Storage_Creation",
 		CustomizationJenkinsBaseURL: "https://jenkins-delivery.oss.corp.amdocs.aws",
+		CustomizationJenkinsJobPath: "/job/Delivery/job/ATT_OSO/job/customization",
 		ScalingJenkinsJobPath:       "/job/Utility/job/OpsUtil/job/scaleUpOrDown",
 		BitbucketBaseURL:            "https://ossbucket:7990",
 		BitbucketProjectKey:         "ATTSVO",
@@ -103,6 +298,20 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnvIntOrDefault(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -140,6 +349,17 @@ func (e Endpoints) ScalingJobRoot(baseURL string) string {
 	return trimmedBase + NormalizeJobPath(path)
 }
 
+// CustomizationBranchJobRoot returns the multibranch customization job URL for a branch.
+// Jenkins job names with slashes need %252F encoding.
+func (e Endpoints) CustomizationBranchJobRoot(branch string) string {
+	base := strings.TrimRight(e.CustomizationJenkinsBaseURL, "/")
+	path := e.CustomizationJenkinsJobPath
+	if strings.TrimSpace(path) == "" {
+		path = DefaultEndpoints().CustomizationJenkinsJobPath
+	}
+	return base + NormalizeJobPath(path) + "/job/" + strings.ReplaceAll(branch, "/", "%252F")
+}
+
 func (e Endpoints) StorageJobURL(parts ...string) string {
 	root := e.StorageJobRoot()
 	if len(parts) == 0 {
@@ -160,16 +380,13 @@ func (e Endpoints) StorageJobURL(parts ...string) string {
 	return builder
 }
 
-func (e Endpoints) BitbucketBranchesAPI() string {
-	base := strings.TrimRight(e.BitbucketBaseURL, "/")
-	return fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/branches?limit=100&details=true", base, e.BitbucketProjectKey, e.BitbucketCustomizationRepo)
-}
-
-func (e Endpoints) ReplaceWithInternalNexus(url string) string {
-	if e.NexusRepositoryBaseURL == "" || e.NexusInternalProxyBaseURL == "" {
-		return url
+// NexusBaseURL returns scheme://host of the Nexus server behind NexusSearchURL
+func (e Endpoints) NexusBaseURL() string {
+	parsed, err := url.Parse(e.NexusSearchURL)
+	if err != nil || parsed.Host == "" {
+		return strings.TrimRight(e.NexusSearchURL, "/")
 	}
-	return strings.Replace(url, e.NexusRepositoryBaseURL, e.NexusInternalProxyBaseURL, 1)
+	return parsed.Scheme + "://" + parsed.Host
 }
 
 func NormalizeJobPath(path string) string {
@@ -0,0 +1,211 @@
+// Package deploymap decides which microservice of a namespace a repository's
+// service is deployed to. Candidates are scored by name and by the image of
+// the init container a deploy patches; a single clear winner is used, while
+// several close candidates are reported as ambiguous so the user chooses one,
+// which is then remembered for the repository and namespace.
+package deploymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"app/internal/kube"
+)
+
+// CustomizationService is the service of a customization repository: every
+// customization service is deployed to the namespace's backend microservice
+const CustomizationService = "customization"
+
+// How a service's microservice was decided
+const (
+	SourceOverride  = "override"  // chosen for this deploy
+	SourceConfirmed = "confirmed" // chosen by the user before and remembered
+	SourceMatched   = "matched"   // the one clearly best candidate
+	SourceAmbiguous = "ambiguous" // several close candidates, the user must choose
+	SourceNone      = "none"      // no microservice could receive the service
+)
+
+const (
+	// confidentScore is the lowest score a candidate is used at without asking
+	confidentScore = 60
+	// clearMargin is how far the best candidate must lead the second
+	clearMargin = 20
+)
+
+// Candidate is a microservice a service could be deployed to
+type Candidate struct {
+	Microservice string   `json:"microservice"`
+	Score        int      `json:"score"`
+	Reasons      []string `json:"reasons"`
+	Container    string   `json:"container"` // init container the deploy patches
+	Image        string   `json:"image"`     // its current image
+}
+
+// Resolution is the microservice decided for one service
+type Resolution struct {
+	Service      string      `json:"service"`
+	Microservice string      `json:"microservice,omitempty"`
+	Source       string      `json:"source"`
+	Candidates   []Candidate `json:"candidates"`
+	Rejected     string      `json:"rejected,omitempty"` // an override naming a microservice that does not exist
+}
+
+// Resolve decides the microservice of every service. overrides are choices
+// for this deploy and confirmed the remembered ones; either wins over scoring
+// when the microservice still exists. An override naming a microservice that
+// does not exist resolves to SourceNone with Rejected set, rather than to a
+// guess the user did not ask for.
+func Resolve(services []string, microservices []kube.Microservice, confirmed, overrides map[string]string) []Resolution {
+	resolutions := make([]Resolution, 0, len(services))
+	for _, service := range services {
+		candidates := Score(service, microservices)
+		resolution := Resolution{Service: service, Candidates: candidates}
+		switch {
+		case overrides[service] != "" && exists(microservices, overrides[service]):
+			resolution.Microservice, resolution.Source = overrides[service], SourceOverride
+		case overrides[service] != "":
+			resolution.Rejected, resolution.Source = overrides[service], SourceNone
+		case confirmed[service] != "" && exists(microservices, confirmed[service]):
+			resolution.Microservice, resolution.Source = confirmed[service], SourceConfirmed
+		case len(candidates) == 0:
+			resolution.Source = SourceNone
+		case candidates[0].Score >= confidentScore && (len(candidates) == 1 || candidates[0].Score-candidates[1].Score >= clearMargin):
+			resolution.Microservice, resolution.Source = candidates[0].Microservice, SourceMatched
+		default:
+			resolution.Source = SourceAmbiguous
+		}
+		resolutions = append(resolutions, resolution)
+	}
+	return resolutions
+}
+
+// Score ranks the microservices that could receive a service, best first.
+// Only microservices with the init container the deploy patches qualify.
+func Score(service string, microservices []kube.Microservice) []Candidate {
+	pattern := kube.ApplicationContainerPattern
+	if service == CustomizationService {
+		pattern = kube.CustomizationContainerPattern
+	}
+	var candidates []Candidate
+	for i := range microservices {
+		microservice := &microservices[i]
+		index, _, err := microservice.FindInitContainer(pattern)
+		if err != nil || index < 0 {
+			continue
+		}
+		container := microservice.InitContainers[index]
+		candidate := Candidate{Microservice: microservice.Name, Container: container.Name, Image: container.Image}
+		if service == CustomizationService {
+			scoreBackend(&candidate)
+		} else {
+			scoreName(&candidate, service)
+		}
+		if candidate.Score > 0 {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Microservice < candidates[j].Microservice
+	})
+	return candidates
+}
+
+// scoreBackend scores a microservice with a customization init container; the
+// backend ones are preferred, and two backends are left to the user
+func scoreBackend(candidate *Candidate) {
+	candidate.Score = 50
+	candidate.Reasons = append(candidate.Reasons, "has a customization init container")
+	if strings.Contains(strings.ToLower(candidate.Microservice), "backend") {
+		candidate.Score += 20
+		candidate.Reasons = append(candidate.Reasons, "backend microservice")
+	}
+}
+
+// scoreName scores a microservice by how its name, and the image of its
+// application init container, compare with the service name. A longer name
+// that contains the service, such as dop-backend-oso for dop-backend, scores
+// lower the more parts it adds.
+func scoreName(candidate *Candidate, service string) {
+	serviceParts := nameParts(service)
+	microserviceParts := nameParts(candidate.Microservice)
+	switch {
+	case strings.EqualFold(candidate.Microservice, service):
+		candidate.Score = 100
+		candidate.Reasons = append(candidate.Reasons, "same name")
+	case containsParts(microserviceParts, serviceParts):
+		extra := len(microserviceParts) - len(serviceParts)
+		candidate.Score = 75 - 5*extra
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("name contains %s with %d more part(s)", service, extra))
+	default:
+		matched := 0
+		for _, part := range serviceParts {
+			if containsParts(microserviceParts, []string{part}) {
+				matched++
+			}
+		}
+		if matched > 0 {
+			candidate.Score = 40 * matched / len(serviceParts)
+			candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("%d/%d name parts match", matched, len(serviceParts)))
+		}
+	}
+
+	if repository := imageRepository(candidate.Image); repository != "" {
+		imageParts := nameParts(repository)
+		switch {
+		case strings.EqualFold(repository, service):
+			candidate.Score += 25
+			candidate.Reasons = append(candidate.Reasons, "image "+repository+" is named after the service")
+		case containsParts(imageParts, serviceParts):
+			candidate.Score += 10
+			candidate.Reasons = append(candidate.Reasons, "image "+repository+" contains the service name")
+		}
+	}
+}
+
+// imageRepository returns the last path element of an image without its tag
+// or digest, e.g. dop-backend for registry/att/dop-backend:1.2
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if slash := strings.LastIndex(image, "/"); slash >= 0 {
+		image = image[slash+1:]
+	}
+	image, _, _ = strings.Cut(image, ":")
+	return image
+}
+
+func nameParts(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+}
+
+// containsParts reports whether parts holds sequence as consecutive elements
+func containsParts(parts, sequence []string) bool {
+	if len(sequence) == 0 || len(sequence) > len(parts) {
+		return false
+	}
+	for start := 0; start+len(sequence) <= len(parts); start++ {
+		match := true
+		for i := range sequence {
+			if parts[start+i] != sequence[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func exists(microservices []kube.Microservice, name string) bool {
+	for _, microservice := range microservices {
+		if microservice.Name == name {
+			return true
+		}
+	}
+	return false
+}
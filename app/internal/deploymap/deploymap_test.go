@@ -0,0 +1,179 @@
+package deploymap
+
+import (
+	"testing"
+
+	"app/internal/kube"
+)
+
+// application is a microservice with an application init container running image
+func application(name, image string) kube.Microservice {
+	return kube.Microservice{
+		Name:           name,
+		Namespace:      "dop",
+		InitContainers: []kube.Container{{Name: "copy-application-files", Image: image}},
+	}
+}
+
+// customization is a microservice with a customization init container
+func customization(name string) kube.Microservice {
+	return kube.Microservice{
+		Name:           name,
+		Namespace:      "dop",
+		InitContainers: []kube.Container{{Name: "customization", Image: "registry/att/customization:1.0"}},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		service       string
+		microservices []kube.Microservice
+		confirmed     map[string]string
+		overrides     map[string]string
+
+		wantSource       string
+		wantMicroservice string
+		wantRejected     string
+		wantScores       []int // best first
+	}{
+		{
+			name:          "same name beats a longer name",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{application("dop-backend-oso", "registry/att/base:1.0"), application("dop-backend", "registry/att/base:1.0")},
+			wantSource:    SourceMatched, wantMicroservice: "dop-backend",
+			wantScores: []int{100, 70},
+		},
+		{
+			name:          "longer name containing the service",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{application("dop-backend-oso", "registry/att/base:1.0")},
+			wantSource:    SourceMatched, wantMicroservice: "dop-backend-oso",
+			wantScores: []int{70},
+		},
+		{
+			name:          "image named after the service breaks a tie",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{application("dop-backend-ui", "registry/att/base:1.0"), application("dop-backend-oso", "registry/att/dop-backend:2.3")},
+			wantSource:    SourceMatched, wantMicroservice: "dop-backend-oso",
+			wantScores: []int{95, 70},
+		},
+		{
+			name:          "close candidates are ambiguous",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{application("dop-backend-oso", "registry/att/base:1.0"), application("dop-backend-ui", "registry/att/dop-backend-ui:1.0")},
+			wantSource:    SourceAmbiguous,
+			wantScores:    []int{80, 70},
+		},
+		{
+			name:          "a weak single candidate is ambiguous",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{application("dop-frontend", "registry/att/base:1.0")},
+			wantSource:    SourceAmbiguous,
+			wantScores:    []int{20},
+		},
+		{
+			name:          "confirmed mapping wins over scoring",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{application("dop-backend", "registry/att/base:1.0"), application("dop-backend-oso", "registry/att/base:1.0")},
+			confirmed:     map[string]string{"dop-backend": "dop-backend-oso"},
+			wantSource:    SourceConfirmed, wantMicroservice: "dop-backend-oso",
+			wantScores: []int{100, 70},
+		},
+		{
+			name:          "confirmed mapping to a deleted microservice falls back to scoring",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{application("dop-backend-oso", "registry/att/base:1.0")},
+			confirmed:     map[string]string{"dop-backend": "dop-backend-old"},
+			wantSource:    SourceMatched, wantMicroservice: "dop-backend-oso",
+			wantScores: []int{70},
+		},
+		{
+			name:          "override wins over confirmed and scoring",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{application("dop-backend", "registry/att/base:1.0"), application("dop-backend-oso", "registry/att/base:1.0")},
+			confirmed:     map[string]string{"dop-backend": "dop-backend"},
+			overrides:     map[string]string{"dop-backend": "dop-backend-oso"},
+			wantSource:    SourceOverride, wantMicroservice: "dop-backend-oso",
+			wantScores: []int{100, 70},
+		},
+		{
+			name:          "override to a missing microservice is rejected",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{application("dop-backend", "registry/att/base:1.0")},
+			overrides:     map[string]string{"dop-backend": "dop-bakend"},
+			wantSource:    SourceNone, wantRejected: "dop-bakend",
+			wantScores: []int{100},
+		},
+		{
+			name:          "microservices without an application init container do not qualify",
+			service:       "dop-backend",
+			microservices: []kube.Microservice{{Name: "dop-backend", Namespace: "dop"}, customization("dop-backend-oso")},
+			wantSource:    SourceNone,
+		},
+		{
+			name:          "customization goes to the backend",
+			service:       CustomizationService,
+			microservices: []kube.Microservice{customization("dop-backend"), customization("dop-ui"), application("dop-api", "registry/att/base:1.0")},
+			wantSource:    SourceMatched, wantMicroservice: "dop-backend",
+			wantScores: []int{70, 50},
+		},
+		{
+			name:          "two backends leave customization ambiguous",
+			service:       CustomizationService,
+			microservices: []kube.Microservice{customization("dop-backend"), customization("dop-backend-oso")},
+			wantSource:    SourceAmbiguous,
+			wantScores:    []int{70, 70},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolutions := Resolve([]string{tt.service}, tt.microservices, tt.confirmed, tt.overrides)
+			if len(resolutions) != 1 {
+				t.Fatalf("got %d resolutions, want 1", len(resolutions))
+			}
+			got := resolutions[0]
+			if got.Service != tt.service {
+				t.Errorf("Service = %q, want %q", got.Service, tt.service)
+			}
+			if got.Source != tt.wantSource {
+				t.Errorf("Source = %q, want %q", got.Source, tt.wantSource)
+			}
+			if got.Microservice != tt.wantMicroservice {
+				t.Errorf("Microservice = %q, want %q", got.Microservice, tt.wantMicroservice)
+			}
+			if got.Rejected != tt.wantRejected {
+				t.Errorf("Rejected = %q, want %q", got.Rejected, tt.wantRejected)
+			}
+			if len(got.Candidates) != len(tt.wantScores) {
+				t.Fatalf("got %d candidates %+v, want scores %v", len(got.Candidates), got.Candidates, tt.wantScores)
+			}
+			for i, want := range tt.wantScores {
+				if got.Candidates[i].Score != want {
+					t.Errorf("candidate %d (%s) score = %d, want %d", i, got.Candidates[i].Microservice, got.Candidates[i].Score, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScoreReasons(t *testing.T) {
+	candidates := Score("dop-backend", []kube.Microservice{application("dop-backend-oso", "registry/att/dop-backend@sha256:abc")})
+	if len(candidates) != 1 {
+		t.Fatalf("got %d candidates, want 1", len(candidates))
+	}
+	candidate := candidates[0]
+	if candidate.Container != "copy-application-files" || candidate.Image != "registry/att/dop-backend@sha256:abc" {
+		t.Errorf("patch target = %s %s", candidate.Container, candidate.Image)
+	}
+	want := []string{"name contains dop-backend with 1 more part(s)", "image dop-backend is named after the service"}
+	if len(candidate.Reasons) != len(want) {
+		t.Fatalf("Reasons = %q, want %q", candidate.Reasons, want)
+	}
+	for i := range want {
+		if candidate.Reasons[i] != want[i] {
+			t.Errorf("Reasons[%d] = %q, want %q", i, candidate.Reasons[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package deploymap
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dockerSuffix is stripped from dockers/ directories to get the service name
+var dockerSuffix = regexp.MustCompile(`(-img|-img-job|-app)$`)
+
+// skippedDirectories are top-level directories that are not services
+var skippedDirectories = map[string]bool{
+	"dockers":                  true,
+	"helm":                     true,
+	"integration-ms":           true,
+	"jakarta-clientkits":       true,
+	"terminated-users-removal": true,
+}
+
+// IsCustomization reports whether a repository deploys customization
+// services, by the same folder name rule that picks the deploy script
+func IsCustomization(repoPath string) bool {
+	return strings.Contains(repoPath, "customization")
+}
+
+// DiscoverServices lists the services a deploy of the repository can detect
+// as changed, as the deploy script's discover_microservices does: <name>-ms
+// directories, dockers/<name>[-img|-img-job|-app] directories and top-level
+// Maven modules. A customization repository has the one customization service.
+func DiscoverServices(repoPath string) ([]string, error) {
+	if IsCustomization(repoPath) {
+		return []string{CustomizationService}, nil
+	}
+	entries, err := os.ReadDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if service, ok := strings.CutSuffix(name, "-ms"); ok {
+			found[service] = true
+			continue // the <name>-ms module is the service itself
+		}
+		if skippedDirectories[name] {
+			continue
+		}
+		if isModule(filepath.Join(repoPath, name)) {
+			found[name] = true
+		}
+	}
+
+	if dockers, err := os.ReadDir(filepath.Join(repoPath, "dockers")); err == nil {
+		for _, entry := range dockers {
+			if entry.IsDir() {
+				found[dockerSuffix.ReplaceAllString(entry.Name(), "")] = true
+			}
+		}
+	}
+
+	services := make([]string, 0, len(found))
+	for service := range found {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// isModule reports whether a directory is a buildable module
+func isModule(dir string) bool {
+	for _, name := range []string{"src", "pom.xml", "target"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
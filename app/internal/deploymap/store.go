@@ -0,0 +1,161 @@
+package deploymap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mapping is a microservice the user confirmed for a service of a repository
+// in a namespace
+type Mapping struct {
+	Repo         string    `json:"repo"`
+	Namespace    string    `json:"namespace"`
+	Service      string    `json:"service"`
+	Microservice string    `json:"microservice"`
+	ConfirmedAt  time.Time `json:"confirmed_at"`
+}
+
+// Store keeps confirmed mappings in a local JSON file
+type Store struct {
+	path string
+
+	mu       sync.Mutex
+	mappings map[string]Mapping // by repo/namespace/service
+}
+
+// NewStore loads the mappings stored at path. A missing file holds none.
+func NewStore(path string) (*Store, error) {
+	store := &Store{path: path, mappings: make(map[string]Mapping)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read microservice mappings %s: %w", path, err)
+	}
+	var stored []Mapping
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse microservice mappings %s: %w", path, err)
+	}
+	for _, mapping := range stored {
+		store.mappings[mappingKey(mapping.Repo, mapping.Namespace, mapping.Service)] = mapping
+	}
+	return store, nil
+}
+
+// RepoKey identifies a repository by its cleaned absolute path, compared
+// case-insensitively on Windows
+func RepoKey(repoPath string) string {
+	if absolute, err := filepath.Abs(repoPath); err == nil {
+		repoPath = absolute
+	}
+	repoPath = filepath.ToSlash(filepath.Clean(repoPath))
+	if runtime.GOOS == "windows" {
+		repoPath = strings.ToLower(repoPath)
+	}
+	return repoPath
+}
+
+func mappingKey(repo, namespace, service string) string {
+	return repo + "\x00" + namespace + "\x00" + service
+}
+
+// List returns the mappings of a repository in a namespace by service
+func (s *Store) List(repoPath, namespace string) []Mapping {
+	repo := RepoKey(repoPath)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var mappings []Mapping
+	for _, mapping := range s.mappings {
+		if mapping.Repo == repo && mapping.Namespace == namespace {
+			mappings = append(mappings, mapping)
+		}
+	}
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].Service < mappings[j].Service })
+	return mappings
+}
+
+// Microservices returns the mapped microservice of each service of a
+// repository in a namespace
+func (s *Store) Microservices(repoPath, namespace string) map[string]string {
+	microservices := make(map[string]string)
+	for _, mapping := range s.List(repoPath, namespace) {
+		microservices[mapping.Service] = mapping.Microservice
+	}
+	return microservices
+}
+
+// Confirm remembers the microservice of a service
+func (s *Store) Confirm(repoPath, namespace, service, microservice string) (Mapping, error) {
+	mapping := Mapping{
+		Repo:         RepoKey(repoPath),
+		Namespace:    namespace,
+		Service:      service,
+		Microservice: microservice,
+		ConfirmedAt:  time.Now().UTC(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := mappingKey(mapping.Repo, namespace, service)
+	previous, existed := s.mappings[key]
+	s.mappings[key] = mapping
+	if err := s.save(); err != nil {
+		if existed {
+			s.mappings[key] = previous
+		} else {
+			delete(s.mappings, key)
+		}
+		return Mapping{}, err
+	}
+	return mapping, nil
+}
+
+// Forget drops the mapping of a service; it reports whether there was one
+func (s *Store) Forget(repoPath, namespace, service string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := mappingKey(RepoKey(repoPath), namespace, service)
+	previous, existed := s.mappings[key]
+	if !existed {
+		return false, nil
+	}
+	delete(s.mappings, key)
+	if err := s.save(); err != nil {
+		s.mappings[key] = previous
+		return false, err
+	}
+	return true, nil
+}
+
+// save writes all mappings to disk; callers hold s.mu
+func (s *Store) save() error {
+	stored := make([]Mapping, 0, len(s.mappings))
+	for _, mapping := range s.mappings {
+		stored = append(stored, mapping)
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		return mappingKey(stored[i].Repo, stored[i].Namespace, stored[i].Service) < mappingKey(stored[j].Repo, stored[j].Namespace, stored[j].Service)
+	})
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create microservice mappings directory: %w", err)
+	}
+	if err := os.WriteFile(s.path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("failed to write microservice mappings: %w", err)
+	}
+	if err := os.Rename(s.path+".tmp", s.path); err != nil {
+		return fmt.Errorf("failed to write microservice mappings: %w", err)
+	}
+	return nil
+}
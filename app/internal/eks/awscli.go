@@ -0,0 +1,90 @@
+package eks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// SourceAWS is the name of the AWS CLI cluster source
+const SourceAWS = "aws"
+
+// ScriptRunner runs an embedded deploy script and returns its stdout
+type ScriptRunner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// awsCLISource lists clusters with list-eks-clusters.sh, which prints one
+// `aws eks describe-cluster` JSON document per cluster
+type awsCLISource struct {
+	run     ScriptRunner
+	regions []string
+}
+
+// NewAWSCLISource lists clusters in regions through the AWS CLI; no regions
+// uses the CLI's configured region
+func NewAWSCLISource(run ScriptRunner, regions []string) Source {
+	return &awsCLISource{run: run, regions: regions}
+}
+
+func (s *awsCLISource) Name() string { return SourceAWS }
+
+func (s *awsCLISource) List(ctx context.Context) ([]Cluster, error) {
+	var args []string
+	if len(s.regions) > 0 {
+		args = append(args, "--regions", strings.Join(s.regions, ","))
+	}
+	output, err := s.run(ctx, "list-eks-clusters.sh", args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseDescribeClusters(output), nil
+}
+
+// describeCluster is the part of `aws eks describe-cluster` output OCD uses;
+// Region is only set by the script's fallback when describe failed
+type describeCluster struct {
+	Cluster struct {
+		Name    string `json:"name"`
+		ARN     string `json:"arn"`
+		Status  string `json:"status"`
+		Version string `json:"version"`
+	} `json:"cluster"`
+	Region string `json:"region"`
+}
+
+// parseDescribeClusters decodes a stream of JSON documents, skipping anything
+// between them such as terminal warnings printed by a login shell
+func parseDescribeClusters(output []byte) []Cluster {
+	var clusters []Cluster
+	rest := output
+	for {
+		start := bytes.IndexByte(rest, '{')
+		if start < 0 {
+			return clusters
+		}
+		decoder := json.NewDecoder(bytes.NewReader(rest[start:]))
+		var described describeCluster
+		if err := decoder.Decode(&described); err != nil {
+			rest = rest[start+1:]
+			continue
+		}
+		rest = rest[start+int(decoder.InputOffset()):]
+
+		if described.Cluster.Name == "" {
+			continue
+		}
+		cluster := Cluster{
+			Name:    described.Cluster.Name,
+			ARN:     described.Cluster.ARN,
+			Status:  described.Cluster.Status,
+			Version: described.Cluster.Version,
+			Region:  described.Region,
+		}
+		// arn:aws:eks:<region>:<account>:cluster/<name>
+		if parts := strings.Split(cluster.ARN, ":"); len(parts) >= 6 {
+			cluster.Region = parts[3]
+			cluster.Account = parts[4]
+		}
+		clusters = append(clusters, cluster)
+	}
+}
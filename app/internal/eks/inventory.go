@@ -0,0 +1,221 @@
+// Package eks keeps the inventory of EKS clusters OCD can deploy to and
+// scale. Clusters are gathered from several sources (the AWS CLI, the Jenkins
+// scaling job) and merged by name, so a cluster known to any source is listed.
+package eks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInventoryTTL is how long a cluster listing is reused
+const DefaultInventoryTTL = 5 * time.Minute
+
+// Cluster is one EKS cluster
+type Cluster struct {
+	Name    string   `json:"name"`
+	Region  string   `json:"region,omitempty"`
+	Account string   `json:"account,omitempty"`
+	Status  string   `json:"status,omitempty"` // ACTIVE, CREATING, ... as reported by EKS
+	Version string   `json:"version,omitempty"`
+	ARN     string   `json:"arn,omitempty"`
+	Sources []string `json:"sources"` // which sources know the cluster
+}
+
+// Listing is the merged result of every source
+type Listing struct {
+	Clusters  []Cluster         `json:"clusters"`
+	FetchedAt time.Time         `json:"fetched_at"`
+	Errors    map[string]string `json:"errors,omitempty"` // source name -> failure, when some sources failed
+}
+
+// Names returns the cluster names of the listing
+func (l *Listing) Names() []string {
+	names := make([]string, 0, len(l.Clusters))
+	for _, cluster := range l.Clusters {
+		names = append(names, cluster.Name)
+	}
+	return names
+}
+
+// Source lists clusters from one place
+type Source interface {
+	Name() string
+	List(ctx context.Context) ([]Cluster, error)
+}
+
+type funcSource struct {
+	name string
+	list func(ctx context.Context) ([]Cluster, error)
+}
+
+func (s funcSource) Name() string                                { return s.name }
+func (s funcSource) List(ctx context.Context) ([]Cluster, error) { return s.list(ctx) }
+
+// NewSource adapts a listing function to a Source
+func NewSource(name string, list func(ctx context.Context) ([]Cluster, error)) Source {
+	return funcSource{name: name, list: list}
+}
+
+// Inventory merges and caches the clusters of its sources. It is safe for
+// concurrent use; concurrent refreshes share one fetch.
+type Inventory struct {
+	sources []Source
+	ttl     time.Duration
+
+	mu       sync.Mutex
+	listing  *Listing
+	inflight chan struct{}
+}
+
+// NewInventory creates an inventory over sources, in priority order: when two
+// sources describe the same cluster, the earlier one's fields win
+func NewInventory(ttl time.Duration, sources ...Source) *Inventory {
+	if ttl <= 0 {
+		ttl = DefaultInventoryTTL
+	}
+	return &Inventory{sources: sources, ttl: ttl}
+}
+
+// List returns the cached listing, fetching it again when it is older than the
+// TTL or refresh is set. It fails only when every source fails.
+func (inv *Inventory) List(ctx context.Context, refresh bool) (*Listing, error) {
+	for {
+		inv.mu.Lock()
+		if inv.listing != nil && !refresh && time.Since(inv.listing.FetchedAt) < inv.ttl {
+			listing := inv.listing
+			inv.mu.Unlock()
+			return listing, nil
+		}
+		if wait := inv.inflight; wait != nil {
+			inv.mu.Unlock()
+			select {
+			case <-wait:
+				refresh = false // use what the other caller fetched
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		done := make(chan struct{})
+		inv.inflight = done
+		inv.mu.Unlock()
+
+		listing, err := inv.fetch(ctx)
+
+		inv.mu.Lock()
+		if err == nil {
+			inv.listing = listing
+		}
+		inv.inflight = nil
+		close(done)
+		inv.mu.Unlock()
+
+		if err != nil {
+			// A stale listing beats none when every source is down
+			inv.mu.Lock()
+			stale := inv.listing
+			inv.mu.Unlock()
+			if stale != nil {
+				return stale, nil
+			}
+			return nil, err
+		}
+		return listing, nil
+	}
+}
+
+// fetch queries every source concurrently and merges the results
+func (inv *Inventory) fetch(ctx context.Context) (*Listing, error) {
+	type result struct {
+		clusters []Cluster
+		err      error
+	}
+	results := make([]result, len(inv.sources))
+	var wg sync.WaitGroup
+	for i, source := range inv.sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+			clusters, err := source.List(ctx)
+			results[i] = result{clusters: clusters, err: err}
+		}(i, source)
+	}
+	wg.Wait()
+
+	listing := &Listing{FetchedAt: time.Now()}
+	byName := make(map[string]*Cluster)
+	var order []string
+	failures := make(map[string]string)
+	for i, res := range results {
+		sourceName := inv.sources[i].Name()
+		if res.err != nil {
+			failures[sourceName] = res.err.Error()
+			continue
+		}
+		for _, cluster := range res.clusters {
+			name := strings.TrimSpace(cluster.Name)
+			if name == "" {
+				continue
+			}
+			existing, ok := byName[name]
+			if !ok {
+				merged := cluster
+				merged.Name = name
+				merged.Sources = []string{sourceName}
+				byName[name] = &merged
+				order = append(order, name)
+				continue
+			}
+			existing.Region = firstNonEmpty(existing.Region, cluster.Region)
+			existing.Account = firstNonEmpty(existing.Account, cluster.Account)
+			existing.Status = firstNonEmpty(existing.Status, cluster.Status)
+			existing.Version = firstNonEmpty(existing.Version, cluster.Version)
+			existing.ARN = firstNonEmpty(existing.ARN, cluster.ARN)
+			if !contains(existing.Sources, sourceName) {
+				existing.Sources = append(existing.Sources, sourceName)
+			}
+		}
+	}
+
+	if len(inv.sources) > 0 && len(failures) == len(inv.sources) {
+		messages := make([]string, 0, len(failures))
+		for name, message := range failures {
+			messages = append(messages, name+": "+message)
+		}
+		sort.Strings(messages)
+		return nil, fmt.Errorf("no cluster source is available: %s", strings.Join(messages, "; "))
+	}
+
+	sort.Strings(order)
+	listing.Clusters = make([]Cluster, 0, len(order))
+	for _, name := range order {
+		listing.Clusters = append(listing.Clusters, *byName[name])
+	}
+	if len(failures) > 0 {
+		listing.Errors = failures
+	}
+	return listing, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,308 @@
+package eks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultVerifyNamespace is where OCD deploys the application pods
+const DefaultVerifyNamespace = "dop"
+
+// NodeGroupStatus is the size of one managed node group
+type NodeGroupStatus struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // ACTIVE, UPDATING, ... as reported by EKS
+	Desired int    `json:"desired"`
+	Min     int    `json:"min"`
+	Max     int    `json:"max"`
+}
+
+// ClusterStatus is one check of a cluster's node groups, nodes and pods
+type ClusterStatus struct {
+	Cluster      string            `json:"cluster"`
+	Namespace    string            `json:"namespace"`
+	NodeGroups   []NodeGroupStatus `json:"node_groups"`
+	DesiredNodes int               `json:"desired_nodes"`
+	Nodes        int               `json:"nodes"`
+	ReadyNodes   int               `json:"ready_nodes"`
+	Pods         int               `json:"pods"` // pods expected to run; completed jobs are left out
+	ReadyPods    int               `json:"ready_pods"`
+	NotReadyPods []string          `json:"not_ready_pods,omitempty"` // the first few, for the timeline
+	Errors       map[string]string `json:"errors,omitempty"`         // section -> failure, e.g. an unreachable API
+	CheckedAt    time.Time         `json:"checked_at"`
+}
+
+// notReadyPodsShown caps how many pending pods a status names
+const notReadyPodsShown = 10
+
+// NodeGroupsSettled reports whether every node group finished updating
+func (s *ClusterStatus) NodeGroupsSettled() bool {
+	if len(s.NodeGroups) == 0 {
+		return false
+	}
+	for _, group := range s.NodeGroups {
+		if group.Status != "ACTIVE" {
+			return false
+		}
+	}
+	return true
+}
+
+// NodesAtDesired reports whether the ready nodes match the node groups' desired
+// size: at least that many after scaling up, at most after scaling down
+func (s *ClusterStatus) NodesAtDesired(scaleType string) bool {
+	if s.Errors["kubeconfig"] != "" || s.Errors["nodes"] != "" {
+		return false
+	}
+	if scaleType == "down" {
+		return s.Nodes <= s.DesiredNodes
+	}
+	return s.DesiredNodes > 0 && s.ReadyNodes >= s.DesiredNodes
+}
+
+// PodsReady reports whether every pod of the namespace is ready
+func (s *ClusterStatus) PodsReady() bool {
+	return s.Errors["pods"] == "" && s.Pods > 0 && s.ReadyPods == s.Pods
+}
+
+// Ready reports whether the cluster finished scaling: node groups settled at
+// their desired size and, after scaling up, the application pods ready
+func (s *ClusterStatus) Ready(scaleType string) bool {
+	// Scaling down may remove the node groups altogether
+	removed := scaleType == "down" && len(s.NodeGroups) == 0 && s.Errors["nodegroups"] == ""
+	if !(s.NodeGroupsSettled() || removed) || !s.NodesAtDesired(scaleType) {
+		return false
+	}
+	return scaleType == "down" || s.PodsReady()
+}
+
+// CheckClusterStatus reads the scaling state of a cluster with cluster-status.sh
+func CheckClusterStatus(ctx context.Context, run ScriptRunner, cluster, region, namespace string) (*ClusterStatus, error) {
+	if namespace == "" {
+		namespace = DefaultVerifyNamespace
+	}
+	args := []string{cluster, "--namespace", namespace}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+	output, err := run(ctx, "cluster-status.sh", args...)
+	if err != nil {
+		return nil, err
+	}
+	status := parseClusterStatus(output)
+	status.Cluster = cluster
+	status.Namespace = namespace
+	status.CheckedAt = time.Now()
+	return status, nil
+}
+
+// parseClusterStatus reads the sections printed by cluster-status.sh; output
+// before the first section, such as login shell noise, is ignored
+func parseClusterStatus(output []byte) *ClusterStatus {
+	status := &ClusterStatus{}
+	sections := make(map[string][]byte)
+	var current string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if header, ok := strings.CutPrefix(line, "### "); ok {
+			if rest, isError := strings.CutPrefix(header, "error "); isError {
+				section, message, _ := strings.Cut(rest, " ")
+				if status.Errors == nil {
+					status.Errors = make(map[string]string)
+				}
+				status.Errors[section] = strings.TrimSpace(message)
+				continue
+			}
+			current = strings.TrimSpace(header)
+			continue
+		}
+		if current != "" {
+			sections[current] = append(append(sections[current], line...), '\n')
+		}
+	}
+
+	status.NodeGroups = parseNodeGroups(sections["nodegroups"])
+	for _, group := range status.NodeGroups {
+		status.DesiredNodes += group.Desired
+	}
+	for _, fields := range tabFields(sections["nodes"]) {
+		status.Nodes++
+		if len(fields) > 1 && fields[1] == "True" {
+			status.ReadyNodes++
+		}
+	}
+	for _, fields := range tabFields(sections["pods"]) {
+		phase := ""
+		if len(fields) > 1 {
+			phase = fields[1]
+		}
+		if phase == "Succeeded" {
+			continue // finished job pods are never ready and do not need to be
+		}
+		status.Pods++
+		if len(fields) > 2 && fields[2] == "True" {
+			status.ReadyPods++
+		} else if len(status.NotReadyPods) < notReadyPodsShown {
+			status.NotReadyPods = append(status.NotReadyPods, fmt.Sprintf("%s (%s)", fields[0], firstNonEmpty(phase, "Unknown")))
+		}
+	}
+	return status
+}
+
+// parseNodeGroups decodes a stream of `aws eks describe-nodegroup` documents
+func parseNodeGroups(section []byte) []NodeGroupStatus {
+	var groups []NodeGroupStatus
+	decoder := json.NewDecoder(bytes.NewReader(section))
+	for {
+		var described struct {
+			Nodegroup struct {
+				Name          string `json:"nodegroupName"`
+				Status        string `json:"status"`
+				ScalingConfig struct {
+					MinSize     int `json:"minSize"`
+					MaxSize     int `json:"maxSize"`
+					DesiredSize int `json:"desiredSize"`
+				} `json:"scalingConfig"`
+			} `json:"nodegroup"`
+		}
+		if err := decoder.Decode(&described); err != nil {
+			return groups
+		}
+		if described.Nodegroup.Name == "" {
+			continue
+		}
+		groups = append(groups, NodeGroupStatus{
+			Name:    described.Nodegroup.Name,
+			Status:  described.Nodegroup.Status,
+			Desired: described.Nodegroup.ScalingConfig.DesiredSize,
+			Min:     described.Nodegroup.ScalingConfig.MinSize,
+			Max:     described.Nodegroup.ScalingConfig.MaxSize,
+		})
+	}
+}
+
+func tabFields(section []byte) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(string(section), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		rows = append(rows, strings.Split(strings.TrimSpace(line), "\t"))
+	}
+	return rows
+}
+
+// Kinds of verification events
+const (
+	VerifyCheck     = "check"     // a status check, sent every interval
+	VerifyMilestone = "milestone" // a readiness condition was reached
+	VerifyError     = "error"     // a check failed; verification goes on
+	VerifyReady     = "ready"     // the cluster is ready, verification ends
+	VerifyTimeout   = "timeout"   // gave up waiting, verification ends
+)
+
+// VerifyEvent is one entry of a verification timeline
+type VerifyEvent struct {
+	Kind    string         `json:"kind"`
+	Time    time.Time      `json:"time"`
+	Elapsed float64        `json:"elapsed_seconds"`
+	Message string         `json:"message"`
+	Status  *ClusterStatus `json:"status,omitempty"`
+}
+
+// VerifyOptions controls a verification
+type VerifyOptions struct {
+	ScaleType string // "up" or "down"
+	Region    string
+	Namespace string
+	Interval  time.Duration
+	Timeout   time.Duration
+}
+
+// Verify checks a cluster every interval until it is ready for its scale type
+// or the timeout passes, sending the timeline to emit. It returns nil once
+// the cluster is ready.
+func Verify(ctx context.Context, run ScriptRunner, cluster string, options VerifyOptions, emit func(VerifyEvent)) error {
+	if options.Interval <= 0 {
+		options.Interval = 20 * time.Second
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 30 * time.Minute
+	}
+	started := time.Now()
+	send := func(kind, message string, status *ClusterStatus) {
+		now := time.Now()
+		emit(VerifyEvent{Kind: kind, Time: now, Elapsed: now.Sub(started).Seconds(), Message: message, Status: status})
+	}
+
+	reached := make(map[string]bool)
+	milestone := func(key string, ok bool, message string) {
+		if ok && !reached[key] {
+			reached[key] = true
+			send(VerifyMilestone, message, nil)
+		}
+	}
+
+	deadline := time.NewTimer(options.Timeout)
+	defer deadline.Stop()
+	for {
+		status, err := CheckClusterStatus(ctx, run, cluster, options.Region, options.Namespace)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			send(VerifyError, fmt.Sprintf("Status check failed: %v", err), nil)
+		default:
+			send(VerifyCheck, describeStatus(status, options.ScaleType), status)
+			milestone("nodegroups", status.NodeGroupsSettled(), fmt.Sprintf("Node groups active, %d node(s) desired", status.DesiredNodes))
+			if options.ScaleType == "down" {
+				milestone("nodes", status.NodesAtDesired("down"), fmt.Sprintf("Nodes drained to %d", status.DesiredNodes))
+			} else {
+				milestone("nodes", status.NodesAtDesired("up"), fmt.Sprintf("%d/%d nodes ready", status.ReadyNodes, status.DesiredNodes))
+				milestone("pods", status.PodsReady(), fmt.Sprintf("All %d pods in %s ready", status.Pods, status.Namespace))
+			}
+			if status.Ready(options.ScaleType) {
+				message := fmt.Sprintf("Cluster ready after %s", time.Since(started).Round(time.Second))
+				if options.ScaleType == "down" {
+					message = fmt.Sprintf("Cluster scaled down after %s", time.Since(started).Round(time.Second))
+				}
+				send(VerifyReady, message, status)
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			send(VerifyTimeout, fmt.Sprintf("Cluster not ready after %s", options.Timeout), nil)
+			return fmt.Errorf("cluster %s not ready after %s", cluster, options.Timeout)
+		case <-time.After(options.Interval):
+		}
+	}
+}
+
+// describeStatus summarises a check for the timeline
+func describeStatus(status *ClusterStatus, scaleType string) string {
+	parts := []string{fmt.Sprintf("nodes %d/%d ready", status.ReadyNodes, status.DesiredNodes)}
+	if scaleType != "down" {
+		parts = append(parts, fmt.Sprintf("pods %d/%d ready in %s", status.ReadyPods, status.Pods, status.Namespace))
+	}
+	for _, group := range status.NodeGroups {
+		if group.Status != "ACTIVE" {
+			parts = append(parts, fmt.Sprintf("node group %s %s", group.Name, strings.ToLower(group.Status)))
+		}
+	}
+	if status.Errors["kubeconfig"] != "" {
+		parts = append(parts, "cluster API unreachable")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -8,20 +8,28 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"app/internal/config"
+	"app/internal/deploymap"
+	"app/internal/kube"
 	"app/internal/progress"
 	"app/internal/security"
 	ocdscripts "deploy-scripts"
 )
 
-type CommandExecutor struct{ config *config.Config }
+type CommandExecutor struct {
+	config      *config.Config
+	mappings    *deploymap.Store // microservices the user confirmed for a repository's services
+	kubeClients *kube.Provider   // the cluster the deploy scripts use: their shell's kubeconfig unless one is configured
+}
 
-func NewCommandExecutor(configuration *config.Config) *CommandExecutor {
-	return &CommandExecutor{config: configuration}
+func NewCommandExecutor(configuration *config.Config, mappings *deploymap.Store, kubeClients *kube.Provider) *CommandExecutor {
+	return &CommandExecutor{config: configuration, mappings: mappings, kubeClients: kubeClients}
 }
 
 func (ce *CommandExecutor) Execute(folderPath string) progress.Response {
@@ -30,7 +38,7 @@ func (ce *CommandExecutor) Execute(folderPath string) progress.Response {
 	}
 	safeFolderPath := security.SanitizePath(folderPath)
 
-	cmd, err := ce.buildCommand(safeFolderPath)
+	cmd, err := ce.buildCommand(safeFolderPath, deployTarget{})
 	if err != nil {
 		return progress.Response{Message: err.Error(), Success: false}
 	}
@@ -42,15 +50,29 @@ func (ce *CommandExecutor) Execute(folderPath string) progress.Response {
 	return progress.Response{Message: fmt.Sprintf("OCD deployment completed!\n%s", string(output)), Success: true}
 }
 
-// ExecuteWithSSE runs the OCD script and streams output via SSE channel
-func (ce *CommandExecutor) ExecuteWithSSE(ctx context.Context, folderPath string, writer chan []byte) {
-	if err := security.ValidateFolderPath(folderPath); err != nil {
+// ExecuteWithSSE runs the OCD script and streams output via SSE channel. The
+// microservice of each service is resolved first and passed to the script,
+// which stops before building when a changed service has no clear one; the
+// user is then asked to choose among its candidates.
+func (ce *CommandExecutor) ExecuteWithSSE(ctx context.Context, request progress.DeployRequest, writer chan []byte) {
+	if err := security.ValidateFolderPath(request.FolderPath); err != nil {
 		sendSSEMessage(writer, progress.OutputMessage{Type: "complete", Content: fmt.Sprintf("Invalid folder path: %s", err.Error()), Success: false})
 		return
 	}
-	safeFolderPath := security.SanitizePath(folderPath)
+	if err := security.ValidateDeployTarget(request.Namespace, request.Microservices); err != nil {
+		sendSSEMessage(writer, progress.OutputMessage{Type: "complete", Content: fmt.Sprintf("Invalid deploy target: %s", err.Error()), Success: false})
+		return
+	}
+	safeFolderPath := security.SanitizePath(request.FolderPath)
 
-	cmd, err := ce.buildCommand(safeFolderPath)
+	target, resolutions := ce.resolveMicroservices(ctx, request, safeFolderPath, writer)
+	if replyDir, err := ce.patchReplyDir(); err == nil {
+		defer os.RemoveAll(replyDir)
+		target.PatchReplyDir = replyDir
+	} else {
+		sendSSEMessage(writer, progress.OutputMessage{Type: "output", Content: fmt.Sprintf("[patch] Patching with kubectl: %v", err)})
+	}
+	cmd, err := ce.buildCommand(safeFolderPath, target)
 	if err != nil {
 		sendSSEMessage(writer, progress.OutputMessage{Type: "complete", Content: err.Error(), Success: false})
 		return
@@ -76,8 +98,17 @@ func (ce *CommandExecutor) ExecuteWithSSE(ctx context.Context, folderPath string
 	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, time.Duration(ce.config.CommandTimeout)*time.Second)
 	defer timeoutCancel()
 
-	// Stream stdout
+	// Stream stdout, answering patch requests and collecting the microservices
+	// the script patched and the services it stopped on
+	var (
+		readers   sync.WaitGroup
+		targetsMu sync.Mutex
+		targets   []kube.RolloutTarget
+		ambiguous []string
+	)
+	readers.Add(2)
 	go func() {
+		defer readers.Done()
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
 			select {
@@ -89,6 +120,22 @@ func (ce *CommandExecutor) ExecuteWithSSE(ctx context.Context, folderPath string
 			if strings.Contains(line, "screen size is bogus") {
 				continue
 			}
+			if patch, ok := parsePatchLine(line); ok && target.PatchReplyDir != "" {
+				ce.answerPatch(timeoutCtx, target.PatchReplyDir, patch, writer)
+				continue
+			}
+			if target, ok := parsePatchedLine(line); ok {
+				targetsMu.Lock()
+				targets = append(targets, target)
+				targetsMu.Unlock()
+				continue
+			}
+			if service, ok := parseAmbiguousLine(line); ok {
+				targetsMu.Lock()
+				ambiguous = append(ambiguous, service)
+				targetsMu.Unlock()
+				continue
+			}
 			sendSSEMessage(writer, progress.OutputMessage{Type: "output", Content: line})
 			if pu := progress.ParseProgressFromOutput(line); pu != nil {
 				sendSSEMessage(writer, pu)
@@ -98,6 +145,7 @@ func (ce *CommandExecutor) ExecuteWithSSE(ctx context.Context, folderPath string
 
 	// Stream stderr
 	go func() {
+		defer readers.Done()
 		scanner := bufio.NewScanner(stderr)
 		for scanner.Scan() {
 			select {
@@ -113,9 +161,12 @@ func (ce *CommandExecutor) ExecuteWithSSE(ctx context.Context, folderPath string
 		}
 	}()
 
-	// Wait for completion
+	// Wait for completion; the pipes must be read to the end before Wait closes them
 	done := make(chan error, 1)
-	go func() { done <- cmd.Wait() }()
+	go func() {
+		readers.Wait()
+		done <- cmd.Wait()
+	}()
 
 	select {
 	case <-timeoutCtx.Done():
@@ -128,6 +179,24 @@ func (ce *CommandExecutor) ExecuteWithSSE(ctx context.Context, folderPath string
 	case err := <-done:
 		success := err == nil
 		msg := "Check logs for more details"
+		if len(ambiguous) > 0 {
+			// The script stopped before building; ask which microservice to patch
+			for _, service := range ambiguous {
+				sendSSEMessage(writer, mappingMessage{Type: "mapping", Namespace: target.Namespace, Service: service, Candidates: resolutions[service].Candidates})
+			}
+			success = false
+			msg = fmt.Sprintf("Choose the microservice for %s and deploy again", strings.Join(ambiguous, ", "))
+		}
+		if success && len(targets) > 0 && ce.config.Kube.VerifyRollout {
+			// A patch that went through can still leave the new pods crash-looping
+			if verifyErr := ce.verifyRollouts(ctx, targets, writer); verifyErr != nil {
+				success = false
+				msg = verifyErr.Error()
+				if ctx.Err() != nil {
+					msg = "Deployment cancelled"
+				}
+			}
+		}
 		if success {
 			msg = "Deployment completed successfully"
 		}
@@ -135,7 +204,7 @@ func (ce *CommandExecutor) ExecuteWithSSE(ctx context.Context, folderPath string
 	}
 }
 
-func (ce *CommandExecutor) buildCommand(safeFolderPath string) (*exec.Cmd, error) {
+func (ce *CommandExecutor) buildCommand(safeFolderPath string, target deployTarget) (*exec.Cmd, error) {
 	// Detect project type and determine correct script to use
 	var scriptName string
 	if strings.Contains(safeFolderPath, "customization") {
@@ -206,12 +275,12 @@ func (ce *CommandExecutor) buildCommand(safeFolderPath string) (*exec.Cmd, error
 			ocdScriptWSLPath := convertToWSLPath(tempScriptFile.Name())
 			sharedDirWSLPath := convertToWSLPath(tempSharedDir)
 			cmd = exec.Command("wsl", "--user", ce.config.WSLUser, "bash", "-l", "-c",
-				buildWSLDirectCommand(ocdScriptWSLPath, sharedDirWSLPath, wslPath))
+				buildWSLDirectCommand(ocdScriptWSLPath, sharedDirWSLPath, wslPath, target))
 		} else {
 			return nil, fmt.Errorf("WSL not available on Windows. Please install WSL to use OCD")
 		}
 	case "linux", "darwin":
-		cmd = exec.Command("bash", "-l", "-c", buildDirectCommand(tempScriptFile.Name(), tempSharedDir, safeFolderPath))
+		cmd = exec.Command("bash", "-l", "-c", buildDirectCommand(tempScriptFile.Name(), tempSharedDir, safeFolderPath, target))
 	default:
 		return nil, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
@@ -220,12 +289,45 @@ func (ce *CommandExecutor) buildCommand(safeFolderPath string) (*exec.Cmd, error
 	return cmd, nil
 }
 
-func buildWSLDirectCommand(scriptPath, sharedDirPath, folderPath string) string {
-	return fmt.Sprintf(`export MAVEN_OPTS="-Dorg.slf4j.simpleLogger.showDateTime=true -Dorg.slf4j.simpleLogger.dateTimeFormat=HH:mm:ss" && export OCD_VERBOSE=true && proxy on 2>/dev/null || true && cd %s && bash %s`, shellEscape(folderPath), shellEscape(scriptPath))
+func buildWSLDirectCommand(scriptPath, sharedDirPath, folderPath string, target deployTarget) string {
+	exports, args := deployTargetOptions(target)
+	return fmt.Sprintf(`export MAVEN_OPTS="-Dorg.slf4j.simpleLogger.showDateTime=true -Dorg.slf4j.simpleLogger.dateTimeFormat=HH:mm:ss" && export OCD_VERBOSE=true%s && proxy on 2>/dev/null || true && cd %s && bash %s%s`, exports, shellEscape(folderPath), shellEscape(scriptPath), args)
+}
+
+func buildDirectCommand(scriptPath, sharedDirPath, folderPath string, target deployTarget) string {
+	exports, args := deployTargetOptions(target)
+	return fmt.Sprintf(`export MAVEN_OPTS="-Dorg.slf4j.simpleLogger.showDateTime=true -Dorg.slf4j.simpleLogger.dateTimeFormat=HH:mm:ss" && export OCD_VERBOSE=true%s && proxy on 2>/dev/null || true && cd %s && bash %s%s`, exports, shellEscape(folderPath), shellEscape(scriptPath), args)
 }
 
-func buildDirectCommand(scriptPath, sharedDirPath, folderPath string) string {
-	return fmt.Sprintf(`export MAVEN_OPTS="-Dorg.slf4j.simpleLogger.showDateTime=true -Dorg.slf4j.simpleLogger.dateTimeFormat=HH:mm:ss" && export OCD_VERBOSE=true && proxy on 2>/dev/null || true && cd %s && bash %s`, shellEscape(folderPath), shellEscape(scriptPath))
+// deployTargetOptions renders a deploy's resolved microservices as an export
+// of OCD_MICROSERVICE_MAP ("service=microservice,..."), its unresolved
+// services as OCD_AMBIGUOUS_SERVICES, where OCD answers patch requests as
+// OCD_PATCH_REPLY_DIR and its namespace as the script's --namespace argument;
+// each is empty when not set
+func deployTargetOptions(target deployTarget) (string, string) {
+	var exports, args string
+	if len(target.Microservices) > 0 {
+		services := make([]string, 0, len(target.Microservices))
+		for service := range target.Microservices {
+			services = append(services, service)
+		}
+		sort.Strings(services)
+		entries := make([]string, 0, len(services))
+		for _, service := range services {
+			entries = append(entries, service+"="+target.Microservices[service])
+		}
+		exports = " && export OCD_MICROSERVICE_MAP=" + shellEscape(strings.Join(entries, ","))
+	}
+	if len(target.Ambiguous) > 0 {
+		exports += " && export OCD_AMBIGUOUS_SERVICES=" + shellEscape(strings.Join(target.Ambiguous, ","))
+	}
+	if target.PatchReplyDir != "" {
+		exports += " && export OCD_PATCH_REPLY_DIR=" + shellEscape(convertToWSLPath(target.PatchReplyDir))
+	}
+	if target.Namespace != "" {
+		args = " --namespace " + shellEscape(target.Namespace)
+	}
+	return exports, args
 }
 
 // shellEscape safely escapes a string for use in shell commands
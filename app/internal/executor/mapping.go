@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"app/internal/deploymap"
+	"app/internal/progress"
+)
+
+// ambiguousMarker starts the line a deploy script prints before stopping on a
+// changed service OCD could not map: OCD_AMBIGUOUS service=<name> namespace=<ns>
+const ambiguousMarker = "OCD_AMBIGUOUS "
+
+// deployTarget is what the deploy script is told about where to patch
+type deployTarget struct {
+	Namespace     string
+	Microservices map[string]string // service -> microservice, passed as OCD_MICROSERVICE_MAP
+	Ambiguous     []string          // services the script must not deploy without a choice
+	PatchReplyDir string            // where OCD answers the script's patch requests, passed as OCD_PATCH_REPLY_DIR
+}
+
+// mappingMessage asks the user which microservice a service is deployed to
+type mappingMessage struct {
+	Type       string                `json:"type"` // "mapping"
+	Namespace  string                `json:"namespace"`
+	Service    string                `json:"service"`
+	Candidates []deploymap.Candidate `json:"candidates"`
+}
+
+// parseAmbiguousLine reads the service of an ambiguous marker line
+func parseAmbiguousLine(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), ambiguousMarker)
+	if !ok {
+		return "", false
+	}
+	for _, field := range strings.Fields(rest) {
+		if service, ok := strings.CutPrefix(field, "service="); ok && service != "" {
+			return service, true
+		}
+	}
+	return "", false
+}
+
+// resolveMicroservices maps every service of the repository to the
+// microservice it is deployed to: this deploy's choices, then the ones the
+// user confirmed before, then a clearly best match among the namespace's
+// microservices. Services with several close candidates are left ambiguous.
+// Without cluster access only the chosen and confirmed mappings are passed
+// and the script falls back to exact name matching.
+func (ce *CommandExecutor) resolveMicroservices(ctx context.Context, request progress.DeployRequest, folderPath string, writer chan []byte) (deployTarget, map[string]deploymap.Resolution) {
+	target := deployTarget{Namespace: request.Namespace, Microservices: make(map[string]string)}
+	if target.Namespace == "" {
+		target.Namespace = ce.config.Kube.DefaultNamespace
+	}
+	confirmed := make(map[string]string)
+	if ce.mappings != nil {
+		confirmed = ce.mappings.Microservices(folderPath, target.Namespace)
+	}
+	fallback := func(reason string) (deployTarget, map[string]deploymap.Resolution) {
+		sendSSEMessage(writer, progress.OutputMessage{Type: "output", Content: fmt.Sprintf("[mapping] Microservices not checked: %s", reason)})
+		for service, microservice := range confirmed {
+			target.Microservices[service] = microservice
+		}
+		for service, microservice := range request.Microservices {
+			target.Microservices[service] = microservice
+		}
+		return target, nil
+	}
+
+	services, err := deploymap.DiscoverServices(folderPath)
+	if err != nil {
+		return fallback(err.Error())
+	}
+	client, err := ce.kubeClients.Client()
+	if err != nil {
+		return fallback(err.Error())
+	}
+	microservices, err := client.ListMicroservices(ctx, target.Namespace)
+	if err != nil {
+		return fallback(err.Error())
+	}
+
+	resolutions := make(map[string]deploymap.Resolution)
+	for _, resolution := range deploymap.Resolve(services, microservices, confirmed, request.Microservices) {
+		resolutions[resolution.Service] = resolution
+		switch resolution.Source {
+		case deploymap.SourceOverride, deploymap.SourceConfirmed, deploymap.SourceMatched:
+			target.Microservices[resolution.Service] = resolution.Microservice
+		case deploymap.SourceAmbiguous:
+			target.Ambiguous = append(target.Ambiguous, resolution.Service)
+		case deploymap.SourceNone:
+			// A mistyped choice must be made again, not replaced by name matching
+			if resolution.Rejected != "" {
+				target.Ambiguous = append(target.Ambiguous, resolution.Service)
+			}
+		}
+		if message := describeResolution(resolution); message != "" {
+			sendSSEMessage(writer, progress.OutputMessage{Type: "output", Content: "[mapping] " + message})
+		}
+	}
+	sort.Strings(target.Ambiguous)
+	return target, resolutions
+}
+
+// describeResolution explains a mapping for the output; services without a
+// candidate are not worth a line
+func describeResolution(resolution deploymap.Resolution) string {
+	switch resolution.Source {
+	case deploymap.SourceOverride:
+		return fmt.Sprintf("%s -> %s (chosen for this deploy)", resolution.Service, resolution.Microservice)
+	case deploymap.SourceConfirmed:
+		return fmt.Sprintf("%s -> %s (confirmed before)", resolution.Service, resolution.Microservice)
+	case deploymap.SourceMatched:
+		best := resolution.Candidates[0]
+		return fmt.Sprintf("%s -> %s (score %d: %s)", resolution.Service, resolution.Microservice, best.Score, strings.Join(best.Reasons, ", "))
+	case deploymap.SourceAmbiguous:
+		names := make([]string, 0, len(resolution.Candidates))
+		for _, candidate := range resolution.Candidates {
+			names = append(names, fmt.Sprintf("%s (%d)", candidate.Microservice, candidate.Score))
+		}
+		return fmt.Sprintf("%s could go to %s; it is not deployed until one is chosen", resolution.Service, strings.Join(names, ", "))
+	case deploymap.SourceNone:
+		if resolution.Rejected != "" {
+			return fmt.Sprintf("%s was chosen to go to %s, which does not exist in the namespace; it is not deployed until another is chosen", resolution.Service, resolution.Rejected)
+		}
+	}
+	return ""
+}
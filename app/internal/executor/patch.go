@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"app/internal/kube"
+	"app/internal/progress"
+	"app/internal/security"
+)
+
+// patchMarker starts the line a deploy script prints to have OCD patch a
+// microservice: OCD_PATCH id=<n> namespace=<ns> microservice=<name>
+// pattern=<init container pattern> image=<image> server=<API server of the
+// script's kubectl context>. The script then waits for the answer in file <id>
+// of OCD_PATCH_REPLY_DIR: "ok container=<name> previous=<image>" or
+// "error <message>".
+const patchMarker = "OCD_PATCH "
+
+// patchTimeout bounds reading and patching one microservice
+const patchTimeout = 2 * time.Minute
+
+// patchRequest is an init container image the deploy script asks OCD to set
+type patchRequest struct {
+	ID           string
+	Namespace    string
+	Microservice string
+	Pattern      string
+	Image        string
+	Server       string // the cluster the script checked the microservice on
+}
+
+// parsePatchLine reads the request of a patch marker line
+func parsePatchLine(line string) (patchRequest, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), patchMarker)
+	if !ok {
+		return patchRequest{}, false
+	}
+	var request patchRequest
+	for _, field := range strings.Fields(rest) {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "id":
+			request.ID = value
+		case "namespace":
+			request.Namespace = value
+		case "microservice":
+			request.Microservice = value
+		case "pattern":
+			request.Pattern = value
+		case "image":
+			request.Image = value
+		case "server":
+			request.Server = value
+		}
+	}
+	return request, isPatchID(request.ID) && request.Namespace != "" && request.Microservice != "" && request.Pattern != "" && request.Image != ""
+}
+
+// patchReplyDir checks that a Kubernetes client can be built and creates the
+// directory patch answers are written to
+func (ce *CommandExecutor) patchReplyDir() (string, error) {
+	if _, err := ce.kubeClients.Client(); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp("", "OCD_patch_*")
+}
+
+// answerPatch sets the image of a microservice's init container through the
+// Kubernetes API and writes the answer the deploy script waits for. The patch
+// tests the container's name and current image, so a microservice changed
+// since it was read is reported rather than overwritten.
+func (ce *CommandExecutor) answerPatch(ctx context.Context, replyDir string, request patchRequest, writer chan []byte) {
+	answer := ""
+	update, err := ce.applyPatch(ctx, request)
+	if err != nil {
+		sendSSEMessage(writer, progress.OutputMessage{Type: "output", Content: fmt.Sprintf("[patch] %s: %v", request.Microservice, err)})
+		answer = "error " + strings.Join(strings.Fields(err.Error()), " ")
+	} else {
+		sendSSEMessage(writer, progress.OutputMessage{Type: "output", Content: fmt.Sprintf("[patch] %s/%s: init container %s (found by %s) %s -> %s",
+			update.Namespace, update.Microservice, update.Container, update.DetectedBy, update.PreviousImage, update.Image)})
+		answer = fmt.Sprintf("ok container=%s previous=%s", update.Container, update.PreviousImage)
+	}
+
+	// Renamed into place so the script never reads a partial answer
+	partial := filepath.Join(replyDir, request.ID+".tmp")
+	if err := os.WriteFile(partial, []byte(answer+"\n"), 0644); err == nil {
+		err = os.Rename(partial, filepath.Join(replyDir, request.ID))
+	}
+	if err != nil {
+		sendSSEMessage(writer, progress.OutputMessage{Type: "output", Content: fmt.Sprintf("[patch] Failed to answer the deploy script: %v", err)})
+	}
+}
+
+func (ce *CommandExecutor) applyPatch(ctx context.Context, request patchRequest) (*kube.ImageUpdate, error) {
+	for _, name := range []string{request.Namespace, request.Microservice} {
+		if err := security.ValidateKubernetesName(name); err != nil {
+			return nil, err
+		}
+	}
+	if request.Pattern != kube.ApplicationContainerPattern && request.Pattern != kube.CustomizationContainerPattern {
+		return nil, fmt.Errorf("unknown init container pattern %q", request.Pattern)
+	}
+	if strings.ContainsAny(request.Image, " \t\"'") {
+		return nil, fmt.Errorf("invalid image %q", request.Image)
+	}
+	client, err := ce.kubeClients.Client()
+	if err != nil {
+		return nil, err
+	}
+	// Patching another cluster than the one the script looked at would deploy
+	// to the wrong environment
+	if server := strings.TrimRight(request.Server, "/"); server != client.Server() {
+		return nil, fmt.Errorf("the deploy script's kubectl uses API server %q but OCD's Kubernetes client uses %q; point OCD_KUBECONFIG and OCD_KUBE_CONTEXT at the script's cluster", server, client.Server())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, patchTimeout)
+	defer cancel()
+	update, err := client.UpdateInitContainerImage(ctx, request.Namespace, request.Microservice, request.Pattern, request.Image)
+	if kube.IsConflict(err) {
+		return nil, fmt.Errorf("microservice %s changed while it was being patched; deploy again: %w", request.Microservice, err)
+	}
+	return update, err
+}
+
+// isPatchID reports whether id is safe to use as a reply file name
+func isPatchID(id string) bool {
+	if id == "" || len(id) > 20 {
+		return false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
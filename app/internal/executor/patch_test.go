@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"app/internal/kube"
+	"app/internal/kube/kubetest"
+)
+
+func TestParsePatchLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want patchRequest
+		ok   bool
+	}{
+		{
+			line: "OCD_PATCH id=3 namespace=dop microservice=dop-backend pattern=(copy-application-files|source-code) image=registry/att/dop-backend:1.1 server=https://eks.example.com",
+			want: patchRequest{ID: "3", Namespace: "dop", Microservice: "dop-backend", Pattern: "(copy-application-files|source-code)", Image: "registry/att/dop-backend:1.1", Server: "https://eks.example.com"},
+			ok:   true,
+		},
+		{line: "OCD_PATCHED namespace=dop microservice=dop-backend container=c image=i"},
+		{line: "OCD_PATCH id=../x namespace=dop microservice=dop-backend pattern=customization image=i server=s"},
+		{line: "OCD_PATCH id=1 namespace=dop pattern=customization image=i server=s"},
+	}
+
+	for _, tt := range tests {
+		got, ok := parsePatchLine(tt.line)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parsePatchLine(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	fake := kubetest.NewFakeServer()
+	t.Cleanup(fake.Close)
+	fake.AddMicroservice("dop", "dop-backend", kube.Container{Name: "copy-application-files", Image: "registry/att/dop-backend:1.0"})
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	content := fmt.Sprintf(`{"current-context": "c", "contexts": [{"name": "c", "context": {"cluster": "k", "user": "u"}}], "clusters": [{"name": "k", "cluster": {"server": %q}}], "users": [{"name": "u", "user": {"token": %q}}]}`, fake.URL, kubetest.Token)
+	if err := os.WriteFile(kubeconfig, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ce := &CommandExecutor{kubeClients: kube.NewProvider(kubeconfig, "", kubetest.MicroserviceAPI, nil)}
+
+	request := patchRequest{
+		ID:           "1",
+		Namespace:    "dop",
+		Microservice: "dop-backend",
+		Pattern:      kube.ApplicationContainerPattern,
+		Image:        "registry/att/dop-backend:1.1",
+		Server:       "https://other-cluster.example.com",
+	}
+	if _, err := ce.applyPatch(context.Background(), request); err == nil || !strings.Contains(err.Error(), "API server") {
+		t.Fatalf("patch for another cluster error = %v, want a server mismatch", err)
+	}
+	if fake.Patches() != 0 {
+		t.Fatalf("a microservice was patched for another cluster")
+	}
+
+	request.Pattern = "(.*)"
+	request.Server = fake.URL + "/"
+	if _, err := ce.applyPatch(context.Background(), request); err == nil {
+		t.Error("an arbitrary init container pattern was accepted")
+	}
+
+	request.Pattern = kube.ApplicationContainerPattern
+	update, err := ce.applyPatch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("applyPatch: %v", err)
+	}
+	if update.Container != "copy-application-files" || update.PreviousImage != "registry/att/dop-backend:1.0" {
+		t.Errorf("update = %+v", update)
+	}
+	if image := fake.Microservice("dop", "dop-backend").InitContainers[0].Image; image != request.Image {
+		t.Errorf("image after the patch = %q, want %q", image, request.Image)
+	}
+}
@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"app/internal/kube"
+	"app/internal/progress"
+)
+
+// patchedMarker starts the line a deploy script prints after patching a
+// microservice: OCD_PATCHED namespace=<ns> microservice=<name> container=<name> image=<image>
+const patchedMarker = "OCD_PATCHED "
+
+// parsePatchedLine reads the rollout target of a patched marker line
+func parsePatchedLine(line string) (kube.RolloutTarget, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), patchedMarker)
+	if !ok {
+		return kube.RolloutTarget{}, false
+	}
+	var target kube.RolloutTarget
+	for _, field := range strings.Fields(rest) {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "namespace":
+			target.Namespace = value
+		case "microservice":
+			target.Microservice = value
+		case "container":
+			target.Container = value
+		case "image":
+			target.Image = value
+		}
+	}
+	return target, target.Namespace != "" && target.Microservice != "" && target.Image != ""
+}
+
+// verifyRollouts watches the pods of every patched microservice until they
+// run the new image and are ready, reported as the "verify" stage with the
+// pod events and logs of a failure in the output. It returns an error when a
+// rollout failed; a cluster it cannot reach only leaves the stage unverified.
+func (ce *CommandExecutor) verifyRollouts(ctx context.Context, targets []kube.RolloutTarget, writer chan []byte) error {
+	client, err := ce.kubeClients.Client()
+	if err != nil {
+		sendSSEMessage(writer, progress.OutputMessage{Type: "output", Content: fmt.Sprintf("Rollout verification skipped: %v", err)})
+		for _, target := range targets {
+			sendSSEMessage(writer, progress.ProgressUpdate{Type: "progress", Stage: "verify", Service: target.Microservice, Status: "warning", Message: "Rollout not verified", Details: err.Error()})
+		}
+		return nil
+	}
+
+	options := kube.RolloutOptions{Timeout: time.Duration(ce.config.Kube.VerifyTimeoutSeconds) * time.Second}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []string
+	)
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target kube.RolloutTarget) {
+			defer wg.Done()
+			sendSSEMessage(writer, progress.ProgressUpdate{Type: "progress", Stage: "verify", Service: target.Microservice, Status: "running", Message: fmt.Sprintf("Verifying %s rollout", target.Microservice), Details: target.Image})
+
+			err := client.WatchRollout(ctx, target, options, func(update kube.RolloutUpdate) {
+				prefix := "[verify " + target.Microservice + "]"
+				if update.Pod != "" && (update.Kind == kube.RolloutEvent || update.Kind == kube.RolloutLog) {
+					prefix = "[verify " + update.Pod + "]"
+				}
+				sendSSEMessage(writer, progress.OutputMessage{Type: "output", Content: prefix + " " + update.Message})
+				if update.Kind == kube.RolloutCheck {
+					sendSSEMessage(writer, progress.ProgressUpdate{Type: "progress", Stage: "verify", Service: target.Microservice, Status: "running", Message: fmt.Sprintf("Verifying %s rollout", target.Microservice), Details: update.Message})
+				}
+			})
+			if err != nil {
+				sendSSEMessage(writer, progress.ProgressUpdate{Type: "progress", Stage: "verify", Service: target.Microservice, Status: "error", Message: fmt.Sprintf("Rollout failed for %s", target.Microservice), Details: err.Error()})
+				mu.Lock()
+				failures = append(failures, err.Error())
+				mu.Unlock()
+				return
+			}
+			sendSSEMessage(writer, progress.ProgressUpdate{Type: "progress", Stage: "verify", Service: target.Microservice, Status: "success", Message: fmt.Sprintf("%s rolled out", target.Microservice), Details: target.Image})
+		}(target)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("rollout verification failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -17,8 +17,8 @@ func NewRunner(ce *CommandExecutor) *Runner {
 }
 
 // SSE-based execution for deployment streaming
-func (r *Runner) RunOCDScriptWithSSE(ctx context.Context, folderPath string, writer chan []byte) {
-    r.executor.ExecuteWithSSE(ctx, folderPath, writer)
+func (r *Runner) RunOCDScriptWithSSE(ctx context.Context, request progress.DeployRequest, writer chan []byte) {
+    r.executor.ExecuteWithSSE(ctx, request, writer)
 }
 
 func (r *Runner) RunOCDScript(folderPath string) progress.Response { 
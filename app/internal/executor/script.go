@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"app/internal/config"
+	"app/internal/kube"
+	ocdscripts "deploy-scripts"
+)
+
+// RunScript runs an embedded script through a login bash with the proxy
+// enabled (WSL on Windows) and returns its stdout. Stderr is kept out of the
+// output so callers can parse it, and is reported in the error on failure.
+func RunScript(ctx context.Context, name string, args ...string) ([]byte, error) {
+	scriptBytes, err := ocdscripts.ReadScript(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded script %s: %w", name, err)
+	}
+
+	tempScriptFile, err := os.CreateTemp("", strings.TrimSuffix(name, ".sh")+"_*.sh")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp script: %w", err)
+	}
+	defer os.Remove(tempScriptFile.Name())
+
+	// Convert Windows line endings to Unix line endings for bash compatibility
+	scriptContent := strings.ReplaceAll(string(scriptBytes), "\r\n", "\n")
+	scriptContent = strings.ReplaceAll(scriptContent, "\r", "\n")
+	if _, err := tempScriptFile.WriteString(scriptContent); err != nil {
+		tempScriptFile.Close()
+		return nil, fmt.Errorf("failed to write temp script: %w", err)
+	}
+	tempScriptFile.Close()
+
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, shellEscape(arg))
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		if _, err := exec.LookPath("wsl"); err != nil {
+			return nil, fmt.Errorf("WSL not available on Windows. Please install WSL to use %s", name)
+		}
+		cmdString := fmt.Sprintf("proxy on 2>/dev/null || true && bash %s %s", shellEscape(convertToWSLPath(tempScriptFile.Name())), strings.Join(quoted, " "))
+		cmd = exec.CommandContext(ctx, "wsl", "bash", "-l", "-c", cmdString)
+	case "linux", "darwin":
+		cmdString := fmt.Sprintf("proxy on 2>/dev/null || true && bash %s %s", shellEscape(tempScriptFile.Name()), strings.Join(quoted, " "))
+		cmd = exec.CommandContext(ctx, "bash", "-l", "-c", cmdString)
+	default:
+		return nil, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// KubeShell returns the shell the deploy scripts run in, with the proxy on:
+// `wsl --user <WSLUser> bash -l` on Windows and a login bash elsewhere. The
+// kube client reads its kubeconfig and runs credential plugins through it, so
+// it reaches the cluster the scripts' kubectl does. Nil on other systems.
+func KubeShell(configuration *config.Config) kube.Shell {
+	var name string
+	var args []string
+	switch runtime.GOOS {
+	case "windows":
+		name, args = "wsl", []string{"--user", configuration.WSLUser, "bash", "-l", "-c"}
+	case "linux", "darwin":
+		name, args = "bash", []string{"-l", "-c"}
+	default:
+		return nil
+	}
+	return func(ctx context.Context, commandLine string) *exec.Cmd {
+		// The proxy's own messages must not mix with what the command prints
+		line := "proxy on >/dev/null 2>&1 || true && " + commandLine
+		return exec.CommandContext(ctx, name, append(append([]string(nil), args...), line)...)
+	}
+}
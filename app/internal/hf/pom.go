@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -146,3 +147,24 @@ func PrettyDiffText(items []DiffItem) string {
 	}
 	return buf.String()
 }
+
+// MarkdownDiffTable renders the diff as a Markdown table for pull request descriptions
+func MarkdownDiffTable(items []DiffItem) string {
+	sorted := append([]DiffItem(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Property < sorted[j].Property })
+
+	cell := func(value string) string {
+		if value == "" {
+			return "-"
+		}
+		return "`" + strings.ReplaceAll(value, "|", "\\|") + "`"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("| Property | Current | Proposed | Change |\n")
+	buf.WriteString("|---|---|---|---|\n")
+	for _, it := range sorted {
+		buf.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", cell(it.Property), cell(it.Current), cell(it.Proposed), it.Change))
+	}
+	return buf.String()
+}
@@ -0,0 +1,187 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"app/internal/bitbucket"
+)
+
+// branchCacheTTL is how long a repository's branch listing is reused before
+// Bitbucket is asked again; ?refresh=1 bypasses it
+const branchCacheTTL = 2 * time.Minute
+
+// branchFilter narrows a branch listing
+type branchFilter struct {
+	Prefix       string
+	Pattern      *regexp.Regexp
+	UpdatedSince time.Time
+}
+
+// parseBranchFilter reads prefix, regex and updated_since query parameters.
+// updated_since accepts RFC 3339, a date (2006-01-02) or a duration such as 72h.
+func parseBranchFilter(query map[string][]string, now time.Time) (branchFilter, error) {
+	get := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+
+	filter := branchFilter{Prefix: get("prefix")}
+	if expr := get("regex"); expr != "" {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid regex: %v", err)
+		}
+		filter.Pattern = pattern
+	}
+	if since := get("updated_since"); since != "" {
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			filter.UpdatedSince = t
+		} else if t, err := time.ParseInLocation("2006-01-02", since, time.Local); err == nil {
+			filter.UpdatedSince = t
+		} else if d, err := time.ParseDuration(since); err == nil && d > 0 {
+			filter.UpdatedSince = now.Add(-d)
+		} else {
+			return filter, fmt.Errorf("invalid updated_since %q: expected RFC 3339 time, date or duration", since)
+		}
+	}
+	return filter, nil
+}
+
+// matches reports whether a branch passes the filter
+func (f branchFilter) matches(branch GitBranch) bool {
+	if f.Prefix != "" && !strings.HasPrefix(branch.Name, f.Prefix) {
+		return false
+	}
+	if f.Pattern != nil && !f.Pattern.MatchString(branch.Name) {
+		return false
+	}
+	if !f.UpdatedSince.IsZero() && (branch.CommitTime == nil || branch.CommitTime.Before(f.UpdatedSince)) {
+		return false
+	}
+	return true
+}
+
+// branchListing is one cached branch listing
+type branchListing struct {
+	branches  []GitBranch
+	fetchedAt time.Time
+}
+
+// branchCache keeps recent branch listings per repository and credentials so
+// reopening a page does not list hundreds of branches again
+type branchCache struct {
+	mu      sync.Mutex
+	entries map[string]branchListing
+}
+
+func newBranchCache() *branchCache {
+	return &branchCache{entries: make(map[string]branchListing)}
+}
+
+// get returns the branches of a repository, from the cache unless it is stale
+// or refresh is set
+func (c *branchCache) get(ctx context.Context, client *bitbucket.Client, repo string, refresh bool) ([]GitBranch, time.Time, error) {
+	// Credentials are part of the key so one user's listing is never served to another
+	key := client.ProjectKey() + "/" + repo + "/" + client.CredentialsFingerprint()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && !refresh && time.Since(entry.fetchedAt) < branchCacheTTL {
+		return entry.branches, entry.fetchedAt, nil
+	}
+
+	branches, err := fetchBranchesFromBitbucketAPI(ctx, client, repo)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	entry = branchListing{branches: branches, fetchedAt: time.Now()}
+
+	c.mu.Lock()
+	for k, e := range c.entries {
+		if time.Since(e.fetchedAt) >= branchCacheTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry
+	c.mu.Unlock()
+	return entry.branches, entry.fetchedAt, nil
+}
+
+// fetchBranchesFromBitbucketAPI lists every branch of a repository with its
+// head commit and ahead/behind counts against the default branch, newest first
+func fetchBranchesFromBitbucketAPI(ctx context.Context, client *bitbucket.Client, repo string) ([]GitBranch, error) {
+	bitbucketBranches, err := client.Branches(ctx, repo, bitbucket.BranchQuery{Details: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort branches by commit timestamp (newest first)
+	sort.SliceStable(bitbucketBranches, func(i, j int) bool {
+		return bitbucketBranches[i].LatestCommitTime().After(bitbucketBranches[j].LatestCommitTime())
+	})
+
+	branches := make([]GitBranch, 0, len(bitbucketBranches))
+	for _, branch := range bitbucketBranches {
+		commitHash := branch.LatestCommit
+		if len(commitHash) > 8 {
+			commitHash = commitHash[:8]
+		}
+		gitBranch := GitBranch{
+			Name:       branch.DisplayID,
+			LastCommit: commitHash,
+			IsDefault:  branch.IsDefault,
+		}
+		if commit := branch.Metadata.LatestCommit; commit != nil {
+			committed := commit.CommitterTime()
+			gitBranch.CommitTime = &committed
+			gitBranch.Author = firstNonBlank(commit.Author.DisplayName, commit.Author.Name)
+			gitBranch.Message = strings.TrimSpace(strings.SplitN(commit.Message, "\n", 2)[0])
+		}
+		if aheadBehind := branch.Metadata.AheadBehind; aheadBehind != nil {
+			ahead, behind := aheadBehind.Ahead, aheadBehind.Behind
+			gitBranch.Ahead = &ahead
+			gitBranch.Behind = &behind
+		}
+		branches = append(branches, gitBranch)
+	}
+	return branches, nil
+}
+
+// branchesETag derives a strong validator from the branches being returned
+func branchesETag(branches []GitBranch) string {
+	encoded, _ := json.Marshal(branches)
+	sum := sha256.Sum256(encoded)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonBlank(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -1,25 +1,23 @@
 package httpapi
 
 import (
-	"crypto/tls"
 	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
-	"sort"
 	"strings"
 	"time"
 
+	"app/internal/bitbucket"
 	"app/internal/config"
+	"app/internal/eks"
 	"app/internal/executor"
+	"app/internal/jenkins"
+	"app/internal/jenkins/services"
 	"app/internal/progress"
 	"app/internal/ui"
 	"app/internal/version"
-	ocdscripts "deploy-scripts"
 )
 
 func HandleBrowse(w http.ResponseWriter, r *http.Request) {
@@ -72,205 +70,120 @@ func HandleHealth(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	health := map[string]interface{}{"status": "healthy", "timestamp": time.Now().Unix(), "version": version.Version, "commit": version.Commit, "date": version.Date}
+	status := "healthy"
+	breakers := jenkins.BreakerStates()
+	for _, breaker := range breakers {
+		if breaker.State != jenkins.BreakerClosed {
+			status = "degraded"
+		}
+	}
+	health := map[string]interface{}{"status": status, "timestamp": time.Now().Unix(), "version": version.Version, "commit": version.Commit, "date": version.Date, "jenkins_breakers": breakers}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(health)
 }
 
-// Jenkins handlers have been moved to handlers_jenkins.go for better organization
-
-func HandleEKSClusters(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// DEBUG: Log the start of EKS handler
-	fmt.Printf("[DEBUG] EKS Handler: Starting embedded script execution\n")
-
-	// Use embedded list-eks-clusters.sh script following the same pattern as command_executor.go
-	tempScriptFile, err := os.CreateTemp("", "list-eks-clusters_*.sh")
-	if err != nil {
-		fmt.Printf("[DEBUG] EKS Handler: Failed to create temp file: %v\n", err)
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  false,
-			"message":  "DEBUG: Failed to create temp script: " + err.Error(),
-			"clusters": []string{},
-		})
-		return
-	}
-	defer tempScriptFile.Close()
-	fmt.Printf("[DEBUG] EKS Handler: Created temp script: %s\n", tempScriptFile.Name())
+// HandleHealthDependencies reports the reachability of every external dependency
+func HandleHealthDependencies(monitoring services.MonitoringService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-	scriptBytes, err := ocdscripts.ReadScript("list-eks-clusters.sh")
-	if err != nil {
-		fmt.Printf("[DEBUG] EKS Handler: Failed to read embedded script: %v\n", err)
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  false,
-			"message":  "DEBUG: Failed to read embedded script: " + err.Error(),
-			"clusters": []string{},
+		status, err := monitoring.HealthCheck(r.Context())
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "Failed to check dependencies: "+err.Error())
+			return
+		}
+		metrics, _ := monitoring.GetServiceMetrics(r.Context())
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"status":       status.Status,
+			"details":      status.Details,
+			"checked_at":   status.LastChecked,
+			"dependencies": status.Metrics["dependencies"],
+			"metrics":      metrics,
 		})
-		return
 	}
-	fmt.Printf("[DEBUG] EKS Handler: Read embedded script, size: %d bytes\n", len(scriptBytes))
+}
 
-	// Convert Windows line endings to Unix line endings for bash compatibility
-	scriptContent := strings.ReplaceAll(string(scriptBytes), "\r\n", "\n")
-	scriptContent = strings.ReplaceAll(scriptContent, "\r", "\n")
-	previewLen := 100
-	if len(scriptContent) < previewLen {
-		previewLen = len(scriptContent)
-	}
-	fmt.Printf("[DEBUG] EKS Handler: Script content preview: %s...\n", scriptContent[:previewLen])
+// Jenkins handlers have been moved to handlers_jenkins.go for better organization
 
-	if _, err := tempScriptFile.Write([]byte(scriptContent)); err != nil {
-		fmt.Printf("[DEBUG] EKS Handler: Failed to write script: %v\n", err)
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  false,
-			"message":  "DEBUG: Failed to write temp script: " + err.Error(),
-			"clusters": []string{},
-		})
-		return
-	}
-	if err := os.Chmod(tempScriptFile.Name(), 0755); err != nil {
-		fmt.Printf("[DEBUG] EKS Handler: Failed to chmod script: %v\n", err)
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  false,
-			"message":  "DEBUG: Failed to chmod temp script: " + err.Error(),
-			"clusters": []string{},
-		})
-		return
-	}
-	fmt.Printf("[DEBUG] EKS Handler: Script written and made executable\n")
+// HandleEKSClusters lists EKS clusters from the cluster inventory. clusters
+// holds the names for existing callers and details the region, account and
+// status of each; ?refresh=1 bypasses the inventory cache.
+func HandleEKSClusters(inventory *eks.Inventory) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-	// Build command following the same pattern as command_executor.go
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "windows":
-		if _, err := exec.LookPath("wsl"); err == nil {
-			wslScriptPath := convertToWSLPath(tempScriptFile.Name())
-			cmdString := fmt.Sprintf("proxy on 2>/dev/null || true && bash %s", wslScriptPath)
-			fmt.Printf("[DEBUG] EKS Handler: Executing WSL command: wsl bash -l -c \"%s\"\n", cmdString)
-			cmd = exec.Command("wsl", "bash", "-l", "-c", cmdString)
-		} else {
-			fmt.Printf("[DEBUG] EKS Handler: WSL not available on Windows\n")
+		listing, err := inventory.List(r.Context(), r.URL.Query().Get("refresh") == "1")
+		if err != nil {
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(map[string]interface{}{
 				"success":  false,
-				"message":  "DEBUG: WSL not available on Windows. Please install WSL to use EKS features",
+				"message":  "Failed to list EKS clusters: " + err.Error(),
 				"clusters": []string{},
 			})
 			return
 		}
-	case "linux", "darwin":
-		cmdString := fmt.Sprintf("proxy on 2>/dev/null || true && bash %s", tempScriptFile.Name())
-		fmt.Printf("[DEBUG] EKS Handler: Executing command: bash -l -c \"%s\"\n", cmdString)
-		cmd = exec.Command("bash", "-l", "-c", cmdString)
-	default:
-		fmt.Printf("[DEBUG] EKS Handler: Unsupported OS: %s\n", runtime.GOOS)
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  false,
-			"message":  fmt.Sprintf("DEBUG: Unsupported operating system: %s", runtime.GOOS),
-			"clusters": []string{},
-		})
-		return
-	}
 
-	// Capture both stdout and stderr for debugging
-	output, err := cmd.CombinedOutput()
-	fmt.Printf("[DEBUG] EKS Handler: Command output length: %d bytes\n", len(output))
-	fmt.Printf("[DEBUG] EKS Handler: Command error: %v\n", err)
-	if err != nil {
-		fmt.Printf("[DEBUG] EKS Handler: Full command output: %s\n", string(output))
-	}
-	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  false,
-			"message":  "DEBUG: Failed to list EKS clusters: " + err.Error() + " | Output: " + string(output),
-			"clusters": []string{},
+			"success":    true,
+			"message":    "EKS clusters retrieved successfully",
+			"clusters":   listing.Names(),
+			"details":    listing.Clusters,
+			"fetched_at": listing.FetchedAt,
+			"errors":     listing.Errors,
 		})
-		return
-	}
-	fmt.Printf("[DEBUG] EKS Handler: Command executed successfully, output: %s\n", string(output))
-
-	// Parse the output to extract cluster names
-	// AWS CLI with --output text returns space/tab-separated values on a single line
-	// Filter out screen size warnings and other noise
-	outputStr := strings.TrimSpace(string(output))
-	var clusters []string
-	if outputStr != "" {
-		// Split by lines first to filter out screen size warnings
-		lines := strings.Split(outputStr, "\n")
-		var cleanOutput []string
-
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			// Skip screen size warnings and other noise
-			if line != "" &&
-				!strings.Contains(line, "screen size is bogus") &&
-				!strings.Contains(line, "expect trouble") &&
-				!strings.HasPrefix(line, "your ") {
-				cleanOutput = append(cleanOutput, line)
-			}
-		}
-
-		// Now split by whitespace to handle space or tab-separated cluster names
-		for _, cleanLine := range cleanOutput {
-			clusterNames := strings.Fields(cleanLine)
-			for _, name := range clusterNames {
-				name = strings.TrimSpace(name)
-				// Additional filtering: only accept valid cluster name patterns
-				if name != "" &&
-					!strings.HasPrefix(name, "NAME") &&
-					!strings.Contains(name, "x1") && // Filter out screen size fragments
-					len(name) > 2 { // Cluster names should be reasonable length
-					clusters = append(clusters, name)
-				}
-			}
-		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":  true,
-		"message":  "EKS clusters retrieved successfully",
-		"clusters": clusters,
-	})
 }
 
 type GitBranch struct {
-	Name       string `json:"name"`
-	LastCommit string `json:"lastCommit"`
+	Name       string     `json:"name"`
+	LastCommit string     `json:"lastCommit"`
+	Author     string     `json:"author,omitempty"`
+	Message    string     `json:"message,omitempty"` // first line of the head commit message
+	CommitTime *time.Time `json:"commitTime,omitempty"`
+	Ahead      *int       `json:"ahead,omitempty"` // versus the default branch
+	Behind     *int       `json:"behind,omitempty"`
+	IsDefault  bool       `json:"isDefault,omitempty"`
 }
 
-func HandleGitBranchesCustomization(configuration *config.Config) http.HandlerFunc {
+// HandleGitBranchesCustomization lists customization repository branches.
+// Query parameters prefix, regex and updated_since filter the listing; the
+// listing is cached briefly and served with an ETag so unchanged results
+// revalidate with 304 Not Modified.
+func HandleGitBranchesCustomization(configuration *config.Config, bitbucketClient *bitbucket.Client) http.HandlerFunc {
+	cache := newBranchCache()
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		username := r.Header.Get("X-Bitbucket-Username")
-		token := r.Header.Get("X-Bitbucket-Token")
-
-		if username == "" {
-			username = os.Getenv("BITBUCKET_USERNAME")
-		}
-		if token == "" {
-			token = os.Getenv("BITBUCKET_TOKEN")
+		filter, err := parseBranchFilter(r.URL.Query(), time.Now())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success":  false,
+				"message":  err.Error(),
+				"branches": []GitBranch{},
+			})
+			return
 		}
 
+		// Request headers take precedence over the configured credentials
+		client := bitbucketClient.WithCredentials(r.Header.Get("X-Bitbucket-Username"), r.Header.Get("X-Bitbucket-Token"))
+
 		var branches []GitBranch
-		var err error
+		var fetchedAt time.Time
 
-		if username != "" && token != "" {
-			branches, err = fetchBranchesFromBitbucketAPI(configuration, username, token)
+		if client.HasCredentials() {
+			refresh := r.URL.Query().Get("refresh") == "1"
+			branches, fetchedAt, err = cache.get(r.Context(), client, configuration.Endpoints.BitbucketCustomizationRepo, refresh)
 			if err != nil {
 				w.Header().Set("Content-Type", "application/json")
 				_ = json.NewEncoder(w).Encode(map[string]interface{}{
@@ -291,51 +204,33 @@ func HandleGitBranchesCustomization(configuration *config.Config) http.HandlerFu
 			return
 		}
 
+		matched := make([]GitBranch, 0, len(branches))
+		for _, branch := range branches {
+			if filter.matches(branch) {
+				matched = append(matched, branch)
+			}
+		}
+
+		// Browsers revalidate on every use and get 304 while the listing is unchanged
+		etag := branchesETag(matched)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "private, no-cache")
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  true,
-			"message":  "Git branches retrieved successfully",
-			"branches": branches,
+			"success":   true,
+			"message":   "Git branches retrieved successfully",
+			"branches":  matched,
+			"total":     len(branches),
+			"fetchedAt": fetchedAt,
 		})
 	}
 }
 
-type BitbucketBranch struct {
-	ID           string            `json:"id"`
-	DisplayID    string            `json:"displayId"`
-	Type         string            `json:"type"`
-	LatestCommit string            `json:"latestCommit"`
-	Metadata     BitbucketMetadata `json:"metadata"`
-}
-
-type BitbucketMetadata struct {
-	LatestCommitMetadata LatestCommitMetadata `json:"com.atlassian.bitbucket.server.bitbucket-branch:latest-commit-metadata"`
-}
-
-type LatestCommitMetadata struct {
-	ID                 string `json:"id"`
-	DisplayID          string `json:"displayId"`
-	AuthorTimestamp    int64  `json:"authorTimestamp"`
-	CommitterTimestamp int64  `json:"committerTimestamp"`
-	Message            string `json:"message"`
-}
-
-type BitbucketResponse struct {
-	Size          int               `json:"size"`
-	Limit         int               `json:"limit"`
-	IsLastPage    bool              `json:"isLastPage"`
-	Values        []BitbucketBranch `json:"values"`
-	Start         int               `json:"start"`
-	NextPageStart int               `json:"nextPageStart"`
-}
-
-func newHTTPClient(configuration *config.Config) *http.Client {
-	if configuration != nil && configuration.TLS.InsecureSkipVerify {
-		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
-	}
-	return &http.Client{}
-}
-
 // HandleRNCreate handles RN creation requests
 func HandleRNCreate(configuration *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -376,79 +271,6 @@ func HandleRNCreate(configuration *config.Config) http.HandlerFunc {
 	}
 }
 
-func fetchBranchesFromBitbucketAPI(configuration *config.Config, username, token string) ([]GitBranch, error) {
-	client := newHTTPClient(configuration)
-
-	url := configuration.Endpoints.BitbucketBranchesAPI()
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// Set basic auth
-	req.SetBasicAuth(username, token)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
-
-	var bitbucketResp BitbucketResponse
-	if err := json.Unmarshal(body, &bitbucketResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
-	}
-
-	// Convert to our GitBranch format with commit timestamps
-	var branchesWithTimestamp []struct {
-		GitBranch
-		CommitTimestamp int64
-	}
-
-	for _, branch := range bitbucketResp.Values {
-		commitHash := branch.LatestCommit
-		if len(commitHash) > 8 {
-			commitHash = commitHash[:8]
-		}
-
-		branchWithTimestamp := struct {
-			GitBranch
-			CommitTimestamp int64
-		}{
-			GitBranch: GitBranch{
-				Name:       branch.DisplayID,
-				LastCommit: commitHash,
-			},
-			CommitTimestamp: branch.Metadata.LatestCommitMetadata.CommitterTimestamp,
-		}
-		branchesWithTimestamp = append(branchesWithTimestamp, branchWithTimestamp)
-	}
-
-	// Sort branches by commit timestamp (newest first)
-	sort.Slice(branchesWithTimestamp, func(i, j int) bool {
-		return branchesWithTimestamp[i].CommitTimestamp > branchesWithTimestamp[j].CommitTimestamp
-	})
-
-	// Extract just the GitBranch structs
-	var branches []GitBranch
-	for _, branchWithTimestamp := range branchesWithTimestamp {
-		branches = append(branches, branchWithTimestamp.GitBranch)
-	}
-
-	return branches, nil
-}
-
 // convertToWSLPath converts Windows paths to WSL paths - same function as in command_executor.go
 func convertToWSLPath(windowsPath string) string {
 	if runtime.GOOS != "windows" {
@@ -13,7 +13,10 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
+	"app/internal/bitbucket"
+	"app/internal/config"
 	"app/internal/hf"
 	ocdscripts "deploy-scripts"
 )
@@ -138,181 +141,308 @@ func HandleHFParseEmail(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HandleHFUpdatePOM updates pom.xml versions based on provided mapping (stub for now)
-func HandleHFUpdatePOM(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+// HandleHFUpdatePOM updates pom.xml versions based on provided mapping. The
+// change is either pushed to the branch directly or proposed from a feature
+// branch through a Bitbucket pull request, per request or configured mode.
+func HandleHFUpdatePOM(configuration *config.Config, bitbucketClient *bitbucket.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-	var req struct {
-		RepoURL   string            `json:"repo_url"`
-		Branch    string            `json:"branch"`
-		Versions  map[string]string `json:"versions"`
-		DryRun    bool              `json:"dry_run"`
-		PomPath   string            `json:"pom_path"`   // optional, default root pom.xml
-		WorkDir   string            `json:"work_dir"`   // optional temp dir override
-		CommitMsg string            `json:"commit_msg"` // optional
-		Username  string            `json:"username"`   // optional for https auth
-		Token     string            `json:"token"`
-		Debug     bool              `json:"debug"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"message": "Invalid request format",
-		})
-		return
-	}
+		var req struct {
+			RepoURL   string            `json:"repo_url"`
+			Branch    string            `json:"branch"`
+			Versions  map[string]string `json:"versions"`
+			DryRun    bool              `json:"dry_run"`
+			PomPath   string            `json:"pom_path"`   // optional, default root pom.xml
+			WorkDir   string            `json:"work_dir"`   // optional temp dir override
+			CommitMsg string            `json:"commit_msg"` // optional
+			Username  string            `json:"username"`   // optional for https auth
+			Token     string            `json:"token"`
+			Debug     bool              `json:"debug"`
+			Mode      string            `json:"mode"`      // push | pull_request, default from configuration
+			Reviewers []string          `json:"reviewers"` // optional, added to the configured reviewers
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"message": "Invalid request format",
+			})
+			return
+		}
 
-	if req.RepoURL == "" || req.Branch == "" || len(req.Versions) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"message": "repo_url, branch and versions are required",
-		})
-		return
-	}
+		if req.RepoURL == "" || req.Branch == "" || len(req.Versions) == 0 {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"message": "repo_url, branch and versions are required",
+			})
+			return
+		}
 
-	// Create temp workdir
-	workDir := req.WorkDir
-	if workDir == "" {
-		var err error
-		workDir, err = os.MkdirTemp("", "hf_repo_*")
-		if err != nil {
+		mode := strings.ToLower(strings.TrimSpace(req.Mode))
+		if mode == "" {
+			mode = configuration.HF.UpdateMode
+		}
+		if mode != config.HFUpdateModePush && mode != config.HFUpdateModePullRequest {
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(map[string]interface{}{
 				"success": false,
-				"message": fmt.Sprintf("Failed to create temp dir: %v", err),
+				"message": fmt.Sprintf("Unsupported mode %q (expected %s or %s)", mode, config.HFUpdateModePush, config.HFUpdateModePullRequest),
 			})
 			return
 		}
-		keepWorkDir := req.Debug || r.URL.Query().Get("debug") == "1" || r.Header.Get("X-Debug") == "1" || os.Getenv("OCD_DEBUG") == "1"
-		if !keepWorkDir {
-			defer os.RemoveAll(workDir)
+
+		// Resolve the pull request target up front so a bad URL or missing
+		// credentials fail before anything is cloned or pushed
+		var prClient *bitbucket.Client
+		var prRepo string
+		if mode == config.HFUpdateModePullRequest && !req.DryRun {
+			projectKey, repo, err := bitbucket.ParseCloneURL(req.RepoURL)
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"success": false,
+					"message": err.Error(),
+				})
+				return
+			}
+			prClient = bitbucketClient.WithCredentials(req.Username, req.Token).WithProject(projectKey)
+			prRepo = repo
+			if !prClient.HasCredentials() {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"success": false,
+					"message": "Bitbucket credentials are required to open a pull request",
+				})
+				return
+			}
 		}
-	}
 
-	// Prepare values
-	repoURL := req.RepoURL
+		// Create temp workdir
+		workDir := req.WorkDir
+		if workDir == "" {
+			var err error
+			workDir, err = os.MkdirTemp("", "hf_repo_*")
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"success": false,
+					"message": fmt.Sprintf("Failed to create temp dir: %v", err),
+				})
+				return
+			}
+			keepWorkDir := req.Debug || r.URL.Query().Get("debug") == "1" || r.Header.Get("X-Debug") == "1" || os.Getenv("OCD_DEBUG") == "1"
+			if !keepWorkDir {
+				defer os.RemoveAll(workDir)
+			}
+		}
 
-	// git clone and checkout via embedded script (handles proxy)
-	if out, err := runShell(workDir, "git-clone.sh", "--repo", repoURL, "--branch", req.Branch, "--dir", filepath.Join(workDir, "repo"), "--username", req.Username, "--token", req.Token); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"message": fmt.Sprintf("git clone failed: %v | %s", err, out),
-		})
-		return
-	}
+		// Prepare values
+		repoURL := req.RepoURL
 
-	repoPath := filepath.Join(workDir, "repo")
-	pomPath := req.PomPath
-	if pomPath == "" {
-		pomPath = filepath.Join(repoPath, "pom.xml")
-	} else {
-		pomPath = filepath.Join(repoPath, pomPath)
-	}
+		// git clone and checkout via embedded script (handles proxy)
+		if out, err := runShell(workDir, "git-clone.sh", "--repo", repoURL, "--branch", req.Branch, "--dir", filepath.Join(workDir, "repo"), "--username", req.Username, "--token", req.Token); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("git clone failed: %v | %s", err, out),
+			})
+			return
+		}
 
-	// read current pom
-	pomBytes, err := os.ReadFile(pomPath)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"message": fmt.Sprintf("Failed to read pom.xml: %v", err),
-		})
-		return
-	}
+		repoPath := filepath.Join(workDir, "repo")
+		pomPath := req.PomPath
+		if pomPath == "" {
+			pomPath = filepath.Join(repoPath, "pom.xml")
+		} else {
+			pomPath = filepath.Join(repoPath, pomPath)
+		}
 
-	// build diff
-	keys := make([]string, 0, len(req.Versions))
-	for k := range req.Versions {
-		keys = append(keys, k)
-	}
-	current := hf.ExtractVersions(pomBytes, keys)
-	diff := hf.BuildDiff(current, req.Versions)
+		// read current pom
+		pomBytes, err := os.ReadFile(pomPath)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("Failed to read pom.xml: %v", err),
+			})
+			return
+		}
 
-	if req.DryRun {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": true,
-			"message": "Dry-run diff generated",
-			"diff":    diff,
-			"pretty":  hf.PrettyDiffText(diff),
-		})
-		return
-	}
+		// build diff
+		keys := make([]string, 0, len(req.Versions))
+		for k := range req.Versions {
+			keys = append(keys, k)
+		}
+		current := hf.ExtractVersions(pomBytes, keys)
+		diff := hf.BuildDiff(current, req.Versions)
 
-	// Apply changes
-	updated, err := hf.UpdatePOMVersions(pomBytes, req.Versions)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"message": fmt.Sprintf("Failed to update pom: %v", err),
-		})
-		return
-	}
-	if err := os.WriteFile(pomPath, updated, 0644); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"message": fmt.Sprintf("Failed to write pom.xml: %v", err),
-		})
-		return
-	}
+		if req.DryRun {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": true,
+				"message": "Dry-run diff generated",
+				"diff":    diff,
+				"pretty":  hf.PrettyDiffText(diff),
+			})
+			return
+		}
 
-	// git commit and push via embedded script (handles proxy)
-	// Build commit message and include HF token from subject if available (X-HF-Subject header)
-	msg := req.CommitMsg
-	if msg == "" {
-		dop := ""
-		if raw := r.Header.Get("X-HF-Subject"); raw != "" {
-			// Decode MIME encoded words if present
-			if decoded, err := new(mime.WordDecoder).DecodeHeader(raw); err == nil && decoded != "" {
-				raw = decoded
+		// Apply changes
+		updated, err := hf.UpdatePOMVersions(pomBytes, req.Versions)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("Failed to update pom: %v", err),
+			})
+			return
+		}
+		if err := os.WriteFile(pomPath, updated, 0644); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("Failed to write pom.xml: %v", err),
+			})
+			return
+		}
+
+		// git commit and push via embedded script (handles proxy)
+		// Build commit message and include HF token from subject if available (X-HF-Subject header)
+		msg := req.CommitMsg
+		if msg == "" {
+			dop := ""
+			if raw := r.Header.Get("X-HF-Subject"); raw != "" {
+				// Decode MIME encoded words if present
+				if decoded, err := new(mime.WordDecoder).DecodeHeader(raw); err == nil && decoded != "" {
+					raw = decoded
+				}
+				// Remove known prefixes not needed in commit title
+				raw = regexp.MustCompile(`(?i)^\s*Releases\s*»\s*ReleaseForHF\s*-?\s*`).ReplaceAllString(raw, "")
+				// Try to extract version like 10.4.826-hf2503.41 from subject
+				if m := regexp.MustCompile(`\b\d+\.\d+\.\d+-hf\d{4}\.\d+\b`).FindString(raw); m != "" {
+					dop = m
+				} else if m := regexp.MustCompile(`\bhf\d{4}\b`).FindString(strings.ToLower(raw)); m != "" {
+					dop = strings.ToUpper(m)
+				} else {
+					// Fallback: use full subject (no truncation)
+					dop = strings.TrimSpace(raw)
+				}
 			}
-			// Remove known prefixes not needed in commit title
-			raw = regexp.MustCompile(`(?i)^\s*Releases\s*»\s*ReleaseForHF\s*-?\s*`).ReplaceAllString(raw, "")
-			// Try to extract version like 10.4.826-hf2503.41 from subject
-			if m := regexp.MustCompile(`\b\d+\.\d+\.\d+-hf\d{4}\.\d+\b`).FindString(raw); m != "" {
-				dop = m
-			} else if m := regexp.MustCompile(`\bhf\d{4}\b`).FindString(strings.ToLower(raw)); m != "" {
-				dop = strings.ToUpper(m)
+			if dop != "" {
+				msg = fmt.Sprintf("OCD: HF Adoption - update versions (%s)", dop)
 			} else {
-				// Fallback: use full subject (no truncation)
-				dop = strings.TrimSpace(raw)
+				msg = "OCD: HF Adoption - update versions"
 			}
 		}
-		if dop != "" {
-			msg = fmt.Sprintf("OCD: HF Adoption - update versions (%s)", dop)
-		} else {
-			msg = "OCD: HF Adoption - update versions"
+		pushArgs := []string{"--repo-dir", repoPath, "--branch", req.Branch, "--message", msg, "--username", req.Username, "--token", req.Token}
+		featureBranch := ""
+		if mode == config.HFUpdateModePullRequest {
+			if !hasVersionChanges(diff) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"success": false,
+					"message": "pom.xml already has the requested versions; no pull request needed",
+					"diff":    diff,
+				})
+				return
+			}
+			featureBranch = hfFeatureBranch(msg, time.Now())
+			pushArgs = append(pushArgs, "--new-branch", featureBranch)
 		}
-	}
-	if out, err := runShell(workDir, "git-commit-push.sh", "--repo-dir", repoPath, "--branch", req.Branch, "--message", msg, "--username", req.Username, "--token", req.Token); err != nil {
+		if out, err := runShell(workDir, "git-commit-push.sh", pushArgs...); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			resp := map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("git commit/push failed: %v | %s", err, out),
+			}
+			if req.Debug || r.URL.Query().Get("debug") == "1" {
+				resp["work_dir"] = workDir
+				resp["repo_dir"] = repoPath
+				resp["output"] = out
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		if mode == config.HFUpdateModePush {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": true,
+				"message": "POM updated and pushed successfully",
+				"diff":    diff,
+			})
+			return
+		}
+
+		reviewers := append(append([]string(nil), configuration.HF.Reviewers...), req.Reviewers...)
+		pullRequest, err := prClient.CreatePullRequest(r.Context(), prRepo, bitbucket.NewPullRequest{
+			Title:       msg,
+			Description: hfPullRequestDescription(req.Branch, diff),
+			FromBranch:  featureBranch,
+			ToBranch:    req.Branch,
+			Reviewers:   reviewers,
+		})
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":       false,
+				"message":       fmt.Sprintf("Branch %s was pushed but the pull request could not be created: %v", featureBranch, err),
+				"source_branch": featureBranch,
+				"diff":          diff,
+			})
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
-		resp := map[string]interface{}{
-			"success": false,
-			"message": fmt.Sprintf("git commit/push failed: %v | %s", err, out),
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":       true,
+			"message":       "POM updated and pull request opened",
+			"diff":          diff,
+			"source_branch": featureBranch,
+			"pr_id":         pullRequest.ID,
+			"pr_url":        pullRequest.URL(),
+		})
+	}
+}
+
+// hasVersionChanges reports whether applying the diff changes pom.xml
+func hasVersionChanges(diff []hf.DiffItem) bool {
+	for _, item := range diff {
+		if item.Change != "same" && item.Change != "missing" {
+			return true
 		}
-		if req.Debug || r.URL.Query().Get("debug") == "1" {
-			resp["work_dir"] = workDir
-			resp["repo_dir"] = repoPath
-			resp["output"] = out
+	}
+	return false
+}
+
+var (
+	hfBranchUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	hfCommitTag    = regexp.MustCompile(`\(([^)]+)\)\s*$`)
+)
+
+// hfFeatureBranch names the branch an HF pull request is raised from, using the
+// HF version from the commit title when present
+func hfFeatureBranch(commitMsg string, now time.Time) string {
+	name := "ocd-hf-adoption"
+	if m := hfCommitTag.FindStringSubmatch(commitMsg); m != nil {
+		if tag := strings.Trim(hfBranchUnsafe.ReplaceAllString(m[1], "-"), "-."); tag != "" {
+			name += "-" + strings.ToLower(tag)
 		}
-		_ = json.NewEncoder(w).Encode(resp)
-		return
 	}
+	return fmt.Sprintf("feature/%s-%s", name, now.Format("20060102-150405"))
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": "POM updated and pushed successfully",
-		"diff":    diff,
-	})
+// hfPullRequestDescription renders the pull request body with the version diff table
+func hfPullRequestDescription(targetBranch string, diff []hf.DiffItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "HF adoption: parent-pom version updates for `%s`, raised by OCD.\n\n", targetBranch)
+	b.WriteString(hf.MarkdownDiffTable(diff))
+	return b.String()
 }
 
 // runShell executes an embedded script similarly to other modules (ensures proxy on)
@@ -1,38 +1,39 @@
 package httpapi
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os/user"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"app/internal/config"
-	"app/internal/jenkins"
+	jenkinsconfig "app/internal/jenkins/config"
+	jenkinserrors "app/internal/jenkins/errors"
 	"app/internal/jenkins/services"
 	"app/internal/jenkins/types"
+	"app/internal/nexus"
 )
 
 // JenkinsHandlers contains all Jenkins-related HTTP handlers
 type JenkinsHandlers struct {
-	configuration     *config.Config
-	client            *jenkins.Client
-	scalingService    services.ScalingService
-	artifactsService  services.ArtifactsService
-	rnCreationService services.RNCreationService
+	configuration *config.Config
+	services      services.ServiceManager
+	verifications *scaleVerifications // post-scale checks started through HandleScaleVerify
 }
 
-// NewJenkinsHandlers creates a new Jenkins handlers instance
-func NewJenkinsHandlers(configuration *config.Config, client *jenkins.Client) *JenkinsHandlers {
+// NewJenkinsHandlers creates a new Jenkins handlers instance backed by the service manager
+func NewJenkinsHandlers(configuration *config.Config, manager services.ServiceManager) *JenkinsHandlers {
 	return &JenkinsHandlers{
-		configuration:     configuration,
-		client:            client,
-		scalingService:    services.NewScalingService(configuration, client),
-		artifactsService:  services.NewArtifactsService(client),
-		rnCreationService: services.NewRNCreationService(configuration, client),
+		configuration: configuration,
+		services:      manager,
+		verifications: newScaleVerifications(),
 	}
 }
 
@@ -55,7 +56,7 @@ func (h *JenkinsHandlers) HandleJenkinsScale() http.HandlerFunc {
 		}
 
 		// Check if we have credentials either from environment or request
-		hasEnvCredentials := h.client.IsConfigured()
+		hasEnvCredentials := h.services.GetDeliveryClient().IsConfigured()
 		hasRequestCredentials := req.Username != "" && req.Token != ""
 
 		if !hasEnvCredentials && !hasRequestCredentials {
@@ -67,33 +68,22 @@ func (h *JenkinsHandlers) HandleJenkinsScale() http.HandlerFunc {
 			return
 		}
 
-		// Create a context for the request
-		ctx := request.Context()
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
 
-		// Use appropriate client for the request
-		var scalingService services.ScalingService
-		if hasRequestCredentials {
-			// Create temporary client with request credentials
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: req.Username,
-				Token:    req.Token,
-			})
-			if err != nil {
-				response.Header().Set("Content-Type", "application/json")
-				_ = json.NewEncoder(response).Encode(map[string]interface{}{
-					"success": false,
-					"message": "Failed to create Jenkins client: " + err.Error(),
-				})
-				return
-			}
-			scalingService = services.NewScalingService(h.configuration, tempClient)
-		} else {
-			scalingService = h.scalingService
-		}
+		req.RequestedBy = h.scaleRequester(req.Username)
+		req.Source = types.ScaleSourceManual
 
 		// Trigger the scaling operation
-		scaleResponse, err := scalingService.TriggerScale(ctx, &req.ScaleRequest)
+		scaleResponse, err := h.services.GetScalingService().TriggerScale(ctx, &req.ScaleRequest)
+		var policyErr *services.ScalePolicyError
+		if errors.As(err, &policyErr) {
+			writeJSON(response, http.StatusForbidden, map[string]interface{}{
+				"success":    false,
+				"message":    policyErr.Error(),
+				"violations": policyErr.Violations,
+			})
+			return
+		}
 		if err != nil {
 			response.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(response).Encode(map[string]interface{}{
@@ -114,6 +104,120 @@ func (h *JenkinsHandlers) HandleJenkinsScale() http.HandlerFunc {
 	}
 }
 
+// HandleScalingBatch scales a list or group of clusters at once and returns
+// the aggregate result; refusals and failures of single clusters are reported
+// per cluster rather than failing the batch
+func (h *JenkinsHandlers) HandleScalingBatch() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req struct {
+			types.ScaleBatchRequest
+			Username string `json:"username"`
+			Token    string `json:"token"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+			writeJSONError(response, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		req.RequestedBy = h.scaleRequester(req.Username)
+		req.Source = types.ScaleSourceManual
+
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
+		result, err := h.services.GetScalingService().TriggerBatch(ctx, &req.ScaleBatchRequest)
+		var policyErr *services.ScalePolicyError
+		if errors.As(err, &policyErr) {
+			writeJSON(response, http.StatusForbidden, map[string]interface{}{
+				"success":    false,
+				"message":    policyErr.Error(),
+				"violations": policyErr.Violations,
+			})
+			return
+		}
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), err.Error())
+			return
+		}
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success": result.Counts[types.ScaleBatchFailed] == 0 && result.Counts[types.ScaleBatchRefused] == 0,
+			"batch":   result,
+		})
+	}
+}
+
+// HandleScalingBatchStatus refreshes the per-cluster job status of a batch
+func (h *JenkinsHandlers) HandleScalingBatchStatus() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req struct {
+			BatchID  string `json:"batch_id"`
+			Username string `json:"username"`
+			Token    string `json:"token"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+			writeJSONError(response, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		if strings.TrimSpace(req.BatchID) == "" {
+			writeJSONError(response, http.StatusBadRequest, "batch_id is required")
+			return
+		}
+
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
+		result, err := h.services.GetScalingService().GetBatchStatus(ctx, strings.TrimSpace(req.BatchID))
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), err.Error())
+			return
+		}
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"batch":   result,
+		})
+	}
+}
+
+// HandleScalingGroups lists the cluster groups batch scaling accepts
+func (h *JenkinsHandlers) HandleScalingGroups() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		groups, err := h.services.GetScalingService().GetClusterGroups(request.Context())
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), err.Error())
+			return
+		}
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"groups":  groups,
+		})
+	}
+}
+
+// scaleRequester names who is scaling for the audit log: the Jenkins user of
+// the request, else the configured Jenkins user, else the OS user running OCD
+func (h *JenkinsHandlers) scaleRequester(username string) string {
+	if username = strings.TrimSpace(username); username != "" {
+		return username
+	}
+	if h.configuration != nil && h.configuration.Jenkins.Username != "" {
+		return h.configuration.Jenkins.Username
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return ""
+}
+
 // HandleJenkinsStatus handles Jenkins job status queries
 func (h *JenkinsHandlers) HandleJenkinsStatus() http.HandlerFunc {
 	return func(response http.ResponseWriter, request *http.Request) {
@@ -144,28 +248,10 @@ func (h *JenkinsHandlers) HandleJenkinsStatus() http.HandlerFunc {
 			return
 		}
 
-		// Create context for the request
-		ctx := request.Context()
-
-		// Use appropriate client for the request
-		var scalingService services.ScalingService
-		if username != "" && token != "" {
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: username,
-				Token:    token,
-			})
-			if err != nil {
-				writeJSONError(response, http.StatusInternalServerError, "Failed to create Jenkins client: "+err.Error())
-				return
-			}
-			scalingService = services.NewScalingService(h.configuration, tempClient)
-		} else {
-			scalingService = h.scalingService
-		}
+		ctx := h.services.WithCredentials(request.Context(), username, token)
 
 		// Get job status
-		jobStatus, err := scalingService.GetScaleJobStatus(ctx, jobNumber)
+		jobStatus, err := h.services.GetScalingService().GetScaleJobStatus(ctx, jobNumber)
 		if err != nil {
 			writeJSONError(response, http.StatusInternalServerError, "Failed to get job status: "+err.Error())
 			return
@@ -202,28 +288,10 @@ func (h *JenkinsHandlers) HandleJenkinsQueueStatus() http.HandlerFunc {
 			return
 		}
 
-		// Create context for the request
-		ctx := request.Context()
-
-		// Use appropriate client for the request
-		var scalingService services.ScalingService
-		if username != "" && token != "" {
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: username,
-				Token:    token,
-			})
-			if err != nil {
-				writeJSONError(response, http.StatusInternalServerError, "Failed to create Jenkins client: "+err.Error())
-				return
-			}
-			scalingService = services.NewScalingService(h.configuration, tempClient)
-		} else {
-			scalingService = h.scalingService
-		}
+		ctx := h.services.WithCredentials(request.Context(), username, token)
 
 		// Get queue status
-		queueStatus, err := scalingService.GetQueueStatus(ctx, queueURL)
+		queueStatus, err := h.services.GetScalingService().GetQueueStatus(ctx, queueURL)
 		if err != nil {
 			writeJSONError(response, http.StatusInternalServerError, "Failed to get queue status: "+err.Error())
 			return
@@ -252,7 +320,7 @@ func (h *JenkinsHandlers) HandleJenkinsArtifacts() http.HandlerFunc {
 		}
 
 		// Check for credentials
-		hasEnvCredentials := h.client.IsConfigured()
+		hasEnvCredentials := h.services.GetDeliveryClient().IsConfigured()
 		hasRequestCredentials := req.Username != "" && req.Token != ""
 
 		if !hasEnvCredentials && !hasRequestCredentials {
@@ -264,28 +332,17 @@ func (h *JenkinsHandlers) HandleJenkinsArtifacts() http.HandlerFunc {
 			return
 		}
 
-		// Create context for the request
-		ctx := request.Context()
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
 
-		// Use appropriate client for the request
-		var artifactsService services.ArtifactsService
-		if hasRequestCredentials {
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: req.Username,
-				Token:    req.Token,
+		// Use the pooled client of the instance that owns the build
+		artifactsService, err := h.services.ArtifactsServiceFor(req.BuildURL)
+		if err != nil {
+			response.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(response).Encode(map[string]interface{}{
+				"success": false,
+				"message": "Failed to get Jenkins client: " + err.Error(),
 			})
-			if err != nil {
-				response.Header().Set("Content-Type", "application/json")
-				_ = json.NewEncoder(response).Encode(map[string]interface{}{
-					"success": false,
-					"message": "Failed to create Jenkins client: " + err.Error(),
-				})
-				return
-			}
-			artifactsService = services.NewArtifactsService(tempClient)
-		} else {
-			artifactsService = h.artifactsService
+			return
 		}
 
 		// Extract artifacts
@@ -309,6 +366,111 @@ func (h *JenkinsHandlers) HandleJenkinsArtifacts() http.HandlerFunc {
 	}
 }
 
+// HandleJenkinsArtifactsDownload downloads matching build artifacts into the
+// local cache and returns where each can be fetched from
+func (h *JenkinsHandlers) HandleJenkinsArtifactsDownload() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req struct {
+			types.ArtifactDownloadRequest
+			Username string `json:"username"`
+			Token    string `json:"token"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+			writeJSONError(response, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
+		artifactsService, err := h.services.ArtifactsServiceFor(req.BuildURL)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to get Jenkins client: "+err.Error())
+			return
+		}
+
+		download, err := artifactsService.DownloadArtifacts(ctx, &req.ArtifactDownloadRequest)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to download artifacts: "+err.Error())
+			return
+		}
+
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success":  len(download.Errors) == 0,
+			"response": download,
+		})
+	}
+}
+
+// HandleJenkinsArtifactsDiff compares the artifacts, parameters and commits of two builds
+func (h *JenkinsHandlers) HandleJenkinsArtifactsDiff() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req struct {
+			types.BuildDiffRequest
+			Username string `json:"username"`
+			Token    string `json:"token"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+			writeJSONError(response, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
+		artifactsService, err := h.services.ArtifactsServiceFor(req.TargetBuildURL)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to get Jenkins client: "+err.Error())
+			return
+		}
+
+		diff, err := artifactsService.DiffBuilds(ctx, &req.BuildDiffRequest)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to compare builds: "+err.Error())
+			return
+		}
+
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"diff":    diff,
+		})
+	}
+}
+
+// HandleCachedArtifact serves an artifact from the local cache by content hash
+func (h *JenkinsHandlers) HandleCachedArtifact() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		cache := h.services.GetArtifactCache()
+		if cache == nil {
+			writeJSONError(response, http.StatusServiceUnavailable, "Artifact cache is not available")
+			return
+		}
+
+		hash := strings.TrimPrefix(request.URL.Path, "/api/artifacts/")
+		file, artifact, err := cache.Open(hash)
+		if err != nil {
+			writeJSONError(response, http.StatusNotFound, "Artifact not found in cache")
+			return
+		}
+		defer file.Close()
+
+		response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", artifact.Name))
+		response.Header().Set("ETag", `"`+artifact.Hash+`"`)
+		http.ServeContent(response, request, artifact.Name, artifact.CachedAt, file)
+	}
+}
+
 // HandleJenkinsBuildInfo handles build information requests
 func (h *JenkinsHandlers) HandleJenkinsBuildInfo() http.HandlerFunc {
 	return func(response http.ResponseWriter, request *http.Request) {
@@ -341,22 +503,12 @@ func (h *JenkinsHandlers) HandleJenkinsBuildInfo() http.HandlerFunc {
 		username := strings.TrimSpace(req.Username)
 		token := strings.TrimSpace(req.Token)
 
-		ctx := request.Context()
+		ctx := h.services.WithCredentials(request.Context(), username, token)
 
-		var jenkinsClient *jenkins.Client
-		if username != "" && token != "" {
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: username,
-				Token:    token,
-			})
-			if err != nil {
-				writeJSONError(response, http.StatusInternalServerError, "Failed to create Jenkins client: "+err.Error())
-				return
-			}
-			jenkinsClient = tempClient
-		} else {
-			jenkinsClient = h.client
+		jenkinsClient, err := h.services.GetPool().ForURL(buildURL)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to get Jenkins client: "+err.Error())
+			return
 		}
 
 		if !regexp.MustCompile(`/\d+/?$`).MatchString(buildURL) {
@@ -453,7 +605,11 @@ func (h *JenkinsHandlers) HandleJenkinsBuildInfo() http.HandlerFunc {
 			return
 		}
 
-		artifactsService := services.NewArtifactsService(jenkinsClient)
+		artifactsService, err := h.services.ArtifactsServiceFor(buildURL)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to get Jenkins client: "+err.Error())
+			return
+		}
 		buildInfo, err := artifactsService.GetBuildInfo(ctx, buildURL)
 		if err != nil {
 			writeJSONError(response, http.StatusInternalServerError, "Failed to get build info: "+err.Error())
@@ -464,6 +620,369 @@ func (h *JenkinsHandlers) HandleJenkinsBuildInfo() http.HandlerFunc {
 	}
 }
 
+// jobHistoryRequest is the request body shared by the history and trend endpoints
+type jobHistoryRequest struct {
+	types.JobHistoryQuery
+	WindowDays int    `json:"window_days"` // shorthand for since = now - window_days
+	Username   string `json:"username"`
+	Token      string `json:"token"`
+}
+
+// decodeJobHistoryRequest decodes a history request and applies window_days
+func decodeJobHistoryRequest(request *http.Request) (*jobHistoryRequest, error) {
+	var req jobHistoryRequest
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	if req.WindowDays > 0 && req.Since == nil {
+		since := time.Now().AddDate(0, 0, -req.WindowDays)
+		req.Since = &since
+	}
+	return &req, nil
+}
+
+// jenkinsErrorStatus maps a Jenkins service error to an HTTP status code
+func jenkinsErrorStatus(err error) int {
+	switch {
+	case jenkinserrors.IsInvalidParametersError(err), jenkinserrors.IsInvalidURLError(err):
+		return http.StatusBadRequest
+	case jenkinserrors.IsJobNotFoundError(err):
+		return http.StatusNotFound
+	case jenkinserrors.IsCircuitOpenError(err):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// HandleJenkinsHistory handles paginated, filtered build history queries
+func (h *JenkinsHandlers) HandleJenkinsHistory() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		req, err := decodeJobHistoryRequest(request)
+		if err != nil {
+			writeJSONError(response, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
+
+		page, err := h.services.GetJobService().QueryJobHistory(ctx, &req.JobHistoryQuery)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to get build history: "+err.Error())
+			return
+		}
+
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"history": page,
+		})
+	}
+}
+
+// HandleJenkinsTrend handles success rate and duration aggregates over a time window
+func (h *JenkinsHandlers) HandleJenkinsTrend() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		req, err := decodeJobHistoryRequest(request)
+		if err != nil {
+			writeJSONError(response, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
+
+		trend, err := h.services.GetJobService().GetJobTrend(ctx, &req.JobHistoryQuery)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to get build trend: "+err.Error())
+			return
+		}
+
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"trend":   trend,
+		})
+	}
+}
+
+// HandleJenkinsDiagnose classifies a build failure from its console log
+func (h *JenkinsHandlers) HandleJenkinsDiagnose() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req struct {
+			types.DiagnosisRequest
+			Username string `json:"username"`
+			Token    string `json:"token"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+			writeJSONError(response, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		buildURL := strings.TrimSpace(req.BuildURL)
+		if buildURL == "" {
+			writeJSONError(response, http.StatusBadRequest, "build_url is required")
+			return
+		}
+
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
+
+		diagnosticsService, err := h.services.DiagnosticsServiceFor(buildURL)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to get Jenkins client: "+err.Error())
+			return
+		}
+
+		diagnosis, err := diagnosticsService.DiagnoseBuild(ctx, &req.DiagnosisRequest)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to diagnose build: "+err.Error())
+			return
+		}
+
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"diagnosis": diagnosis,
+		})
+	}
+}
+
+// HandleJenkinsConfig reports the installed jobs configuration and its source
+func (h *JenkinsHandlers) HandleJenkinsConfig() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"config":  h.services.GetConfigService().Status(),
+		})
+	}
+}
+
+// HandleJenkinsConfigReload re-reads jobs.json and installs it if valid. Invalid
+// configurations are rejected with one entry per offending field.
+func (h *JenkinsHandlers) HandleJenkinsConfigReload() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		configService := h.services.GetConfigService()
+		if err := configService.ReloadConfig(); err != nil {
+			var validationErr *jenkinsconfig.ValidationError
+			if errors.As(err, &validationErr) {
+				writeJSON(response, http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"message": "Jenkins jobs configuration is invalid",
+					"errors":  validationErr.Errors,
+				})
+				return
+			}
+			writeJSONError(response, http.StatusBadRequest, "Failed to reload Jenkins configuration: "+err.Error())
+			return
+		}
+
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"config":  configService.Status(),
+		})
+	}
+}
+
+// HandleScalingClusters lists the clusters the scaling job can scale, with
+// region, account and status; ?refresh=1 bypasses the inventory cache
+func (h *JenkinsHandlers) HandleScalingClusters() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		listing, err := h.services.GetScalingService().GetSupportedClusters(request.Context(), request.URL.Query().Get("refresh") == "1")
+		if err != nil {
+			writeJSONError(response, http.StatusBadGateway, "Failed to list clusters: "+err.Error())
+			return
+		}
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success":    true,
+			"clusters":   listing.Clusters,
+			"fetched_at": listing.FetchedAt,
+			"errors":     listing.Errors,
+		})
+	}
+}
+
+// HandleScalingPolicy checks a scaling request against the scaling policy
+// without triggering anything, so the UI can ask for a reason or confirmation
+// phrase up front
+func (h *JenkinsHandlers) HandleScalingPolicy() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req types.ScaleRequest
+		if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+			writeJSONError(response, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		req.Source = types.ScaleSourceManual
+
+		scaling := h.services.GetScalingService()
+		violations := scaling.CheckPolicy(&req)
+		if violations == nil {
+			violations = []types.ScalePolicyViolation{}
+		}
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success":             true,
+			"allowed":             len(violations) == 0,
+			"violations":          violations,
+			"protected":           scaling.IsProtected(req.ClusterName),
+			"confirmation_phrase": services.ConfirmationPhrase(&req),
+		})
+	}
+}
+
+// HandleScalingAudit returns recent scaling attempts, newest first;
+// ?cluster= narrows to one cluster and ?limit= caps the count (default 100)
+func (h *JenkinsHandlers) HandleScalingAudit() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		query := request.URL.Query()
+		limit := 100
+		if raw := query.Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed <= 0 {
+				writeJSONError(response, http.StatusBadRequest, "limit must be a positive number")
+				return
+			}
+			limit = parsed
+		}
+
+		entries, err := h.services.GetScalingService().GetScalingHistory(strings.TrimSpace(query.Get("cluster")), limit)
+		if err != nil {
+			writeJSONError(response, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(response, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"entries": entries,
+		})
+	}
+}
+
+// scalingSchedulesPath is the collection route; single schedules live below it
+const scalingSchedulesPath = "/api/scaling/schedules"
+
+// HandleScalingSchedules lists (GET) and creates (POST) scaling schedules at
+// /api/scaling/schedules, and reads (GET), replaces (PUT) and deletes (DELETE)
+// one schedule at /api/scaling/schedules/{id}
+func (h *JenkinsHandlers) HandleScalingSchedules() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		scheduler := h.services.GetScalingScheduler()
+		if scheduler == nil {
+			writeJSONError(response, http.StatusServiceUnavailable, "Scaling schedules are unavailable: the schedules file could not be loaded")
+			return
+		}
+
+		id := strings.Trim(strings.TrimPrefix(request.URL.Path, scalingSchedulesPath), "/")
+		if id == "" {
+			switch request.Method {
+			case http.MethodGet:
+				writeJSON(response, http.StatusOK, map[string]interface{}{
+					"success":   true,
+					"schedules": scheduler.ListSchedules(),
+				})
+			case http.MethodPost:
+				var schedule types.ScaleSchedule
+				if err := json.NewDecoder(request.Body).Decode(&schedule); err != nil {
+					writeJSONError(response, http.StatusBadRequest, "Invalid request payload")
+					return
+				}
+				schedule.ID = ""
+				writeSavedSchedule(response, scheduler, &schedule, http.StatusCreated)
+			default:
+				writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			}
+			return
+		}
+
+		switch request.Method {
+		case http.MethodGet:
+			schedule, err := scheduler.GetSchedule(id)
+			if err != nil {
+				writeJSONError(response, jenkinsErrorStatus(err), err.Error())
+				return
+			}
+			writeJSON(response, http.StatusOK, map[string]interface{}{
+				"success":  true,
+				"schedule": schedule,
+			})
+		case http.MethodPut:
+			var schedule types.ScaleSchedule
+			if err := json.NewDecoder(request.Body).Decode(&schedule); err != nil {
+				writeJSONError(response, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+			schedule.ID = id
+			writeSavedSchedule(response, scheduler, &schedule, http.StatusOK)
+		case http.MethodDelete:
+			if err := scheduler.DeleteSchedule(id); err != nil {
+				writeJSONError(response, jenkinsErrorStatus(err), err.Error())
+				return
+			}
+			writeJSON(response, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": fmt.Sprintf("Schedule %s deleted", id),
+			})
+		default:
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	}
+}
+
+// writeSavedSchedule saves a schedule and writes the stored result
+func writeSavedSchedule(response http.ResponseWriter, scheduler services.ScalingScheduler, schedule *types.ScaleSchedule, status int) {
+	saved, err := scheduler.SaveSchedule(schedule)
+	var policyErr *services.ScalePolicyError
+	if errors.As(err, &policyErr) {
+		writeJSON(response, http.StatusForbidden, map[string]interface{}{
+			"success":    false,
+			"message":    policyErr.Error(),
+			"violations": policyErr.Violations,
+		})
+		return
+	}
+	if err != nil {
+		writeJSONError(response, jenkinsErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(response, status, map[string]interface{}{
+		"success":  true,
+		"schedule": saved,
+	})
+}
+
 // HandleRNCreate handles RN creation requests
 func (h *JenkinsHandlers) HandleRNCreate() http.HandlerFunc {
 	return func(response http.ResponseWriter, request *http.Request) {
@@ -485,7 +1004,7 @@ func (h *JenkinsHandlers) HandleRNCreate() http.HandlerFunc {
 		}
 
 		// Check if we have credentials
-		hasEnvCredentials := h.client.IsConfigured()
+		hasEnvCredentials := h.services.GetDeliveryClient().IsConfigured()
 		hasRequestCredentials := req.Username != "" && req.Token != ""
 
 		if !hasEnvCredentials && !hasRequestCredentials {
@@ -497,30 +1016,8 @@ func (h *JenkinsHandlers) HandleRNCreate() http.HandlerFunc {
 			return
 		}
 
-		// Create context for the request
-		ctx := request.Context()
-
-		// Use appropriate client for the request
-		var rnCreationService services.RNCreationService
-		if hasRequestCredentials {
-			// Create temporary client with request credentials
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: req.Username,
-				Token:    req.Token,
-			})
-			if err != nil {
-				response.Header().Set("Content-Type", "application/json")
-				_ = json.NewEncoder(response).Encode(map[string]interface{}{
-					"success": false,
-					"message": "Failed to create Jenkins client: " + err.Error(),
-				})
-				return
-			}
-			rnCreationService = services.NewRNCreationService(h.configuration, tempClient)
-		} else {
-			rnCreationService = h.rnCreationService
-		}
+		ctx := h.services.WithCredentials(request.Context(), req.Username, req.Token)
+		rnCreationService := h.services.GetRNCreationService()
 
 		// Auto-populate request from customization job
 		if err := rnCreationService.PopulateRequestFromCustomizationJob(ctx, &req.RNCreationRequest); err != nil {
@@ -605,25 +1102,8 @@ func (h *JenkinsHandlers) HandleRNCustomizationJob() http.HandlerFunc {
 		username := strings.TrimSpace(req.Username)
 		token := strings.TrimSpace(req.Token)
 
-		// Create context for the request
-		ctx := request.Context()
-
-		// Use appropriate client for the request
-		var rnCreationService services.RNCreationService
-		if username != "" && token != "" {
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: username,
-				Token:    token,
-			})
-			if err != nil {
-				writeJSONError(response, http.StatusInternalServerError, "Failed to create Jenkins client: "+err.Error())
-				return
-			}
-			rnCreationService = services.NewRNCreationService(h.configuration, tempClient)
-		} else {
-			rnCreationService = h.rnCreationService
-		}
+		ctx := h.services.WithCredentials(request.Context(), username, token)
+		rnCreationService := h.services.GetRNCreationService()
 
 		// Get latest customization job
 		job, err := rnCreationService.GetLatestCustomizationJob(ctx, branch)
@@ -669,22 +1149,8 @@ func (h *JenkinsHandlers) HandleRNBuildParameters() http.HandlerFunc {
 		username := strings.TrimSpace(req.Username)
 		token := strings.TrimSpace(req.Token)
 
-		ctx := request.Context()
-		var rnCreationService services.RNCreationService
-		if username != "" && token != "" {
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: username,
-				Token:    token,
-			})
-			if err != nil {
-				writeJSONError(response, http.StatusInternalServerError, "Failed to create Jenkins client: "+err.Error())
-				return
-			}
-			rnCreationService = services.NewRNCreationService(h.configuration, tempClient)
-		} else {
-			rnCreationService = h.rnCreationService
-		}
+		ctx := h.services.WithCredentials(request.Context(), username, token)
+		rnCreationService := h.services.GetRNCreationService()
 
 		parameters, err := rnCreationService.GetBuildParameters(ctx, jobURL)
 		if err != nil {
@@ -735,23 +1201,13 @@ func (h *JenkinsHandlers) HandleRNArtifactURL() http.HandlerFunc {
 		token := strings.TrimSpace(req.Token)
 		branch := strings.TrimSpace(req.Branch)
 
-		ctx := request.Context()
+		ctx := h.services.WithCredentials(request.Context(), username, token)
 
 		// Use existing artifacts service to parse "Deployed Artifacts" section
-		var artifactsService services.ArtifactsService
-		if username != "" && token != "" {
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: username,
-				Token:    token,
-			})
-			if err != nil {
-				writeJSONError(response, http.StatusInternalServerError, "Failed to create Jenkins client: "+err.Error())
-				return
-			}
-			artifactsService = services.NewArtifactsService(tempClient)
-		} else {
-			artifactsService = h.artifactsService
+		artifactsService, err := h.services.ArtifactsServiceFor(jobURL)
+		if err != nil {
+			writeJSONError(response, jenkinsErrorStatus(err), "Failed to get Jenkins client: "+err.Error())
+			return
 		}
 
 		// Extract artifacts using existing service
@@ -773,12 +1229,7 @@ func (h *JenkinsHandlers) HandleRNArtifactURL() http.HandlerFunc {
 		var orchestrationURL string
 		for _, artifact := range artifactsResponse.Artifacts {
 			if strings.Contains(artifact.Name, "att-orchestration") && strings.Contains(artifact.Name, "src.zip") {
-				originalURL := artifact.URL
-				if h.configuration != nil {
-					orchestrationURL = h.configuration.Endpoints.ReplaceWithInternalNexus(originalURL)
-				} else {
-					orchestrationURL = originalURL
-				}
+				orchestrationURL = h.services.GetNexusProxy().Rewrite(artifact.URL)
 				break
 			}
 		}
@@ -787,7 +1238,7 @@ func (h *JenkinsHandlers) HandleRNArtifactURL() http.HandlerFunc {
 			log.Printf("Jenkins artifact not found, trying Nexus direct fetch fallback...")
 			if branch != "" {
 				log.Printf("Calling fetchOrchestrationFromNexus with branch: %s", branch)
-				fallbackURL, err := h.fetchOrchestrationFromNexus(branch)
+				fallbackURL, err := h.fetchOrchestrationFromNexus(ctx, branch)
 				if err == nil && fallbackURL != "" {
 					orchestrationURL = fallbackURL
 					log.Printf("Successfully fetched orchestration URL from Nexus: %s", orchestrationURL)
@@ -850,7 +1301,7 @@ func (h *JenkinsHandlers) HandleRNOniImage() http.HandlerFunc {
 		}
 
 		ctx := request.Context()
-		rnCreationService := h.rnCreationService
+		rnCreationService := h.services.GetRNCreationService()
 
 		oniImage, err := rnCreationService.GetOniImageFromBitbucket(ctx, branch, "customization", username, token)
 		if err != nil {
@@ -906,25 +1357,8 @@ func (h *JenkinsHandlers) HandleRNTableData() http.HandlerFunc {
 			return
 		}
 
-		ctx := request.Context()
-		var rnCreationService services.RNCreationService
-		if username != "" && token != "" {
-			tempClient, err := jenkins.NewClientWithConfig(jenkins.ClientConfig{
-				URL:      h.client.GetBaseURL(),
-				Username: username,
-				Token:    token,
-			})
-			if err != nil {
-				writeJSON(response, http.StatusOK, map[string]interface{}{
-					"success": false,
-					"message": "Failed to create Jenkins client: " + err.Error(),
-				})
-				return
-			}
-			rnCreationService = services.NewRNCreationService(h.configuration, tempClient)
-		} else {
-			rnCreationService = h.rnCreationService
-		}
+		ctx := h.services.WithCredentials(request.Context(), username, token)
+		rnCreationService := h.services.GetRNCreationService()
 
 		tableRequest := &types.RNTableRequest{
 			CustomizationJobURL: customizationJobURL,
@@ -951,7 +1385,7 @@ func (h *JenkinsHandlers) HandleRNTableData() http.HandlerFunc {
 }
 
 // fetchOrchestrationFromNexus fetches orchestration artifact URL directly from Nexus
-func (h *JenkinsHandlers) fetchOrchestrationFromNexus(branch string) (string, error) {
+func (h *JenkinsHandlers) fetchOrchestrationFromNexus(ctx context.Context, branch string) (string, error) {
 	log.Printf("Starting Nexus fetch for branch: %s", branch)
 
 	normalizedBranch := branch
@@ -965,112 +1399,82 @@ func (h *JenkinsHandlers) fetchOrchestrationFromNexus(branch string) (string, er
 		log.Printf("Using branch as-is: %s", normalizedBranch)
 	}
 
-	nexusURL := h.configuration.Endpoints.NexusSearchURL
-	log.Printf("Querying Nexus URL: %s", nexusURL)
-
-	client := h.httpClient(10 * time.Second)
-
-	log.Printf("Making HTTP request to Nexus...")
-	resp, err := client.Get(nexusURL)
-	if err != nil {
-		log.Printf("Nexus HTTP request failed: %v", err)
-		return "", fmt.Errorf("failed to query Nexus API: %v", err)
-	}
-	defer resp.Body.Close()
-
-	log.Printf("Nexus API response status: %d", resp.StatusCode)
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Nexus API returned non-200 status: %d", resp.StatusCode)
-		return "", fmt.Errorf("Nexus API returned status %d", resp.StatusCode)
+	const repository = "att.maven.snapshot"
+	nexusClient := h.services.GetNexusClient()
+	coordinates := nexus.Coordinates{
+		Group:      "com.amdocs.oss.att.customization",
+		Artifact:   "att-orchestration",
+		Classifier: "src",
+		Extension:  "zip",
 	}
+	log.Printf("Reading %s:%s versions from Nexus %s", coordinates.Group, coordinates.Artifact, nexusClient.BaseURL())
 
-	var nexusResp struct {
-		Items []struct {
-			Repository string `json:"repository"`
-			Group      string `json:"group"`
-			Name       string `json:"name"`
-			Version    string `json:"version"`
-			Assets     []struct {
-				DownloadURL string `json:"downloadUrl"`
-				Path        string `json:"path"`
-			} `json:"assets"`
-		} `json:"items"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&nexusResp); err != nil {
-		log.Printf("Failed to decode Nexus JSON response: %v", err)
-		return "", fmt.Errorf("failed to decode Nexus response: %v", err)
+	versions, _, _, err := nexusClient.Versions(ctx, repository, coordinates.Group, coordinates.Artifact)
+	if err != nil {
+		log.Printf("Nexus version listing failed: %v", err)
+		return "", fmt.Errorf("failed to query Nexus: %v", err)
 	}
 
-	log.Printf("Nexus returned %d items", len(nexusResp.Items))
-
-	var latestSrcZip string
-	var latestTimestamp string
-	versionPattern := fmt.Sprintf("10.4-%s-", normalizedBranch)
-
-	for _, item := range nexusResp.Items {
-		if item.Repository != "att.maven.snapshot" ||
-			item.Group != "com.amdocs.oss.att.customization" ||
-			item.Name != "att-orchestration" ||
-			!strings.Contains(item.Version, versionPattern) {
-			log.Printf("Skipping item: repository=%s, group=%s, name=%s, version=%s",
-				item.Repository, item.Group, item.Name, item.Version)
+	// Every snapshot version of the branch is resolved to its newest build;
+	// the most recently updated one wins
+	var latest *nexus.ResolvedArtifact
+	versionPrefix := fmt.Sprintf("10.4-%s-", normalizedBranch)
+	for _, version := range versions {
+		coordinates.Version = version
+		if !strings.HasPrefix(version, versionPrefix) || !coordinates.IsSnapshot() {
 			continue
 		}
-
-		log.Printf("Processing matching item: repository=%s, version=%s", item.Repository, item.Version)
-		log.Printf("Processing item with %d assets", len(item.Assets))
-		for _, asset := range item.Assets {
-			log.Printf("Checking asset: %s", asset.Path)
-			if strings.Contains(asset.Path, "-src.zip") {
-				log.Printf("Found src.zip asset: %s", asset.Path)
-				parts := strings.Split(asset.Path, "-")
-				if len(parts) >= 6 {
-					for _, part := range parts {
-						if len(part) == 15 && strings.Contains(part, ".") {
-							timestamp := part
-							log.Printf("Found timestamp: %s (current latest: %s)", timestamp, latestTimestamp)
-							if timestamp > latestTimestamp {
-								latestTimestamp = timestamp
-								latestSrcZip = asset.DownloadURL
-								log.Printf("New latest src.zip: %s", latestSrcZip)
-							}
-							break
-						}
-					}
-				}
-			}
+		resolved, err := nexusClient.Resolve(ctx, repository, coordinates)
+		if err != nil {
+			log.Printf("Skipping %s: %v", version, err)
+			continue
+		}
+		log.Printf("Resolved %s to %s (updated %s)", version, resolved.ResolvedVersion, resolved.Updated.Format(time.RFC3339))
+		if latest == nil || resolved.Updated.After(latest.Updated) {
+			latest = resolved
 		}
 	}
 
-	if latestSrcZip == "" {
+	if latest == nil {
 		log.Printf("No src.zip artifacts found for branch %s", normalizedBranch)
 		return "", fmt.Errorf("no src.zip artifacts found for branch %s", normalizedBranch)
 	}
+	latestSrcZip := latest.URL
 
 	log.Printf("Selected latest src.zip: %s", latestSrcZip)
 
-	finalURL := h.configuration.Endpoints.ReplaceWithInternalNexus(latestSrcZip)
+	finalURL := h.services.GetNexusProxy().Rewrite(latestSrcZip)
 
 	log.Printf("Final URL after host swapping: %s", finalURL)
 	return finalURL, nil
 }
 
-func (h *JenkinsHandlers) httpClient(timeout time.Duration) *http.Client {
-	client := &http.Client{Timeout: timeout}
-	if h.configuration != nil && h.configuration.TLS.InsecureSkipVerify {
-		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
-	}
-	return client
-}
-
 // RegisterJenkinsRoutes registers all Jenkins-related routes with a mux
 func (h *JenkinsHandlers) RegisterJenkinsRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/jenkins/scale", h.HandleJenkinsScale())
+	mux.HandleFunc("/api/scaling/clusters", h.HandleScalingClusters())
+	mux.HandleFunc("/api/scaling/policy", h.HandleScalingPolicy())
+	mux.HandleFunc("/api/scaling/audit", h.HandleScalingAudit())
+	mux.HandleFunc("/api/scaling/batch", h.HandleScalingBatch())
+	mux.HandleFunc("/api/scaling/batch/status", h.HandleScalingBatchStatus())
+	mux.HandleFunc("/api/scaling/groups", h.HandleScalingGroups())
+	mux.HandleFunc(scaleVerifyPath, h.HandleScaleVerify())
+	mux.HandleFunc(scaleVerifyPath+"/", h.HandleScaleVerify())
+	mux.HandleFunc(scaleVerifyStreamPath, h.HandleScaleVerifyStream())
+	mux.HandleFunc(scalingSchedulesPath, h.HandleScalingSchedules())
+	mux.HandleFunc(scalingSchedulesPath+"/", h.HandleScalingSchedules())
 	mux.HandleFunc("/api/jenkins/status", h.HandleJenkinsStatus())
 	mux.HandleFunc("/api/jenkins/queue-status", h.HandleJenkinsQueueStatus())
 	mux.HandleFunc("/api/jenkins/artifacts", h.HandleJenkinsArtifacts())
+	mux.HandleFunc("/api/jenkins/artifacts/download", h.HandleJenkinsArtifactsDownload())
+	mux.HandleFunc("/api/jenkins/artifacts/diff", h.HandleJenkinsArtifactsDiff())
+	mux.HandleFunc("/api/artifacts/", h.HandleCachedArtifact())
 	mux.HandleFunc("/api/jenkins/build-info", h.HandleJenkinsBuildInfo())
+	mux.HandleFunc("/api/jenkins/history", h.HandleJenkinsHistory())
+	mux.HandleFunc("/api/jenkins/history/trend", h.HandleJenkinsTrend())
+	mux.HandleFunc("/api/jenkins/diagnose", h.HandleJenkinsDiagnose())
+	mux.HandleFunc("/api/jenkins/config", h.HandleJenkinsConfig())
+	mux.HandleFunc("/api/jenkins/config/reload", h.HandleJenkinsConfigReload())
 	mux.HandleFunc("/api/jenkins/rn-create", h.HandleRNCreate())
 	mux.HandleFunc("/api/jenkins/rn-customization-job", h.HandleRNCustomizationJob())
 	mux.HandleFunc("/api/jenkins/rn-build-parameters", h.HandleRNBuildParameters())
@@ -0,0 +1,180 @@
+package httpapi
+
+import (
+	"net/http"
+	"sort"
+
+	"app/internal/config"
+	"app/internal/kube"
+	"app/internal/security"
+)
+
+// kubeNamespace is a namespace and how many microservices it holds; the count
+// is -1 when the microservices could not be listed across namespaces
+type kubeNamespace struct {
+	kube.Namespace
+	Microservices int `json:"microservices"`
+}
+
+// HandleKubeNamespaces lists the namespaces of the deploy cluster with their
+// microservice counts (GET /api/kube/namespaces). A user who may not list
+// namespaces gets those holding microservices instead.
+func HandleKubeNamespaces(configuration *config.Config, kubeClients *kube.Provider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		client, err := kubeClients.Client()
+		if err != nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "Kubernetes is not configured: "+err.Error())
+			return
+		}
+
+		counts := make(map[string]int)
+		microservices, microservicesErr := client.ListMicroservices(r.Context(), "")
+		for _, microservice := range microservices {
+			counts[microservice.Namespace]++
+		}
+
+		namespaces, err := client.ListNamespaces(r.Context())
+		if err != nil {
+			if !kube.IsForbidden(err) || microservicesErr != nil {
+				writeJSONError(w, http.StatusBadGateway, "Failed to list namespaces: "+err.Error())
+				return
+			}
+			namespaces = nil
+			for name := range counts {
+				namespaces = append(namespaces, kube.Namespace{Name: name, Phase: "Active"})
+			}
+			sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+		}
+
+		result := make([]kubeNamespace, 0, len(namespaces))
+		for _, namespace := range namespaces {
+			count := counts[namespace.Name]
+			if microservicesErr != nil {
+				count = -1
+			}
+			result = append(result, kubeNamespace{Namespace: namespace, Microservices: count})
+		}
+		response := map[string]interface{}{
+			"success":           true,
+			"server":            client.Server(),
+			"default_namespace": configuration.Kube.DefaultNamespace,
+			"namespaces":        result,
+		}
+		if microservicesErr != nil {
+			response["message"] = "Could not count microservices: " + microservicesErr.Error()
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// kubePatchTarget is the init container a deploy would patch
+type kubePatchTarget struct {
+	Index      int    `json:"index"`
+	Container  string `json:"container"`
+	Image      string `json:"image"`
+	DetectedBy string `json:"detected_by"` // "image" or "name"
+}
+
+// kubeMicroservice is a microservice with the init containers an application
+// and a customization deploy would patch, when it has them
+type kubeMicroservice struct {
+	kube.Microservice
+	ApplicationTarget   *kubePatchTarget `json:"application_target,omitempty"`
+	CustomizationTarget *kubePatchTarget `json:"customization_target,omitempty"`
+}
+
+// HandleKubeMicroservices lists the microservices of a namespace with their
+// init containers, current images and patch targets (GET
+// /api/kube/microservices?namespace=dop), so a deploy can name the exact
+// microservice to patch
+func HandleKubeMicroservices(configuration *config.Config, kubeClients *kube.Provider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		namespace := r.URL.Query().Get("namespace")
+		if namespace == "" {
+			namespace = configuration.Kube.DefaultNamespace
+		}
+		if err := security.ValidateKubernetesName(namespace); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		client, err := kubeClients.Client()
+		if err != nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "Kubernetes is not configured: "+err.Error())
+			return
+		}
+
+		microservices, err := client.ListMicroservices(r.Context(), namespace)
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, "Failed to list microservices: "+err.Error())
+			return
+		}
+		result := make([]kubeMicroservice, 0, len(microservices))
+		for i := range microservices {
+			result = append(result, kubeMicroservice{
+				Microservice:        microservices[i],
+				ApplicationTarget:   patchTarget(&microservices[i], kube.ApplicationContainerPattern),
+				CustomizationTarget: patchTarget(&microservices[i], kube.CustomizationContainerPattern),
+			})
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"success":       true,
+			"namespace":     namespace,
+			"microservices": result,
+		})
+	}
+}
+
+// patchTarget finds the init container matching a deploy script pattern
+func patchTarget(microservice *kube.Microservice, pattern string) *kubePatchTarget {
+	index, detectedBy, err := microservice.FindInitContainer(pattern)
+	if err != nil || index < 0 {
+		return nil
+	}
+	container := microservice.InitContainers[index]
+	return &kubePatchTarget{Index: index, Container: container.Name, Image: container.Image, DetectedBy: detectedBy}
+}
+
+// HandleKubeHelmReleases lists the latest revision of the helm releases of the
+// deploy cluster, read from helm's release secrets (GET
+// /api/kube/helm-releases?namespace=dop); every namespace when none is given
+func HandleKubeHelmReleases(kubeClients *kube.Provider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		namespace := r.URL.Query().Get("namespace")
+		if namespace != "" {
+			if err := security.ValidateKubernetesName(namespace); err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		client, err := kubeClients.Client()
+		if err != nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "Kubernetes is not configured: "+err.Error())
+			return
+		}
+
+		releases, err := client.ListHelmReleases(r.Context(), namespace)
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, "Failed to list helm releases: "+err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"namespace": namespace,
+			"releases":  releases,
+		})
+	}
+}
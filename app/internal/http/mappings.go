@@ -0,0 +1,101 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"app/internal/config"
+	"app/internal/deploymap"
+	"app/internal/security"
+)
+
+// mappingRequest confirms the microservice of a repository's service
+type mappingRequest struct {
+	FolderPath   string `json:"folderPath"`
+	Namespace    string `json:"namespace"`
+	Service      string `json:"service"`
+	Microservice string `json:"microservice"`
+}
+
+// HandleDeployMappings lists (GET ?folderPath=&namespace=), confirms (POST)
+// and forgets (DELETE ?folderPath=&namespace=&service=) the microservices the
+// user chose for a repository's services at /api/deploy/mappings. Confirmed
+// mappings are used by later deploys of the repository to the namespace.
+func HandleDeployMappings(configuration *config.Config, store *deploymap.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var mapping mappingRequest
+		switch r.Method {
+		case http.MethodGet, http.MethodDelete:
+			query := r.URL.Query()
+			mapping = mappingRequest{FolderPath: query.Get("folderPath"), Namespace: query.Get("namespace"), Service: query.Get("service")}
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&mapping); err != nil {
+				writeJSONError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+		default:
+			writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		if err := security.ValidateFolderPath(mapping.FolderPath); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "Invalid folder path: "+err.Error())
+			return
+		}
+		folderPath := security.SanitizePath(mapping.FolderPath)
+		if mapping.Namespace == "" {
+			mapping.Namespace = configuration.Kube.DefaultNamespace
+		}
+		if err := security.ValidateDeployTarget(mapping.Namespace, nil); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			mappings := store.List(folderPath, mapping.Namespace)
+			if mappings == nil {
+				mappings = []deploymap.Mapping{}
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"success":   true,
+				"namespace": mapping.Namespace,
+				"mappings":  mappings,
+			})
+		case http.MethodPost:
+			if err := security.ValidateDeployTarget(mapping.Namespace, map[string]string{mapping.Service: mapping.Microservice}); err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			saved, err := store.Confirm(folderPath, mapping.Namespace, mapping.Service, mapping.Microservice)
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"mapping": saved,
+				"message": fmt.Sprintf("%s will be deployed to %s in %s", mapping.Service, mapping.Microservice, mapping.Namespace),
+			})
+		case http.MethodDelete:
+			if mapping.Service == "" {
+				writeJSONError(w, http.StatusBadRequest, "service is required")
+				return
+			}
+			forgotten, err := store.Forget(folderPath, mapping.Namespace, mapping.Service)
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if !forgotten {
+				writeJSONError(w, http.StatusNotFound, fmt.Sprintf("No microservice is remembered for %s", mapping.Service))
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": fmt.Sprintf("Forgot the microservice of %s", mapping.Service),
+			})
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"app/internal/eks"
+	"app/internal/executor"
+)
+
+const (
+	scaleVerifyPath       = "/api/scaling/verify"
+	scaleVerifyStreamPath = "/api/scaling/verify/stream/"
+
+	// scaleVerifyRetention is how long a finished verification can still be replayed
+	scaleVerifyRetention = 10 * time.Minute
+)
+
+// scaleVerification is one running or recently finished post-scale check. Its
+// timeline is kept so a stream opened late, or reopened, replays it in full.
+type scaleVerification struct {
+	ID        string
+	Cluster   string
+	ScaleType string
+	cancel    context.CancelFunc
+
+	mu       sync.Mutex
+	events   []eks.VerifyEvent
+	finished time.Time
+	changed  chan struct{} // closed and replaced on every new event
+}
+
+func (v *scaleVerification) add(event eks.VerifyEvent) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.events = append(v.events, event)
+	close(v.changed)
+	v.changed = make(chan struct{})
+}
+
+func (v *scaleVerification) finish() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.finished = time.Now()
+	close(v.changed)
+	v.changed = make(chan struct{})
+}
+
+// since returns the events after the first n, whether the verification is
+// over, and a channel closed on the next change
+func (v *scaleVerification) since(n int) ([]eks.VerifyEvent, bool, <-chan struct{}) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var events []eks.VerifyEvent
+	if n < len(v.events) {
+		events = append(events, v.events[n:]...)
+	}
+	return events, !v.finished.IsZero(), v.changed
+}
+
+// scaleVerifications holds the running and recently finished verifications by ID
+type scaleVerifications struct {
+	mu   sync.Mutex
+	byID map[string]*scaleVerification
+}
+
+func newScaleVerifications() *scaleVerifications {
+	return &scaleVerifications{byID: make(map[string]*scaleVerification)}
+}
+
+// get returns a verification by ID
+func (s *scaleVerifications) get(id string) (*scaleVerification, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	verification, ok := s.byID[id]
+	return verification, ok
+}
+
+// HandleScaleVerify starts verifying that a cluster finished scaling (POST
+// /api/scaling/verify) or cancels a verification (DELETE
+// /api/scaling/verify/{id}). A verification of the same cluster and scale type
+// that is still running is reused.
+func (h *JenkinsHandlers) HandleScaleVerify() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		id := strings.Trim(strings.TrimPrefix(request.URL.Path, scaleVerifyPath), "/")
+		if request.Method == http.MethodDelete && id != "" {
+			verification, ok := h.verifications.get(id)
+			if !ok {
+				writeJSONError(response, http.StatusNotFound, "Verification not found")
+				return
+			}
+			verification.cancel()
+			writeJSON(response, http.StatusOK, map[string]interface{}{"success": true, "session_id": id})
+			return
+		}
+		if request.Method != http.MethodPost || id != "" {
+			writeJSONError(response, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req struct {
+			ClusterName    string `json:"cluster_name"`
+			ScaleType      string `json:"scale_type"`
+			Namespace      string `json:"namespace"`
+			TimeoutMinutes int    `json:"timeout_minutes"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+			writeJSONError(response, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		req.ClusterName = strings.TrimSpace(req.ClusterName)
+		if req.ClusterName == "" {
+			writeJSONError(response, http.StatusBadRequest, "cluster_name is required")
+			return
+		}
+		if req.ScaleType == "" {
+			req.ScaleType = "up"
+		}
+		if req.ScaleType != "up" && req.ScaleType != "down" {
+			writeJSONError(response, http.StatusBadRequest, "scale_type must be 'up' or 'down'")
+			return
+		}
+
+		h.verifications.mu.Lock()
+		for key, existing := range h.verifications.byID {
+			existing.mu.Lock()
+			finished := existing.finished
+			existing.mu.Unlock()
+			if !finished.IsZero() {
+				if time.Since(finished) > scaleVerifyRetention {
+					delete(h.verifications.byID, key)
+				}
+				continue
+			}
+			if existing.Cluster == req.ClusterName && existing.ScaleType == req.ScaleType {
+				h.verifications.mu.Unlock()
+				writeJSON(response, http.StatusOK, map[string]interface{}{"success": true, "session_id": existing.ID})
+				return
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		verification := &scaleVerification{
+			ID:        fmt.Sprintf("verify_%d", time.Now().UnixNano()),
+			Cluster:   req.ClusterName,
+			ScaleType: req.ScaleType,
+			cancel:    cancel,
+			changed:   make(chan struct{}),
+		}
+		h.verifications.byID[verification.ID] = verification
+		h.verifications.mu.Unlock()
+
+		options := eks.VerifyOptions{
+			ScaleType: req.ScaleType,
+			Namespace: strings.TrimSpace(req.Namespace),
+			Timeout:   time.Duration(req.TimeoutMinutes) * time.Minute,
+		}
+		h.services.Go(func(shutdown context.Context) {
+			// Stop on cancellation through the API or on shutdown, whichever comes first
+			defer context.AfterFunc(shutdown, cancel)()
+			defer cancel()
+			defer verification.finish()
+
+			options.Region = h.clusterRegion(ctx, req.ClusterName)
+			err := eks.Verify(ctx, executor.RunScript, req.ClusterName, options, verification.add)
+			if err != nil && ctx.Err() != nil {
+				verification.add(eks.VerifyEvent{Kind: eks.VerifyError, Time: time.Now(), Message: "Verification cancelled"})
+			}
+		})
+
+		writeJSON(response, http.StatusAccepted, map[string]interface{}{"success": true, "session_id": verification.ID})
+	}
+}
+
+// HandleScaleVerifyStream streams a verification timeline as server-sent
+// events, replaying what happened before the stream was opened
+func (h *JenkinsHandlers) HandleScaleVerifyStream() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		id := strings.Trim(strings.TrimPrefix(request.URL.Path, scaleVerifyStreamPath), "/")
+		verification, ok := h.verifications.get(id)
+		if !ok {
+			http.Error(response, "Verification not found", http.StatusNotFound)
+			return
+		}
+
+		response.Header().Set("Content-Type", "text/event-stream")
+		response.Header().Set("Cache-Control", "no-cache")
+		response.Header().Set("Connection", "keep-alive")
+		flush := func() {
+			if flusher, ok := response.(http.Flusher); ok {
+				flusher.Flush()
+			}
+		}
+
+		sent := 0
+		for {
+			events, finished, changed := verification.since(sent)
+			for _, event := range events {
+				data, _ := json.Marshal(event)
+				fmt.Fprintf(response, "data: %s\n\n", data)
+			}
+			sent += len(events)
+			if finished {
+				fmt.Fprintf(response, "data: %s\n\n", `{"kind":"end"}`)
+				flush()
+				return
+			}
+			flush()
+
+			select {
+			case <-changed:
+			case <-request.Context().Done():
+				return
+			case <-time.After(30 * time.Second):
+				fmt.Fprintf(response, "data: %s\n\n", `{"kind":"keepalive"}`)
+				flush()
+			}
+		}
+	}
+}
+
+// clusterRegion looks a cluster's region up in the inventory; empty uses the
+// AWS CLI's configured region
+func (h *JenkinsHandlers) clusterRegion(ctx context.Context, clusterName string) string {
+	inventory := h.services.GetClusterInventory()
+	if inventory == nil {
+		return ""
+	}
+	listing, err := inventory.List(ctx, false)
+	if err != nil {
+		return ""
+	}
+	for _, cluster := range listing.Clusters {
+		if cluster.Name == clusterName {
+			return cluster.Region
+		}
+	}
+	return ""
+}
@@ -11,6 +11,7 @@ import (
 	"app/internal/config"
 	"app/internal/executor"
 	"app/internal/progress"
+	"app/internal/security"
 )
 
 // Session management for deployments
@@ -50,6 +51,10 @@ func HandleDeployStart(configuration *config.Config, runner *executor.Runner) ht
 			http.Error(w, "Folder path is required", http.StatusBadRequest)
 			return
 		}
+		if err := security.ValidateDeployTarget(req.Namespace, req.Microservices); err != nil {
+			http.Error(w, "Invalid deploy target: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 
 		// Create new session
 		sessionID := generateSessionID()
@@ -78,7 +83,7 @@ func HandleDeployStart(configuration *config.Config, runner *executor.Runner) ht
 				close(session.Writer)
 			}()
 
-			r.RunOCDScriptWithSSE(ctx, req.FolderPath, session.Writer)
+			r.RunOCDScriptWithSSE(ctx, req, session.Writer)
 		}(runner)
 
 		// Return session ID
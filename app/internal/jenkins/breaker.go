@@ -0,0 +1,184 @@
+package jenkins
+
+import (
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenDuration     = 30 * time.Second
+)
+
+// BreakerState is a snapshot of the circuit breaker for one Jenkins host
+type BreakerState struct {
+	Host                string     `json:"host"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastFailure         *time.Time `json:"last_failure,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"` // when an open breaker lets a trial request through
+}
+
+// circuitBreaker stops requests to a host after repeated failures so callers
+// fail fast instead of waiting on timeouts while Jenkins is down. After the
+// open period one trial request is let through; its outcome closes or reopens
+// the breaker.
+type circuitBreaker struct {
+	mu               sync.Mutex
+	host             string
+	failureThreshold int
+	openDuration     time.Duration
+	state            string
+	failures         int
+	openedAt         time.Time
+	lastFailure      time.Time
+	lastError        string
+	trialInFlight    bool
+}
+
+// breakers holds one circuit breaker per Jenkins host, shared by every client
+// talking to that host
+var breakers = struct {
+	mu     sync.Mutex
+	byHost map[string]*circuitBreaker
+}{byHost: make(map[string]*circuitBreaker)}
+
+// breakerFor returns the circuit breaker for the host serving requestURL
+func breakerFor(requestURL string, failureThreshold int, openDuration time.Duration) *circuitBreaker {
+	host := requestURL
+	if parsed, err := url.Parse(requestURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+
+	breakers.mu.Lock()
+	defer breakers.mu.Unlock()
+
+	if existing, ok := breakers.byHost[host]; ok {
+		return existing
+	}
+
+	if failureThreshold <= 0 {
+		failureThreshold = defaultBreakerFailureThreshold
+	}
+	if openDuration <= 0 {
+		openDuration = defaultBreakerOpenDuration
+	}
+	created := &circuitBreaker{
+		host:             host,
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		state:            BreakerClosed,
+	}
+	breakers.byHost[host] = created
+	return created
+}
+
+// BreakerStates returns the state of every Jenkins host breaker, sorted by host
+func BreakerStates() []BreakerState {
+	breakers.mu.Lock()
+	all := make([]*circuitBreaker, 0, len(breakers.byHost))
+	for _, breaker := range breakers.byHost {
+		all = append(all, breaker)
+	}
+	breakers.mu.Unlock()
+
+	states := make([]BreakerState, 0, len(all))
+	for _, breaker := range all {
+		states = append(states, breaker.snapshot())
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Host < states[j].Host })
+	return states
+}
+
+// allow reports whether a request may be sent, and if not, how long until the
+// breaker lets a trial request through
+func (b *circuitBreaker) allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		remaining := b.openDuration - time.Since(b.openedAt)
+		if remaining > 0 {
+			return false, remaining
+		}
+		b.state = BreakerHalfOpen
+		b.trialInFlight = true
+		return true, 0
+	case BreakerHalfOpen:
+		if b.trialInFlight {
+			return false, b.openDuration
+		}
+		b.trialInFlight = true
+		return true, 0
+	default:
+		return true, 0
+	}
+}
+
+// recordSuccess closes the breaker
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trialInFlight = false
+}
+
+// recordFailure counts a host failure and opens the breaker once the threshold
+// is reached or a half-open trial fails
+func (b *circuitBreaker) recordFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.lastFailure = time.Now()
+	b.lastError = err.Error()
+	b.trialInFlight = false
+
+	if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// release ends a half-open trial without counting it, for requests abandoned by the caller
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+}
+
+// snapshot returns the current breaker state
+func (b *circuitBreaker) snapshot() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := BreakerState{
+		Host:                b.host,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if !b.lastFailure.IsZero() {
+		lastFailure := b.lastFailure
+		state.LastFailure = &lastFailure
+	}
+	if b.state == BreakerOpen {
+		retryAt := b.openedAt.Add(b.openDuration)
+		state.RetryAt = &retryAt
+	}
+	return state
+}
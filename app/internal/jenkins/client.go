@@ -2,11 +2,15 @@ package jenkins
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"app/internal/config"
@@ -15,30 +19,36 @@ import (
 	"app/internal/jenkins/types"
 )
 
+const defaultRetryMaxDelay = 30 * time.Second
+
 // Client represents a Jenkins HTTP client with authentication
 type Client struct {
 	baseURL    string
 	username   string
 	token      string
-	httpClient *http.Client
-	config     *jenkinsconfig.JobsConfig
+	transport  http.RoundTripper
 	options    *types.ClientOptions
+	sessionsMu sync.Mutex
+	sessions   map[string]*session // cookie jar and crumbs per username
 }
 
 // ClientConfig represents configuration for creating a Jenkins client
 type ClientConfig struct {
-	URL      string
-	Username string
-	Token    string
-	Options  *types.ClientOptions
+	URL                string
+	Username           string
+	Token              string
+	InsecureSkipVerify bool
+	Timeout            time.Duration // overrides the jobs.json default when set
+	Options            *types.ClientOptions
 }
 
 // NewClient creates a new Jenkins client from app config
-func NewClient(configuration config.JenkinsConfig) (*Client, error) {
+func NewClient(configuration config.JenkinsConfig, tlsConfiguration config.TLSConfig) (*Client, error) {
 	return NewClientWithConfig(ClientConfig{
-		URL:      configuration.URL,
-		Username: configuration.Username,
-		Token:    configuration.Token,
+		URL:                configuration.URL,
+		Username:           configuration.Username,
+		Token:              configuration.Token,
+		InsecureSkipVerify: tlsConfiguration.InsecureSkipVerify,
 	})
 }
 
@@ -57,22 +67,27 @@ func NewClientWithConfig(configuration ClientConfig) (*Client, error) {
 			Timeout:       time.Duration(jobsConfig.Global.DefaultTimeoutSeconds) * time.Second,
 			RetryAttempts: jobsConfig.Global.RetryAttempts,
 			RetryDelay:    time.Duration(jobsConfig.Global.RetryDelaySeconds) * time.Second,
+			RetryMaxDelay: time.Duration(jobsConfig.Global.RetryMaxDelaySeconds) * time.Second,
 			UserAgent:     jobsConfig.Global.UserAgent,
 		}
+		if configuration.Timeout > 0 {
+			options.Timeout = configuration.Timeout
+		}
 	}
 
-	// Create HTTP client with timeout
-	httpClient := &http.Client{
-		Timeout: options.Timeout,
+	// Share one transport across sessions so connections are reused
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if configuration.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
 
 	client := &Client{
-		baseURL:    strings.TrimSuffix(configuration.URL, "/"),
-		username:   configuration.Username,
-		token:      configuration.Token,
-		httpClient: httpClient,
-		config:     jobsConfig,
-		options:    options,
+		baseURL:   strings.TrimSuffix(configuration.URL, "/"),
+		username:  configuration.Username,
+		token:     configuration.Token,
+		transport: transport,
+		options:   options,
+		sessions:  make(map[string]*session),
 	}
 
 	return client, nil
@@ -80,22 +95,78 @@ func NewClientWithConfig(configuration ClientConfig) (*Client, error) {
 
 // Get performs a GET request without authentication
 func (c *Client) Get(ctx context.Context, url string) ([]byte, error) {
-	return c.doRequest(ctx, "GET", url, nil, false)
+	body, _, err := c.doRequest(ctx, "GET", url, nil, false)
+	return body, err
 }
 
 // Post performs a POST request without authentication
 func (c *Client) Post(ctx context.Context, url string, data map[string]string) ([]byte, error) {
-	return c.doRequest(ctx, "POST", url, data, false)
+	body, _, err := c.doRequest(ctx, "POST", url, data, false)
+	return body, err
 }
 
 // GetWithAuth performs a GET request with authentication
 func (c *Client) GetWithAuth(ctx context.Context, url string) ([]byte, error) {
-	return c.doRequest(ctx, "GET", url, nil, true)
+	body, _, err := c.doRequest(ctx, "GET", url, nil, true)
+	return body, err
+}
+
+// OpenWithAuth starts an authenticated GET and returns the response body
+// unread, for downloads too large to buffer. It bypasses the client timeout,
+// retries and circuit breaker; the caller bounds the transfer through ctx and
+// must close the body.
+func (c *Client) OpenWithAuth(ctx context.Context, url string) (io.ReadCloser, error) {
+	username, token := c.credentials(ctx)
+	if username == "" || token == "" {
+		return nil, errors.NewAuthenticationError("Jenkins credentials not configured", nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, errors.NewNetworkError("failed to create HTTP request", 0, err)
+	}
+	if c.options.UserAgent != "" {
+		req.Header.Set("User-Agent", c.options.UserAgent)
+	}
+	for key, value := range c.options.Headers {
+		req.Header.Set(key, value)
+	}
+	req.SetBasicAuth(username, token)
+
+	resp, err := (&http.Client{Transport: c.transport}).Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.NewTimeoutError("request timeout", err)
+		}
+		return nil, errors.NewNetworkError("HTTP request failed", 0, err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, c.handleHTTPError(resp.StatusCode, string(body), url)
+	}
+	return resp.Body, nil
 }
 
 // PostWithAuth performs a POST request with authentication
 func (c *Client) PostWithAuth(ctx context.Context, url string, data map[string]string) ([]byte, error) {
-	return c.doRequest(ctx, "POST", url, data, true)
+	body, _, err := c.doRequest(ctx, "POST", url, data, true)
+	return body, err
+}
+
+// TriggerWithAuth POSTs to a build trigger URL such as buildWithParameters and
+// returns the queue item URL Jenkins sends in the Location header, or "" when
+// it sends none
+func (c *Client) TriggerWithAuth(ctx context.Context, url string, data map[string]string) (string, error) {
+	_, header, err := c.doRequest(ctx, "POST", url, data, true)
+	if err != nil {
+		return "", err
+	}
+	location := header.Get("Location")
+	if !strings.Contains(location, "/queue/item/") {
+		return "", nil
+	}
+	return location, nil
 }
 
 // IsConfigured returns true if the client has authentication credentials
@@ -108,39 +179,65 @@ func (c *Client) GetBaseURL() string {
 	return c.baseURL
 }
 
-// GetConfig returns the Jenkins jobs configuration
+// GetConfig returns the currently installed Jenkins jobs configuration.
+// Callers should hold on to the result for the duration of one operation so
+// a concurrent reload cannot change it half way through.
 func (c *Client) GetConfig() *jenkinsconfig.JobsConfig {
-	return c.config
+	return jenkinsconfig.Current()
 }
 
 // GetJobURL constructs a full URL for a Jenkins job
 func (c *Client) GetJobURL(jobName string) (string, error) {
-	return c.config.GetJobURL(c.baseURL, jobName)
+	return c.GetConfig().GetJobURL(c.baseURL, jobName)
 }
 
 // ValidateJobParameters validates parameters for a specific job
 func (c *Client) ValidateJobParameters(jobName string, params map[string]string) error {
-	return c.config.ValidateJobParameters(jobName, params)
+	return c.GetConfig().ValidateJobParameters(jobName, params)
 }
 
-// doRequest performs the actual HTTP request with retry logic
-func (c *Client) doRequest(ctx context.Context, method, requestURL string, data map[string]string, useAuth bool) ([]byte, error) {
+// doRequest performs the actual HTTP request with retry logic. Retries back off
+// exponentially with jitter, honour Retry-After, and stop as soon as the host's
+// circuit breaker opens.
+func (c *Client) doRequest(ctx context.Context, method, requestURL string, data map[string]string, useAuth bool) ([]byte, http.Header, error) {
+	global := c.GetConfig().Global
+	breaker := breakerFor(requestURL, global.BreakerFailureThreshold,
+		time.Duration(global.BreakerOpenSeconds)*time.Second)
+
 	var lastErr error
 
 	// Retry logic
 	for attempt := 0; attempt <= c.options.RetryAttempts; attempt++ {
 		if attempt > 0 {
+			delay, ok := c.retryDelay(ctx, attempt, lastErr)
+			if !ok {
+				break
+			}
+
 			// Wait before retrying
 			select {
 			case <-ctx.Done():
-				return nil, errors.NewTimeoutError("request cancelled during retry", ctx.Err())
-			case <-time.After(c.options.RetryDelay):
+				return nil, nil, errors.NewTimeoutError("request cancelled during retry", ctx.Err())
+			case <-time.After(delay):
 			}
 		}
 
-		body, err := c.executeRequest(ctx, method, requestURL, data, useAuth)
+		if allowed, retryIn := breaker.allow(); !allowed {
+			return nil, nil, errors.NewCircuitOpenError(breaker.host, retryIn)
+		}
+
+		body, header, err := c.executeRequest(ctx, method, requestURL, data, useAuth)
+		switch {
+		case err == nil || !isHostFailure(err):
+			breaker.recordSuccess()
+		case ctx.Err() == context.Canceled:
+			breaker.release()
+		default:
+			breaker.recordFailure(err)
+		}
+
 		if err == nil {
-			return body, nil
+			return body, header, nil
 		}
 
 		lastErr = err
@@ -151,11 +248,88 @@ func (c *Client) doRequest(ctx context.Context, method, requestURL string, data
 		}
 	}
 
-	return nil, lastErr
+	return nil, nil, lastErr
 }
 
-// executeRequest performs a single HTTP request
-func (c *Client) executeRequest(ctx context.Context, method, requestURL string, data map[string]string, useAuth bool) ([]byte, error) {
+// retryDelay returns the wait before the given retry attempt: exponential
+// backoff from RetryDelay with jitter, raised to the server's Retry-After.
+// It reports false when the wait would exceed RetryMaxDelay or the context
+// deadline, so callers fail instead of hanging.
+func (c *Client) retryDelay(ctx context.Context, attempt int, lastErr error) (time.Duration, bool) {
+	maxDelay := c.options.RetryMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	delay := c.options.RetryDelay << uint(attempt-1)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	// Equal jitter: half fixed, half random, to spread out concurrent retries
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int63n(half+1))
+	}
+
+	if jenkinsErr, ok := errors.GetJenkinsError(lastErr); ok && jenkinsErr.RetryAfter > 0 {
+		if jenkinsErr.RetryAfter > maxDelay {
+			return 0, false
+		}
+		if jenkinsErr.RetryAfter > delay {
+			delay = jenkinsErr.RetryAfter
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return 0, false
+	}
+	return delay, true
+}
+
+// executeRequest performs a single HTTP request, attaching a CSRF crumb to POSTs
+// and refreshing it once if Jenkins rejects it as stale
+func (c *Client) executeRequest(ctx context.Context, method, requestURL string, data map[string]string, useAuth bool) ([]byte, http.Header, error) {
+	username, token := c.credentials(ctx)
+	if useAuth && (username == "" || token == "") {
+		return nil, nil, errors.NewAuthenticationError("Jenkins credentials not configured", nil)
+	}
+
+	sess := c.sessionFor(username)
+
+	for crumbAttempt := 0; ; crumbAttempt++ {
+		var requestCrumb *crumb
+		if method == "POST" {
+			fetched, err := c.crumbFor(ctx, sess, requestURL, useAuth)
+			if err != nil {
+				return nil, nil, err
+			}
+			requestCrumb = fetched
+		}
+
+		statusCode, header, body, err := c.send(ctx, sess, method, requestURL, data, useAuth, requestCrumb)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if isCrumbError(statusCode, string(body)) && crumbAttempt == 0 {
+			c.invalidateCrumb(sess, requestURL)
+			continue
+		}
+
+		// Check for HTTP errors
+		if statusCode >= 400 {
+			httpErr := c.handleHTTPError(statusCode, string(body), requestURL)
+			if jenkinsErr, ok := errors.GetJenkinsError(httpErr); ok {
+				jenkinsErr.RetryAfter = parseRetryAfter(header.Get("Retry-After"))
+			}
+			return nil, nil, httpErr
+		}
+
+		return body, header, nil
+	}
+}
+
+// send performs one HTTP round trip and returns the status code, headers and body
+func (c *Client) send(ctx context.Context, sess *session, method, requestURL string, data map[string]string, useAuth bool, requestCrumb *crumb) (int, http.Header, []byte, error) {
 	// Prepare request body for POST requests
 	var requestBody io.Reader
 	if method == "POST" && data != nil {
@@ -169,7 +343,7 @@ func (c *Client) executeRequest(ctx context.Context, method, requestURL string,
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, method, requestURL, requestBody)
 	if err != nil {
-		return nil, errors.NewNetworkError("failed to create HTTP request", 0, err)
+		return 0, nil, nil, errors.NewNetworkError("failed to create HTTP request", 0, err)
 	}
 
 	// Set headers
@@ -186,37 +360,34 @@ func (c *Client) executeRequest(ctx context.Context, method, requestURL string,
 		req.Header.Set(key, value)
 	}
 
-	// Set authentication if required and configured
+	if requestCrumb != nil && requestCrumb.field != "" {
+		req.Header.Set(requestCrumb.field, requestCrumb.value)
+	}
+
+	// Set authentication if required
 	if useAuth {
-		if !c.IsConfigured() {
-			return nil, errors.NewAuthenticationError("Jenkins credentials not configured", nil)
-		}
-		req.SetBasicAuth(c.username, c.token)
+		username, token := c.credentials(ctx)
+		req.SetBasicAuth(username, token)
 	}
 
-	// Execute request
-	resp, err := c.httpClient.Do(req)
+	// Execute request (the session jar keeps the cookies the crumb is bound to)
+	resp, err := sess.httpClient.Do(req)
 	if err != nil {
 		// Check if it's a timeout error
 		if ctx.Err() == context.DeadlineExceeded {
-			return nil, errors.NewTimeoutError("request timeout", err)
+			return 0, nil, nil, errors.NewTimeoutError("request timeout", err)
 		}
-		return nil, errors.NewNetworkError("HTTP request failed", 0, err)
+		return 0, nil, nil, errors.NewNetworkError("HTTP request failed", 0, err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, errors.NewNetworkError("failed to read response body", resp.StatusCode, err)
+		return 0, nil, nil, errors.NewNetworkError("failed to read response body", resp.StatusCode, err)
 	}
 
-	// Check for HTTP errors
-	if resp.StatusCode >= 400 {
-		return nil, c.handleHTTPError(resp.StatusCode, string(body), requestURL)
-	}
-
-	return body, nil
+	return resp.StatusCode, resp.Header, body, nil
 }
 
 // handleHTTPError creates appropriate errors based on HTTP status codes
@@ -266,8 +437,8 @@ func (c *Client) isRetryableError(err error) bool {
 		case "timeout", "network_error":
 			// Retry timeout and network errors
 			return true
-		case "authentication_failed", "invalid_parameters", "parsing_failed":
-			// Don't retry authentication, parameter, or parsing errors
+		case "authentication_failed", "invalid_parameters", "parsing_failed", "circuit_open":
+			// Don't retry authentication, parameter, or parsing errors, or hosts known to be down
 			return false
 		default:
 			// For server errors (5xx), retry
@@ -277,9 +448,50 @@ func (c *Client) isRetryableError(err error) bool {
 	return false
 }
 
+// isHostFailure reports whether an error means the Jenkins host itself is
+// unreachable or failing, as opposed to rejecting this particular request
+func isHostFailure(err error) bool {
+	jenkinsErr, ok := errors.GetJenkinsError(err)
+	if !ok {
+		return false
+	}
+	switch jenkinsErr.Type {
+	case "timeout":
+		return true
+	case "network_error":
+		return jenkinsErr.StatusCode == 0 || (jenkinsErr.StatusCode >= 500 && jenkinsErr.StatusCode < 600)
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// Close releases idle connections held by the client's transport
+func (c *Client) Close() {
+	if closer, ok := c.transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
 // GetJobTimeout returns the configured timeout for a specific job
 func (c *Client) GetJobTimeout(jobName string) time.Duration {
-	return c.config.GetJobTimeout(jobName)
+	return c.GetConfig().GetJobTimeout(jobName)
 }
 
 // CreateContextWithTimeout creates a context with job-specific timeout
@@ -288,8 +500,13 @@ func (c *Client) CreateContextWithTimeout(ctx context.Context, jobName string) (
 	return context.WithTimeout(ctx, timeout)
 }
 
-// Health performs a basic health check on the Jenkins instance
+// Health performs a basic health check on the Jenkins instance. Instances whose
+// credentials come from requests are only checked for reachability.
 func (c *Client) Health(ctx context.Context) error {
+	if !c.IsConfigured() {
+		_, err := c.Get(ctx, c.baseURL+"/login")
+		return err
+	}
 	healthURL := c.baseURL + "/api/json"
 	_, err := c.GetWithAuth(ctx, healthURL)
 	return err
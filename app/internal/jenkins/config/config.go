@@ -1,9 +1,16 @@
 package config
 
 import (
+	"bytes"
 	"embed"
 	"encoding/json"
 	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,6 +21,7 @@ var configFS embed.FS
 type JobsConfig struct {
 	Jobs          map[string]JobConfig       `json:"jobs"`
 	Artifacts     ArtifactsConfig            `json:"artifacts"`
+	Diagnostics   DiagnosticsConfig          `json:"diagnostics"`
 	Global        GlobalConfig               `json:"global"`
 	ErrorHandling ErrorHandlingConfig        `json:"error_handling"`
 }
@@ -22,6 +30,7 @@ type JobsConfig struct {
 type JobConfig struct {
 	Name            string                    `json:"name"`
 	Description     string                    `json:"description"`
+	Instance        string                    `json:"instance"`
 	JobPath         string                    `json:"job_path"`
 	Method          string                    `json:"method"`
 	EndpointSuffix  string                    `json:"endpoint_suffix"`
@@ -63,12 +72,33 @@ type RegexPatterns struct {
 	ArtifactItem            string `json:"artifact_item"`
 }
 
+// DiagnosticsConfig represents the console log failure classification catalog
+type DiagnosticsConfig struct {
+	ContextLines     int                `json:"context_lines"`
+	MaxSearchResults int                `json:"max_search_results"`
+	Signatures       []FailureSignature `json:"signatures"`
+}
+
+// FailureSignature maps console log patterns to a failure category. Signatures
+// are checked in order, so earlier entries win when several match.
+type FailureSignature struct {
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	ErrorType   string   `json:"error_type"` // error type from the jenkins/errors taxonomy
+	Patterns    []string `json:"patterns"`
+	Suggestion  string   `json:"suggestion"`
+}
+
 // GlobalConfig represents global Jenkins configuration
 type GlobalConfig struct {
-	DefaultTimeoutSeconds int    `json:"default_timeout_seconds"`
-	RetryAttempts        int    `json:"retry_attempts"`
-	RetryDelaySeconds    int    `json:"retry_delay_seconds"`
-	UserAgent           string `json:"user_agent"`
+	DefaultInstance         string `json:"default_instance"`
+	DefaultTimeoutSeconds   int    `json:"default_timeout_seconds"`
+	RetryAttempts           int    `json:"retry_attempts"`
+	RetryDelaySeconds       int    `json:"retry_delay_seconds"`
+	RetryMaxDelaySeconds    int    `json:"retry_max_delay_seconds"`
+	BreakerFailureThreshold int    `json:"breaker_failure_threshold"`
+	BreakerOpenSeconds      int    `json:"breaker_open_seconds"`
+	UserAgent               string `json:"user_agent"`
 }
 
 // ErrorHandlingConfig represents error handling configuration
@@ -78,31 +108,134 @@ type ErrorHandlingConfig struct {
 	CustomErrorCodes   map[string]string `json:"custom_error_codes"`
 }
 
-var globalConfig *JobsConfig
+// ConfigPathEnv names the environment variable pointing at an external jobs.json.
+// When unset the configuration embedded at build time is used.
+const ConfigPathEnv = "OCD_JENKINS_JOBS_CONFIG"
+
+// EmbeddedSource is the source name of the embedded configuration
+const EmbeddedSource = "embedded"
+
+// snapshot is an installed configuration together with where it came from.
+// Snapshots are never modified after installation, so readers holding one
+// keep a consistent view across a reload.
+type snapshot struct {
+	config   *JobsConfig
+	source   string
+	loadedAt time.Time
+}
+
+var (
+	current atomic.Pointer[snapshot]
+	loadMu  sync.Mutex
+)
+
+// FieldError describes one invalid configuration field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of a configuration
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldError := range e.Errors {
+		messages = append(messages, fieldError.Field+": "+fieldError.Message)
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) add(field, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
 
-// LoadConfig loads the Jenkins jobs configuration from the embedded JSON file
+// LoadConfig returns the current Jenkins jobs configuration, loading it from
+// its source on first use
 func LoadConfig() (*JobsConfig, error) {
-	if globalConfig != nil {
-		return globalConfig, nil
+	if installed := current.Load(); installed != nil {
+		return installed.config, nil
+	}
+
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	if installed := current.Load(); installed != nil {
+		return installed.config, nil
+	}
+
+	config, source, err := ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	Install(config, source)
+	return config, nil
+}
+
+// Current returns the installed configuration, or nil before the first LoadConfig
+func Current() *JobsConfig {
+	if installed := current.Load(); installed != nil {
+		return installed.config
+	}
+	return nil
+}
+
+// Source returns where the installed configuration was loaded from and when
+func Source() (string, time.Time) {
+	if installed := current.Load(); installed != nil {
+		return installed.source, installed.loadedAt
+	}
+	return "", time.Time{}
+}
+
+// Install atomically replaces the installed configuration
+func Install(config *JobsConfig, source string) {
+	current.Store(&snapshot{config: config, source: source, loadedAt: time.Now()})
+}
+
+// ConfigPath returns the external jobs.json path, or "" for the embedded file
+func ConfigPath() string {
+	return strings.TrimSpace(os.Getenv(ConfigPathEnv))
+}
+
+// ReadConfig reads and validates the configuration from its source without installing it
+func ReadConfig() (*JobsConfig, string, error) {
+	path := ConfigPath()
+	if path == "" {
+		data, err := configFS.ReadFile("jobs.json")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read jobs.json: %w", err)
+		}
+		config, err := ParseConfig(data)
+		return config, EmbeddedSource, err
 	}
 
-	data, err := configFS.ReadFile("jobs.json")
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read jobs.json: %w", err)
+		return nil, path, fmt.Errorf("failed to read %s: %w", path, err)
 	}
+	config, err := ParseConfig(data)
+	return config, path, err
+}
+
+// ParseConfig parses and validates a jobs.json document, applying defaults.
+// Unknown fields are rejected so typos surface instead of being ignored.
+func ParseConfig(data []byte) (*JobsConfig, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
 
 	var config JobsConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	if err := decoder.Decode(&config); err != nil {
 		return nil, fmt.Errorf("failed to parse jobs.json: %w", err)
 	}
 
-	// Validate configuration
 	if err := validateConfig(&config); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+		return nil, err
 	}
-
-	globalConfig = &config
-	return globalConfig, nil
+	return &config, nil
 }
 
 // GetJobConfig returns configuration for a specific job
@@ -122,6 +255,23 @@ func (c *JobsConfig) GetJobTimeout(jobName string) time.Duration {
 	return time.Duration(c.Global.DefaultTimeoutSeconds) * time.Second
 }
 
+// GetJobInstance returns the name of the Jenkins instance a job runs on
+func (c *JobsConfig) GetJobInstance(jobName string) string {
+	if job, exists := c.Jobs[jobName]; exists && job.Instance != "" {
+		return job.Instance
+	}
+	return c.Global.DefaultInstance
+}
+
+// GetJobRootURL constructs the Jenkins job URL without the endpoint suffix
+func (c *JobsConfig) GetJobRootURL(baseURL, jobName string) (string, error) {
+	job, err := c.GetJobConfig(jobName)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimRight(baseURL, "/"), strings.Trim(job.JobPath, "/")), nil
+}
+
 // GetJobURL constructs the full Jenkins job URL
 func (c *JobsConfig) GetJobURL(baseURL, jobName string) (string, error) {
 	job, err := c.GetJobConfig(jobName)
@@ -170,6 +320,11 @@ func (c *JobsConfig) ValidateJobParameters(jobName string, params map[string]str
 	return nil
 }
 
+// Validate checks the configuration and fills in defaults
+func (c *JobsConfig) Validate() error {
+	return validateConfig(c)
+}
+
 // GetErrorCode returns the custom error code for a given error type
 func (c *JobsConfig) GetErrorCode(errorType string) string {
 	if code, exists := c.ErrorHandling.CustomErrorCodes[errorType]; exists {
@@ -178,35 +333,90 @@ func (c *JobsConfig) GetErrorCode(errorType string) string {
 	return "JENKINS_UNKNOWN_ERROR"
 }
 
-// validateConfig validates the loaded configuration
+// validateConfig validates the loaded configuration and fills in defaults,
+// reporting every invalid field as a *ValidationError
 func validateConfig(config *JobsConfig) error {
-	if config.Jobs == nil || len(config.Jobs) == 0 {
-		return fmt.Errorf("no jobs defined in configuration")
+	validation := &ValidationError{}
+
+	// Global defaults first, since job defaults derive from them
+	if config.Global.DefaultTimeoutSeconds <= 0 {
+		config.Global.DefaultTimeoutSeconds = 30
+	}
+	if config.Global.RetryAttempts < 0 {
+		validation.add("global.retry_attempts", "must not be negative")
+	}
+
+	if len(config.Jobs) == 0 {
+		validation.add("jobs", "no jobs defined in configuration")
 	}
 
-	for jobName, job := range config.Jobs {
-		if job.JobPath == "" {
-			return fmt.Errorf("job '%s' has empty job_path", jobName)
+	jobNames := make([]string, 0, len(config.Jobs))
+	for jobName := range config.Jobs {
+		jobNames = append(jobNames, jobName)
+	}
+	sort.Strings(jobNames)
+
+	for _, jobName := range jobNames {
+		job := config.Jobs[jobName]
+		field := "jobs." + jobName
+
+		if strings.TrimSpace(job.JobPath) == "" {
+			validation.add(field+".job_path", "is required")
 		}
 		if job.Method == "" {
 			job.Method = "GET" // Default to GET
 		}
+		if job.Method != "GET" && job.Method != "POST" {
+			validation.add(field+".method", "must be GET or POST, got '%s'", job.Method)
+		}
 		if job.TimeoutSeconds <= 0 {
 			job.TimeoutSeconds = config.Global.DefaultTimeoutSeconds
 		}
-	}
+		for paramName, param := range job.Parameters {
+			if param.Default != "" && len(param.AllowedValues) > 0 && !containsString(param.AllowedValues, param.Default) {
+				validation.add(field+".parameters."+paramName+".default", "'%s' is not in allowed_values %v", param.Default, param.AllowedValues)
+			}
+		}
 
-	if config.Global.DefaultTimeoutSeconds <= 0 {
-		config.Global.DefaultTimeoutSeconds = 30
+		// Map values are copies; write the defaults back
+		config.Jobs[jobName] = job
 	}
 
-	if config.Artifacts.Parsing.RegexPatterns.DeployedArtifactsSection == "" {
-		return fmt.Errorf("deployed_artifacts_section regex pattern is required")
+	patterns := config.Artifacts.Parsing.RegexPatterns
+	if patterns.DeployedArtifactsSection == "" {
+		validation.add("artifacts.parsing.regex_patterns.deployed_artifacts_section", "is required")
+	} else if _, err := regexp.Compile(patterns.DeployedArtifactsSection); err != nil {
+		validation.add("artifacts.parsing.regex_patterns.deployed_artifacts_section", "invalid pattern: %v", err)
+	}
+	if patterns.ArtifactItem == "" {
+		validation.add("artifacts.parsing.regex_patterns.artifact_item", "is required")
+	} else if _, err := regexp.Compile(patterns.ArtifactItem); err != nil {
+		validation.add("artifacts.parsing.regex_patterns.artifact_item", "invalid pattern: %v", err)
 	}
 
-	if config.Artifacts.Parsing.RegexPatterns.ArtifactItem == "" {
-		return fmt.Errorf("artifact_item regex pattern is required")
+	for i, signature := range config.Diagnostics.Signatures {
+		field := fmt.Sprintf("diagnostics.signatures[%d]", i)
+		if signature.Category == "" {
+			validation.add(field+".category", "is required")
+		}
+		for j, pattern := range signature.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				validation.add(fmt.Sprintf("%s.patterns[%d]", field, j), "invalid pattern: %v", err)
+			}
+		}
 	}
 
+	if len(validation.Errors) > 0 {
+		return validation
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
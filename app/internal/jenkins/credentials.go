@@ -0,0 +1,28 @@
+package jenkins
+
+import "context"
+
+type credentialsContextKey struct{}
+
+type requestCredentials struct {
+	username string
+	token    string
+}
+
+// WithCredentials returns a context whose authenticated requests use the given
+// credentials instead of the ones the client was created with
+func WithCredentials(ctx context.Context, username, token string) context.Context {
+	return context.WithValue(ctx, credentialsContextKey{}, requestCredentials{
+		username: username,
+		token:    token,
+	})
+}
+
+// credentials resolves the credentials for a request, preferring the ones
+// attached to the context over the client defaults
+func (c *Client) credentials(ctx context.Context) (string, string) {
+	if creds, ok := ctx.Value(credentialsContextKey{}).(requestCredentials); ok && creds.username != "" && creds.token != "" {
+		return creds.username, creds.token
+	}
+	return c.username, c.token
+}
@@ -0,0 +1,156 @@
+package jenkins
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+
+	"app/internal/jenkins/errors"
+)
+
+const crumbIssuerPath = "/crumbIssuer/api/json"
+
+// crumb is a CSRF token issued by a Jenkins instance. An empty field means the
+// instance has CSRF protection disabled and no header needs to be sent.
+type crumb struct {
+	field string
+	value string
+}
+
+// session holds the cookie jar and crumbs for one set of credentials. Jenkins
+// binds crumbs to the web session, so they are cached alongside the cookies
+// that established it.
+type session struct {
+	httpClient *http.Client
+	mu         sync.Mutex
+	crumbs     map[string]*crumb // keyed by Jenkins root URL
+}
+
+// sessionFor returns the session for the given username, creating it on first use
+func (c *Client) sessionFor(username string) *session {
+	c.sessionsMu.Lock()
+	defer c.sessionsMu.Unlock()
+
+	if existing, ok := c.sessions[username]; ok {
+		return existing
+	}
+
+	jar, _ := cookiejar.New(nil)
+	created := &session{
+		httpClient: &http.Client{
+			Transport: c.transport,
+			Timeout:   c.options.Timeout,
+			Jar:       jar,
+		},
+		crumbs: make(map[string]*crumb),
+	}
+	c.sessions[username] = created
+	return created
+}
+
+// crumbFor returns the cached crumb for the Jenkins instance serving requestURL,
+// fetching it from the crumb issuer when missing
+func (c *Client) crumbFor(ctx context.Context, sess *session, requestURL string, useAuth bool) (*crumb, error) {
+	rootURL := jenkinsRootURL(requestURL)
+
+	sess.mu.Lock()
+	cached, ok := sess.crumbs[rootURL]
+	sess.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	fetched, err := c.fetchCrumb(ctx, sess, rootURL, useAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.mu.Lock()
+	sess.crumbs[rootURL] = fetched
+	sess.mu.Unlock()
+	return fetched, nil
+}
+
+// invalidateCrumb drops the cached crumb so the next POST fetches a fresh one
+func (c *Client) invalidateCrumb(sess *session, requestURL string) {
+	sess.mu.Lock()
+	delete(sess.crumbs, jenkinsRootURL(requestURL))
+	sess.mu.Unlock()
+}
+
+// fetchCrumb requests a new crumb from the Jenkins crumb issuer
+func (c *Client) fetchCrumb(ctx context.Context, sess *session, rootURL string, useAuth bool) (*crumb, error) {
+	crumbURL := rootURL + crumbIssuerPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, crumbURL, nil)
+	if err != nil {
+		return nil, errors.NewNetworkError("failed to create crumb request", 0, err)
+	}
+	if c.options.UserAgent != "" {
+		req.Header.Set("User-Agent", c.options.UserAgent)
+	}
+	if useAuth {
+		username, token := c.credentials(ctx)
+		if username != "" && token != "" {
+			req.SetBasicAuth(username, token)
+		}
+	}
+
+	resp, err := sess.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.NewNetworkError("failed to get crumb", 0, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.NewNetworkError("failed to read crumb response", resp.StatusCode, err)
+	}
+
+	// Instances without CSRF protection don't expose a crumb issuer
+	if resp.StatusCode == http.StatusNotFound {
+		return &crumb{}, nil
+	}
+	if resp.StatusCode >= 400 {
+		return nil, c.handleHTTPError(resp.StatusCode, string(body), crumbURL)
+	}
+
+	var crumbResp struct {
+		Crumb             string `json:"crumb"`
+		CrumbRequestField string `json:"crumbRequestField"`
+	}
+	if err := json.Unmarshal(body, &crumbResp); err != nil {
+		return nil, errors.NewParsingError(crumbURL, "failed to parse crumb response", err)
+	}
+
+	return &crumb{field: crumbResp.CrumbRequestField, value: crumbResp.Crumb}, nil
+}
+
+// jenkinsRootURL derives the Jenkins root URL from any URL on that instance,
+// keeping context paths such as https://host/jenkins
+func jenkinsRootURL(requestURL string) string {
+	if idx := strings.Index(requestURL, "/job/"); idx != -1 {
+		return strings.TrimRight(requestURL[:idx], "/")
+	}
+	for _, marker := range []string{"/queue/", "/crumbIssuer/", "/api/"} {
+		if idx := strings.Index(requestURL, marker); idx != -1 {
+			return strings.TrimRight(requestURL[:idx], "/")
+		}
+	}
+
+	parsed, err := url.Parse(requestURL)
+	if err != nil || parsed.Host == "" {
+		return strings.TrimRight(requestURL, "/")
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// isCrumbError reports whether a response rejected the request for a missing or stale crumb
+func isCrumbError(statusCode int, body string) bool {
+	return statusCode == http.StatusForbidden && strings.Contains(strings.ToLower(body), "crumb")
+}
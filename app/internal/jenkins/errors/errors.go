@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Error types for Jenkins operations
@@ -16,6 +17,7 @@ var (
 	ErrNetworkError        = errors.New("jenkins network error")
 	ErrInvalidURL          = errors.New("invalid jenkins URL")
 	ErrJobExecutionFailed  = errors.New("jenkins job execution failed")
+	ErrCircuitOpen         = errors.New("jenkins circuit breaker open")
 )
 
 // JenkinsError represents a Jenkins-specific error with additional context
@@ -27,6 +29,7 @@ type JenkinsError struct {
 	JobName     string      // Jenkins job name (if applicable)
 	BuildURL    string      // Jenkins build URL (if applicable)
 	StatusCode  int         // HTTP status code (if applicable)
+	RetryAfter  time.Duration // Server-requested delay before retrying (if applicable)
 	Context     interface{} // Additional context data
 }
 
@@ -69,6 +72,8 @@ func (e *JenkinsError) Is(target error) bool {
 		return e.Type == "invalid_url"
 	case ErrJobExecutionFailed:
 		return e.Type == "job_execution_failed"
+	case ErrCircuitOpen:
+		return e.Type == "circuit_open"
 	default:
 		return false
 	}
@@ -179,6 +184,17 @@ func NewJobExecutionError(jobName, message string, cause error) *JenkinsError {
 	}
 }
 
+// NewCircuitOpenError creates an error for requests rejected while a host's circuit breaker is open
+func NewCircuitOpenError(host string, retryIn time.Duration) *JenkinsError {
+	return &JenkinsError{
+		Type:       "circuit_open",
+		Code:       "JENKINS_CIRCUIT_010",
+		Message:    fmt.Sprintf("Jenkins at %s is unavailable, retrying in %s", host, retryIn.Round(time.Second)),
+		StatusCode: 503,
+		RetryAfter: retryIn,
+	}
+}
+
 // IsAuthenticationError checks if error is an authentication error
 func IsAuthenticationError(err error) bool {
 	return errors.Is(err, ErrAuthenticationFailed)
@@ -189,6 +205,21 @@ func IsJobNotFoundError(err error) bool {
 	return errors.Is(err, ErrJobNotFound)
 }
 
+// IsInvalidParametersError checks if error is an invalid parameters error
+func IsInvalidParametersError(err error) bool {
+	return errors.Is(err, ErrInvalidParameters)
+}
+
+// IsInvalidURLError checks if error is an invalid URL error
+func IsInvalidURLError(err error) bool {
+	return errors.Is(err, ErrInvalidURL)
+}
+
+// IsCircuitOpenError checks if error was caused by an open circuit breaker
+func IsCircuitOpenError(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
+
 // IsTimeoutError checks if error is a timeout error
 func IsTimeoutError(err error) bool {
 	return errors.Is(err, ErrTimeout)
@@ -0,0 +1,191 @@
+package jenkins
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"app/internal/config"
+	jenkinsconfig "app/internal/jenkins/config"
+	"app/internal/jenkins/errors"
+)
+
+// Pool holds one long-lived client per configured Jenkins instance so that
+// connections, sessions and crumbs are reused across requests. Per-request
+// credentials are applied with WithCredentials instead of building new clients.
+type Pool struct {
+	mu        sync.Mutex
+	instances map[string]config.JenkinsInstanceConfig
+	clients   map[string]*Client
+}
+
+// NewPool creates a client pool for the given instance registry and checks that
+// every job in jobs.json references a known instance
+func NewPool(instances map[string]config.JenkinsInstanceConfig) (*Pool, error) {
+	jobsConfig, err := jenkinsconfig.LoadConfig()
+	if err != nil {
+		return nil, errors.NewConfigurationError("failed to load Jenkins configuration", err)
+	}
+
+	for name, instance := range instances {
+		if strings.TrimSpace(instance.URL) == "" {
+			return nil, errors.NewConfigurationError(fmt.Sprintf("Jenkins instance '%s' has no URL", name), nil)
+		}
+		if instance.CredentialsSource != config.CredentialsFromEnv && instance.CredentialsSource != config.CredentialsFromRequest {
+			return nil, errors.NewConfigurationError(
+				fmt.Sprintf("Jenkins instance '%s' has unknown credentials source '%s'", name, instance.CredentialsSource), nil)
+		}
+	}
+
+	pool := &Pool{
+		instances: instances,
+		clients:   make(map[string]*Client),
+	}
+	if err := pool.ValidateJobs(jobsConfig); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+// ValidateJobs checks that every job in a jobs configuration references a
+// known instance, so a reload cannot install jobs the pool cannot serve
+func (p *Pool) ValidateJobs(jobsConfig *jenkinsconfig.JobsConfig) error {
+	jobNames := make([]string, 0, len(jobsConfig.Jobs))
+	for jobName := range jobsConfig.Jobs {
+		jobNames = append(jobNames, jobName)
+	}
+	sort.Strings(jobNames)
+
+	for _, jobName := range jobNames {
+		instanceName := jobsConfig.GetJobInstance(jobName)
+		if _, ok := p.instances[instanceName]; !ok {
+			return errors.NewConfigurationError(
+				fmt.Sprintf("job '%s' references unknown Jenkins instance '%s'", jobName, instanceName), nil)
+		}
+	}
+	return nil
+}
+
+// Get returns the client for a named instance, creating it on first use
+func (p *Pool) Get(name string) (*Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, ok := p.clients[name]; ok {
+		return client, nil
+	}
+
+	instance, ok := p.instances[name]
+	if !ok {
+		return nil, errors.NewConfigurationError(fmt.Sprintf("unknown Jenkins instance '%s'", name), nil)
+	}
+
+	client, err := NewClientWithConfig(ClientConfig{
+		URL:                instance.URL,
+		Username:           instance.Username,
+		Token:              instance.Token,
+		InsecureSkipVerify: instance.InsecureSkipVerify,
+		Timeout:            time.Duration(instance.TimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.clients[name] = client
+	return client, nil
+}
+
+// ForJob returns the client for the instance a jobs.json job runs on
+func (p *Pool) ForJob(jobName string) (*Client, error) {
+	return p.Get(p.Jobs().GetJobInstance(jobName))
+}
+
+// ForURL returns the client whose instance serves the given URL: the one with
+// the same scheme and host whose base path is the longest prefix of the URL's
+// path. A URL no instance serves is rejected rather than sent the default
+// instance's credentials.
+func (p *Pool) ForURL(rawURL string) (*Client, error) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || target.Host == "" {
+		return nil, errors.NewInvalidURLError(rawURL, "not an absolute Jenkins URL", err)
+	}
+
+	bestName := ""
+	bestLength := -1
+	for name, instance := range p.instances {
+		base, err := url.Parse(strings.TrimSpace(instance.URL))
+		if err != nil || !sameOrigin(base, target) {
+			continue
+		}
+		basePath := strings.TrimRight(base.Path, "/")
+		if !strings.HasPrefix(target.Path, basePath) {
+			continue
+		}
+		if rest := target.Path[len(basePath):]; rest != "" && !strings.HasPrefix(rest, "/") {
+			continue
+		}
+		if len(basePath) > bestLength {
+			bestName = name
+			bestLength = len(basePath)
+		}
+	}
+	if bestName == "" {
+		return nil, errors.NewInvalidURLError(rawURL, fmt.Sprintf("%s is not served by a configured Jenkins instance", target.Host), nil)
+	}
+	return p.Get(bestName)
+}
+
+// sameOrigin reports whether two URLs have the same scheme, host and port,
+// ignoring case and an explicit default port
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) &&
+		strings.EqualFold(a.Hostname(), b.Hostname()) &&
+		effectivePort(a) == effectivePort(b)
+}
+
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return "443"
+	case "http":
+		return "80"
+	}
+	return ""
+}
+
+// Close releases the idle connections of every pooled client
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, client := range p.clients {
+		client.Close()
+	}
+}
+
+// Names returns the configured instance names in sorted order
+func (p *Pool) Names() []string {
+	names := make([]string, 0, len(p.instances))
+	for name := range p.instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Instance returns the configuration of a named instance
+func (p *Pool) Instance(name string) (config.JenkinsInstanceConfig, bool) {
+	instance, ok := p.instances[name]
+	return instance, ok
+}
+
+// Jobs returns the currently installed jobs configuration
+func (p *Pool) Jobs() *jenkinsconfig.JobsConfig {
+	return jenkinsconfig.Current()
+}
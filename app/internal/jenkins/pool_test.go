@@ -0,0 +1,57 @@
+package jenkins
+
+import (
+	"testing"
+
+	"app/internal/config"
+	"app/internal/jenkins/errors"
+)
+
+func TestPoolForURL(t *testing.T) {
+	// Clients are seeded so Get returns them without building real ones
+	pool := &Pool{
+		instances: map[string]config.JenkinsInstanceConfig{
+			"delivery": {URL: "https://jenkins.corp/"},
+			"nested":   {URL: "https://jenkins.corp/team"},
+			"storage":  {URL: "http://storage:8080"},
+		},
+		clients: map[string]*Client{"delivery": {}, "nested": {}, "storage": {}},
+	}
+
+	tests := []struct {
+		url      string
+		instance string // empty when the URL must be rejected
+	}{
+		{url: "https://jenkins.corp/job/x/1/", instance: "delivery"},
+		{url: "https://JENKINS.corp:443/job/x/1/", instance: "delivery"},
+		{url: "https://jenkins.corp", instance: "delivery"},
+		{url: "https://jenkins.corp/team/job/x/1/", instance: "nested"},
+		{url: "https://jenkins.corp/team", instance: "nested"},
+		{url: "https://jenkins.corp/teamwork/job/x/1/", instance: "delivery"},
+		{url: "http://storage:8080/job/y/2/", instance: "storage"},
+		{url: "https://jenkins.corp.evil.io/job/x/1/"},
+		{url: "http://storage:80801/job/y/2/"},
+		{url: "http://storage/job/y/2/"},
+		{url: "http://jenkins.corp/job/x/1/"},
+		{url: "https://user@jenkins.corp.evil.io/job/x/1/"},
+		{url: "/job/x/1/"},
+		{url: "not a url"},
+	}
+
+	for _, tt := range tests {
+		client, err := pool.ForURL(tt.url)
+		if tt.instance == "" {
+			if err == nil || !errors.IsInvalidURLError(err) {
+				t.Errorf("ForURL(%q) = %v, %v, want an invalid URL error", tt.url, client, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ForURL(%q): %v", tt.url, err)
+			continue
+		}
+		if client != pool.clients[tt.instance] {
+			t.Errorf("ForURL(%q) did not return the %s client", tt.url, tt.instance)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"app/internal/jenkins/types"
+)
+
+// artifactCacheIndex is the file recording cached artifacts, kept in the cache directory
+const artifactCacheIndex = "index.json"
+
+// ArtifactCache is a local content-addressed store for downloaded artifacts.
+// Files are keyed by the SHA-256 of their content; an index maps source URLs
+// to hashes and tracks access times so the least recently used artifacts are
+// evicted once the cache grows beyond its size limit.
+type ArtifactCache struct {
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	entries map[string]*types.CachedArtifact // by hash
+	byURL   map[string]string                // source URL to hash
+}
+
+// NewArtifactCache opens the cache in dir, creating it if needed
+func NewArtifactCache(dir string, maxSizeMB int) (*ArtifactCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create artifact cache directory: %w", err)
+	}
+	if maxSizeMB <= 0 {
+		maxSizeMB = 2048
+	}
+
+	cache := &ArtifactCache{
+		dir:     dir,
+		maxSize: int64(maxSizeMB) * 1024 * 1024,
+		entries: make(map[string]*types.CachedArtifact),
+		byURL:   make(map[string]string),
+	}
+	cache.loadIndex()
+	return cache, nil
+}
+
+// Lookup returns the cached artifact downloaded from sourceURL, if any
+func (c *ArtifactCache) Lookup(sourceURL string) (*types.CachedArtifact, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hash, ok := c.byURL[sourceURL]
+	if !ok {
+		return nil, false
+	}
+	entry, ok := c.entries[hash]
+	if !ok {
+		return nil, false
+	}
+	if _, err := os.Stat(c.path(hash)); err != nil {
+		c.remove(hash)
+		return nil, false
+	}
+
+	entry.LastAccess = time.Now().UTC()
+	c.saveIndex()
+	found := *entry
+	return &found, true
+}
+
+// Store writes content to the cache and evicts old entries if the cache is full.
+// verify, if set, runs once content has been read completely and before it is
+// added to the cache; an error discards the download.
+func (c *ArtifactCache) Store(content io.Reader, artifact types.CachedArtifact, verify func() error) (*types.CachedArtifact, error) {
+	temp, err := os.CreateTemp(c.dir, "download-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(temp, hasher), content)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if verify != nil {
+		if err := verify(); err != nil {
+			return nil, err
+		}
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	target := c.path(hash)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.Rename(temp.Name(), target); err != nil {
+		return nil, fmt.Errorf("failed to move artifact into cache: %w", err)
+	}
+
+	now := time.Now().UTC()
+	artifact.Hash = hash
+	artifact.Size = size
+	artifact.CachedAt = now
+	artifact.LastAccess = now
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[hash] = &artifact
+	c.byURL[artifact.URL] = hash
+	c.evict(hash)
+	c.saveIndex()
+
+	stored := artifact
+	return &stored, nil
+}
+
+// Open returns the cached file for hash together with its description
+func (c *ArtifactCache) Open(hash string) (*os.File, *types.CachedArtifact, error) {
+	if !isContentHash(hash) {
+		return nil, nil, os.ErrNotExist
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[hash]
+	if !ok {
+		return nil, nil, os.ErrNotExist
+	}
+	file, err := os.Open(c.path(hash))
+	if err != nil {
+		c.remove(hash)
+		return nil, nil, err
+	}
+
+	entry.LastAccess = time.Now().UTC()
+	c.saveIndex()
+	found := *entry
+	return file, &found, nil
+}
+
+// Size returns the total size of the cached artifacts in bytes
+func (c *ArtifactCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total int64
+	for _, entry := range c.entries {
+		total += entry.Size
+	}
+	return total
+}
+
+// evict removes least recently used entries until the cache fits its limit.
+// The entry identified by keep is never evicted, even when it alone exceeds
+// the limit, so the caller can still serve what it just stored.
+func (c *ArtifactCache) evict(keep string) {
+	entries := make([]*types.CachedArtifact, 0, len(c.entries))
+	var total int64
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+		total += entry.Size
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastAccess.Before(entries[j].LastAccess) })
+
+	for _, entry := range entries {
+		if total <= c.maxSize {
+			return
+		}
+		if entry.Hash == keep {
+			continue
+		}
+		total -= entry.Size
+		c.remove(entry.Hash)
+	}
+}
+
+// remove deletes an entry and its file; callers hold c.mu
+func (c *ArtifactCache) remove(hash string) {
+	if entry, ok := c.entries[hash]; ok {
+		for sourceURL, urlHash := range c.byURL {
+			if urlHash == hash {
+				delete(c.byURL, sourceURL)
+			}
+		}
+		delete(c.entries, entry.Hash)
+	}
+	_ = os.Remove(c.path(hash))
+}
+
+// path returns the file path of a hash, fanned out by its first two characters
+func (c *ArtifactCache) path(hash string) string {
+	return filepath.Join(c.dir, hash[:2], hash)
+}
+
+// loadIndex restores the index written by a previous run, dropping entries whose files are gone
+func (c *ArtifactCache) loadIndex() {
+	data, err := os.ReadFile(filepath.Join(c.dir, artifactCacheIndex))
+	if err != nil {
+		return
+	}
+
+	var entries []*types.CachedArtifact
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !isContentHash(entry.Hash) {
+			continue
+		}
+		if _, err := os.Stat(c.path(entry.Hash)); err != nil {
+			continue
+		}
+		entry.CacheHit = false
+		c.entries[entry.Hash] = entry
+		c.byURL[entry.URL] = entry.Hash
+	}
+}
+
+// saveIndex persists the index; callers hold c.mu. A failed write only loses
+// the index across restarts, so it is not reported.
+func (c *ArtifactCache) saveIndex() {
+	entries := make([]*types.CachedArtifact, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Hash < entries[j].Hash })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return
+	}
+	indexPath := filepath.Join(c.dir, artifactCacheIndex)
+	if err := os.WriteFile(indexPath+".tmp", data, 0o644); err != nil {
+		return
+	}
+	_ = os.Rename(indexPath+".tmp", indexPath)
+}
+
+// isContentHash reports whether value is a hex SHA-256, guarding file paths built from request input
+func isContentHash(value string) bool {
+	if len(value) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
@@ -2,25 +2,44 @@ package services
 
 import (
 	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"time"
 
+	jenkinsconfig "app/internal/jenkins/config"
 	"app/internal/jenkins/errors"
 	"app/internal/jenkins/types"
 )
 
+// artifactDownloadPath is the API path cached artifacts are served from
+const artifactDownloadPath = "/api/artifacts/"
+
 // ArtifactsServiceImpl implements the ArtifactsService interface
 type ArtifactsServiceImpl struct {
-	client JenkinsClient
+	client     JenkinsClient
+	cache      *ArtifactCache // nil disables downloads
+	httpClient *http.Client   // for artifacts outside Jenkins, e.g. Nexus
 }
 
 // NewArtifactsService creates a new artifacts service instance
-func NewArtifactsService(client JenkinsClient) ArtifactsService {
+func NewArtifactsService(client JenkinsClient, cache *ArtifactCache, httpClient *http.Client) ArtifactsService {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
 	return &ArtifactsServiceImpl{
-		client: client,
+		client:     client,
+		cache:      cache,
+		httpClient: httpClient,
 	}
 }
 
@@ -42,24 +61,10 @@ func (a *ArtifactsServiceImpl) ExtractArtifacts(ctx context.Context, request *ty
 	// Normalize the build URL
 	buildURL := a.normalizeBuildURL(request.BuildURL)
 
-	// Fetch the build page HTML
-	htmlContent, err := a.client.GetWithAuth(ctx, buildURL)
-	if err != nil {
-		return nil, errors.NewNetworkError(
-			fmt.Sprintf("failed to fetch build page: %s", buildURL),
-			0,
-			err,
-		)
-	}
-
-	// Parse artifacts from HTML content
-	artifacts, err := a.parseArtifactsFromHTML(string(htmlContent))
+	// Prefer the JSON API; the HTML page layout changes with Jenkins themes
+	artifacts, method, fallbackReason, err := a.extractArtifacts(ctx, buildURL)
 	if err != nil {
-		return nil, errors.NewParsingError(
-			buildURL,
-			"failed to parse artifacts from HTML",
-			err,
-		)
+		return nil, err
 	}
 
 	// Filter artifacts by type if specified
@@ -71,16 +76,20 @@ func (a *ArtifactsServiceImpl) ExtractArtifacts(ctx context.Context, request *ty
 	a.enhanceArtifacts(artifacts)
 
 	response := &types.ArtifactExtractionResponse{
-		Artifacts:   artifacts,
-		TotalCount:  len(artifacts),
-		BuildURL:    buildURL,
-		ExtractedAt: time.Now().UTC(),
+		Artifacts:        artifacts,
+		TotalCount:       len(artifacts),
+		BuildURL:         buildURL,
+		ExtractionMethod: method,
+		ExtractedAt:      time.Now().UTC(),
 		Metadata: map[string]string{
-			"extraction_method": "html_parsing",
+			"extraction_method": method,
 			"jenkins_build":     buildURL,
 			"timestamp":         time.Now().UTC().Format(time.RFC3339),
 		},
 	}
+	if fallbackReason != "" {
+		response.Metadata["fallback_reason"] = fallbackReason
+	}
 
 	// Add filtering metadata if applicable
 	if len(request.FilterTypes) > 0 {
@@ -90,6 +99,50 @@ func (a *ArtifactsServiceImpl) ExtractArtifacts(ctx context.Context, request *ty
 	return response, nil
 }
 
+// DownloadArtifacts downloads the build artifacts whose names match the request
+// patterns into the local cache, verifying them against the .sha1 or .md5
+// files published next to them. Artifacts already cached are not downloaded again.
+func (a *ArtifactsServiceImpl) DownloadArtifacts(ctx context.Context, request *types.ArtifactDownloadRequest) (*types.ArtifactDownloadResponse, error) {
+	if a.cache == nil {
+		return nil, errors.NewConfigurationError("artifact cache is not configured", nil)
+	}
+	if len(request.Patterns) == 0 {
+		return nil, errors.NewInvalidParametersError("artifacts", "at least one pattern is required", nil)
+	}
+	for _, pattern := range request.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.NewInvalidParametersError("artifacts", fmt.Sprintf("invalid pattern '%s'", pattern), err)
+		}
+	}
+
+	extraction, err := a.ExtractArtifacts(ctx, &types.ArtifactExtractionRequest{BuildURL: request.BuildURL})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.ArtifactDownloadResponse{
+		BuildURL:  extraction.BuildURL,
+		Artifacts: []types.CachedArtifact{},
+	}
+	for _, artifact := range extraction.Artifacts {
+		if !matchesAnyPattern(artifact.Name, request.Patterns) {
+			continue
+		}
+		cached, err := a.downloadArtifact(ctx, artifact)
+		if err != nil {
+			response.Errors = append(response.Errors, fmt.Sprintf("%s: %v", artifact.Name, err))
+			continue
+		}
+		response.Artifacts = append(response.Artifacts, *cached)
+	}
+
+	if len(response.Artifacts) == 0 && len(response.Errors) == 0 {
+		return nil, errors.NewJobNotFoundError("artifacts",
+			fmt.Sprintf("no artifacts of %s match %s", extraction.BuildURL, strings.Join(request.Patterns, ", ")), nil)
+	}
+	return response, nil
+}
+
 // GetBuildInfo retrieves detailed information about a Jenkins build
 func (a *ArtifactsServiceImpl) GetBuildInfo(ctx context.Context, buildURL string) (*types.BuildInfo, error) {
 	// Normalize URL and add API suffix
@@ -227,6 +280,338 @@ func (a *ArtifactsServiceImpl) FilterArtifacts(artifacts []types.DeployedArtifac
 
 // Helper methods
 
+// downloadArtifact returns the cached copy of an artifact, downloading and
+// verifying it first if needed
+func (a *ArtifactsServiceImpl) downloadArtifact(ctx context.Context, artifact types.DeployedArtifact) (*types.CachedArtifact, error) {
+	if cached, ok := a.cache.Lookup(artifact.URL); ok {
+		cached.CacheHit = true
+		cached.DownloadURL = artifactDownloadPath + cached.Hash
+		return cached, nil
+	}
+
+	description := types.CachedArtifact{Name: artifact.Name, URL: artifact.URL}
+
+	// Archived artifacts come from Jenkins itself and need its credentials;
+	// they have no checksum siblings
+	if strings.HasPrefix(artifact.URL, a.client.GetBaseURL()+"/") {
+		downloadCtx, cancel := context.WithTimeout(ctx, artifactDownloadTimeout)
+		defer cancel()
+		body, err := a.client.OpenWithAuth(downloadCtx, artifact.URL)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		cached, err := a.cache.Store(body, description, nil)
+		if err != nil {
+			return nil, err
+		}
+		cached.DownloadURL = artifactDownloadPath + cached.Hash
+		return cached, nil
+	}
+
+	checksumType, expected := a.fetchChecksum(ctx, artifact.URL)
+
+	body, err := a.openURL(ctx, artifact.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var content io.Reader = body
+	var hasher hash.Hash
+	switch checksumType {
+	case "sha1":
+		hasher = sha1.New()
+	case "md5":
+		hasher = md5.New()
+	}
+	var verify func() error
+	if hasher != nil {
+		content = io.TeeReader(body, hasher)
+		description.ChecksumType = checksumType
+		description.Checksum = expected
+		description.Verified = true
+		verify = func() error {
+			if actual := hex.EncodeToString(hasher.Sum(nil)); actual != expected {
+				return fmt.Errorf("%s checksum mismatch: expected %s, got %s", checksumType, expected, actual)
+			}
+			return nil
+		}
+	}
+
+	cached, err := a.cache.Store(content, description, verify)
+	if err != nil {
+		return nil, err
+	}
+	cached.DownloadURL = artifactDownloadPath + cached.Hash
+	return cached, nil
+}
+
+// fetchChecksum reads the .sha1 or .md5 file Nexus publishes next to an
+// artifact. It returns an empty type when neither exists.
+func (a *ArtifactsServiceImpl) fetchChecksum(ctx context.Context, artifactURL string) (string, string) {
+	for _, checksumType := range []string{"sha1", "md5"} {
+		body, err := a.openURL(ctx, artifactURL+"."+checksumType)
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(body, 1024))
+		body.Close()
+		if err != nil {
+			continue
+		}
+		// Checksum files may carry the file name after the digest
+		fields := strings.Fields(string(data))
+		if len(fields) > 0 {
+			return checksumType, strings.ToLower(fields[0])
+		}
+	}
+	return "", ""
+}
+
+// openURL starts a GET request outside Jenkins and returns the response body
+func (a *ArtifactsServiceImpl) openURL(ctx context.Context, target string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.NewNetworkError(fmt.Sprintf("failed to download %s", target), 0, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.NewNetworkError(fmt.Sprintf("failed to download %s: HTTP %d", target, resp.StatusCode), resp.StatusCode, nil)
+	}
+	return resp.Body, nil
+}
+
+func matchesAnyPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// artifactsTree selects the archived artifacts and the deployed-artifact records
+// of the Maven and Artifactory plugins from the build API
+const artifactsTree = "artifacts[fileName,relativePath]," +
+	"actions[_class," +
+	"deployedArtifacts[groupId,artifactId,version,type,extension,classifier,fileName,url,fileSize,repositoryUrl]," +
+	"mainArtifact[groupId,artifactId,version,type,classifier,fileName]," +
+	"attachedArtifacts[groupId,artifactId,version,type,classifier,fileName]," +
+	"deploymentRepository[url]," +
+	"deployedArtifactsList[name,url,type,repository]]"
+
+// buildArtifactsResponse is the subset of the build API used for artifact extraction
+type buildArtifactsResponse struct {
+	Artifacts []struct {
+		FileName     string `json:"fileName"`
+		RelativePath string `json:"relativePath"`
+	} `json:"artifacts"`
+	Actions []artifactsAction `json:"actions"`
+}
+
+// artifactsAction covers the build actions that record deployed artifacts:
+// the Maven integration plugins (deployedArtifacts, or mainArtifact and
+// attachedArtifacts with a deployment repository) and the Artifactory plugin
+// (deployedArtifactsList)
+type artifactsAction struct {
+	Class                string                `json:"_class"`
+	DeployedArtifacts    []mavenArtifactRecord `json:"deployedArtifacts"`
+	MainArtifact         *mavenArtifactRecord  `json:"mainArtifact"`
+	AttachedArtifacts    []mavenArtifactRecord `json:"attachedArtifacts"`
+	DeploymentRepository *struct {
+		URL string `json:"url"`
+	} `json:"deploymentRepository"`
+	DeployedArtifactsList []struct {
+		Name       string `json:"name"`
+		URL        string `json:"url"`
+		Type       string `json:"type"`
+		Repository string `json:"repository"`
+	} `json:"deployedArtifactsList"`
+}
+
+// mavenArtifactRecord is one Maven artifact as exported by the Maven plugins
+type mavenArtifactRecord struct {
+	GroupID       string `json:"groupId"`
+	ArtifactID    string `json:"artifactId"`
+	Version       string `json:"version"`
+	Type          string `json:"type"`
+	Extension     string `json:"extension"`
+	Classifier    string `json:"classifier"`
+	FileName      string `json:"fileName"`
+	URL           string `json:"url"`
+	FileSize      int64  `json:"fileSize"`
+	RepositoryURL string `json:"repositoryUrl"`
+}
+
+// extractArtifacts reads artifacts through the JSON API and falls back to the
+// HTML build page when the API fails or reports none. It returns the method
+// that produced the result and, after a fallback, why the API was not used.
+func (a *ArtifactsServiceImpl) extractArtifacts(ctx context.Context, buildURL string) ([]types.DeployedArtifact, string, string, error) {
+	artifacts, apiErr := a.extractArtifactsFromAPI(ctx, buildURL)
+	if apiErr == nil && len(artifacts) > 0 {
+		return artifacts, types.ExtractionMethodJSONAPI, "", nil
+	}
+
+	fallbackReason := "no artifacts reported by the JSON API"
+	if apiErr != nil {
+		fallbackReason = apiErr.Error()
+	}
+
+	htmlContent, err := a.client.GetWithAuth(ctx, buildURL)
+	if err != nil {
+		if apiErr == nil {
+			// The API answered authoritatively; an empty result stands
+			return artifacts, types.ExtractionMethodJSONAPI, "", nil
+		}
+		return nil, "", "", errors.NewNetworkError(
+			fmt.Sprintf("failed to fetch build page: %s", buildURL),
+			0,
+			err,
+		)
+	}
+
+	htmlArtifacts, err := a.parseArtifactsFromHTML(string(htmlContent))
+	if err != nil {
+		return nil, "", "", errors.NewParsingError(
+			buildURL,
+			"failed to parse artifacts from HTML",
+			err,
+		)
+	}
+	if len(htmlArtifacts) == 0 && apiErr == nil {
+		return artifacts, types.ExtractionMethodJSONAPI, "", nil
+	}
+	return htmlArtifacts, types.ExtractionMethodHTML, fallbackReason, nil
+}
+
+// extractArtifactsFromAPI lists archived and deployed artifacts from the build API
+func (a *ArtifactsServiceImpl) extractArtifactsFromAPI(ctx context.Context, buildURL string) ([]types.DeployedArtifact, error) {
+	apiURL := buildURL + "api/json?tree=" + url.QueryEscape(artifactsTree)
+	responseBody, err := a.client.GetWithAuth(ctx, apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("JSON API request failed: %w", err)
+	}
+
+	var build buildArtifactsResponse
+	if err := json.Unmarshal(responseBody, &build); err != nil {
+		return nil, fmt.Errorf("JSON API response could not be parsed: %w", err)
+	}
+
+	var artifacts []types.DeployedArtifact
+	seen := make(map[string]bool)
+	add := func(artifact types.DeployedArtifact) {
+		if artifact.URL == "" || seen[artifact.URL] {
+			return
+		}
+		seen[artifact.URL] = true
+		a.parseArtifactURL(&artifact)
+		artifacts = append(artifacts, artifact)
+	}
+
+	for _, action := range build.Actions {
+		repositoryURL := ""
+		if action.DeploymentRepository != nil {
+			repositoryURL = action.DeploymentRepository.URL
+		}
+
+		for _, record := range action.DeployedArtifacts {
+			add(record.toDeployedArtifact(firstNonEmpty(record.RepositoryURL, repositoryURL), action.Class))
+		}
+		if repositoryURL != "" {
+			if action.MainArtifact != nil {
+				add(action.MainArtifact.toDeployedArtifact(repositoryURL, action.Class))
+			}
+			for _, record := range action.AttachedArtifacts {
+				add(record.toDeployedArtifact(repositoryURL, action.Class))
+			}
+		}
+		for _, item := range action.DeployedArtifactsList {
+			add(types.DeployedArtifact{
+				Name:       item.Name,
+				URL:        item.URL,
+				Type:       firstNonEmpty(item.Type, artifactTypeFromFileName(item.Name)),
+				Repository: item.Repository,
+				Metadata:   map[string]string{"action": action.Class},
+			})
+		}
+	}
+
+	for _, archived := range build.Artifacts {
+		add(types.DeployedArtifact{
+			Name: archived.FileName,
+			URL:  buildURL + "artifact/" + archived.RelativePath,
+			Type: artifactTypeFromFileName(archived.FileName),
+			Path: archived.RelativePath,
+			Metadata: map[string]string{
+				"source": "jenkins_archive",
+			},
+		})
+	}
+
+	return artifacts, nil
+}
+
+// toDeployedArtifact converts a Maven record, deriving the repository URL of
+// the file from its coordinates when the plugin does not export one
+func (r mavenArtifactRecord) toDeployedArtifact(repositoryURL, actionClass string) types.DeployedArtifact {
+	extension := firstNonEmpty(r.Extension, r.Type, "jar")
+	fileName := r.FileName
+	if fileName == "" || strings.ContainsAny(fileName, "/\\") {
+		fileName = r.ArtifactID + "-" + r.Version
+		if r.Classifier != "" {
+			fileName += "-" + r.Classifier
+		}
+		fileName += "." + extension
+	}
+
+	artifactURL := r.URL
+	if artifactURL == "" && repositoryURL != "" && r.GroupID != "" && r.ArtifactID != "" && r.Version != "" {
+		artifactURL = strings.TrimRight(repositoryURL, "/") + "/" +
+			strings.ReplaceAll(r.GroupID, ".", "/") + "/" + r.ArtifactID + "/" + r.Version + "/" + fileName
+	}
+
+	return types.DeployedArtifact{
+		Name: fileName,
+		URL:  artifactURL,
+		Type: firstNonEmpty(r.Type, extension),
+		Size: r.FileSize,
+		Metadata: map[string]string{
+			"action":   actionClass,
+			"group_id": r.GroupID,
+			"version":  r.Version,
+		},
+	}
+}
+
+// artifactTypeFromFileName derives the artifact type from its extension,
+// keeping compound extensions such as tar.gz together
+func artifactTypeFromFileName(fileName string) string {
+	lowered := strings.ToLower(fileName)
+	for _, compound := range []string{"tar.gz", "tar.bz2"} {
+		if strings.HasSuffix(lowered, "."+compound) {
+			return compound
+		}
+	}
+	if index := strings.LastIndex(lowered, "."); index >= 0 && index < len(lowered)-1 {
+		return lowered[index+1:]
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
 // normalizeBuildURL normalizes a Jenkins build URL
 func (a *ArtifactsServiceImpl) normalizeBuildURL(buildURL string) string {
 	// Remove trailing slashes and API suffixes
@@ -241,9 +626,21 @@ func (a *ArtifactsServiceImpl) normalizeBuildURL(buildURL string) string {
 func (a *ArtifactsServiceImpl) parseArtifactsFromHTML(htmlContent string) ([]types.DeployedArtifact, error) {
 	var artifacts []types.DeployedArtifact
 
-	// Regular expressions for parsing - these should come from configuration
-	deployedArtifactsRegex := regexp.MustCompile(`(?s)Deployed Artifacts\s*<ul>(.*?)</ul>`)
-	artifactRegex := regexp.MustCompile(`<li><a href="([^"]+)">([^<]+)</a>\s*\(type:\s*([^)]+)\)</li>`)
+	// Patterns come from jobs.json so they can follow Jenkins UI changes
+	sectionPattern := `(?s)Deployed Artifacts\s*<ul>(.*?)</ul>`
+	itemPattern := `<li><a href="([^"]+)">([^<]+)</a>\s*\(type:\s*([^)]+)\)</li>`
+	if jobsConfig := jenkinsconfig.Current(); jobsConfig != nil {
+		sectionPattern = jobsConfig.Artifacts.Parsing.RegexPatterns.DeployedArtifactsSection
+		itemPattern = jobsConfig.Artifacts.Parsing.RegexPatterns.ArtifactItem
+	}
+	deployedArtifactsRegex, err := regexp.Compile(sectionPattern)
+	if err != nil {
+		return nil, err
+	}
+	artifactRegex, err := regexp.Compile(itemPattern)
+	if err != nil {
+		return nil, err
+	}
 
 	// Find the deployed artifacts section
 	matches := deployedArtifactsRegex.FindStringSubmatch(htmlContent)
@@ -442,9 +839,9 @@ func (a *ArtifactsServiceImpl) parseParametersFromActions(actions []json.RawMess
 		var actionData struct {
 			Class      string `json:"_class"`
 			Parameters []struct {
-				Name  string `json:"name"`
-				Value string `json:"value"`
-				Class string `json:"_class"`
+				Name  string      `json:"name"`
+				Value interface{} `json:"value"` // boolean parameters are not strings
+				Class string      `json:"_class"`
 			} `json:"parameters"`
 		}
 
@@ -454,9 +851,13 @@ func (a *ArtifactsServiceImpl) parseParametersFromActions(actions []json.RawMess
 
 		if actionData.Class == "hudson.model.ParametersAction" {
 			for _, param := range actionData.Parameters {
+				value := ""
+				if param.Value != nil {
+					value = fmt.Sprintf("%v", param.Value)
+				}
 				parameters = append(parameters, types.JobParameter{
 					Name:  param.Name,
-					Value: param.Value,
+					Value: value,
 					Type:  a.extractParameterType(param.Class),
 				})
 			}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"app/internal/jenkins/errors"
+	"app/internal/jenkins/types"
+)
+
+// maxDiffIntermediateBuilds bounds how many builds between base and target are
+// read to collect commits
+const maxDiffIntermediateBuilds = 50
+
+// versionTokenPattern matches version-like name segments: release numbers,
+// snapshot timestamps and build counters such as "-10.4", "-20240131.101500" or "-3"
+var versionTokenPattern = regexp.MustCompile(`-\d[0-9A-Za-z.]*`)
+
+// DiffBuilds compares two builds: artifacts added, removed or at a different
+// version, parameters that differ, and the commits that went into the target
+// build since the base build
+func (a *ArtifactsServiceImpl) DiffBuilds(ctx context.Context, request *types.BuildDiffRequest) (*types.BuildDiff, error) {
+	if request.BaseBuildURL == "" || request.TargetBuildURL == "" {
+		return nil, errors.NewInvalidParametersError("artifacts", "base_build_url and target_build_url are required", nil)
+	}
+
+	baseURL := a.normalizeBuildURL(request.BaseBuildURL)
+	targetURL := a.normalizeBuildURL(request.TargetBuildURL)
+
+	baseArtifacts, err := a.ExtractArtifacts(ctx, &types.ArtifactExtractionRequest{BuildURL: baseURL})
+	if err != nil {
+		return nil, fmt.Errorf("base build: %w", err)
+	}
+	targetArtifacts, err := a.ExtractArtifacts(ctx, &types.ArtifactExtractionRequest{BuildURL: targetURL})
+	if err != nil {
+		return nil, fmt.Errorf("target build: %w", err)
+	}
+
+	baseInfo, err := a.GetBuildInfo(ctx, baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("base build: %w", err)
+	}
+	targetInfo, err := a.GetBuildInfo(ctx, targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("target build: %w", err)
+	}
+
+	diff := &types.BuildDiff{
+		BaseBuildURL:     baseURL,
+		TargetBuildURL:   targetURL,
+		BaseResult:       baseInfo.Result,
+		TargetResult:     targetInfo.Result,
+		Added:            []types.DeployedArtifact{},
+		Removed:          []types.DeployedArtifact{},
+		Changed:          []types.ArtifactChange{},
+		ParameterChanges: diffParameters(baseInfo.Parameters, targetInfo.Parameters),
+		ComparedAt:       time.Now().UTC(),
+	}
+	a.diffArtifacts(diff, baseArtifacts.Artifacts, targetArtifacts.Artifacts)
+	diff.Commits, diff.CommitsComplete = a.commitsBetween(ctx, baseURL, baseInfo, targetURL, targetInfo)
+
+	return diff, nil
+}
+
+// diffArtifacts matches artifacts by their version-less key and sorts them
+// into added, removed and changed
+func (a *ArtifactsServiceImpl) diffArtifacts(diff *types.BuildDiff, base, target []types.DeployedArtifact) {
+	baseByKey := make(map[string]types.DeployedArtifact, len(base))
+	for _, artifact := range base {
+		baseByKey[artifactKey(artifact)] = artifact
+	}
+
+	seen := make(map[string]bool, len(target))
+	for _, artifact := range target {
+		key := artifactKey(artifact)
+		seen[key] = true
+
+		previous, ok := baseByKey[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, artifact)
+		case previous.URL == artifact.URL && previous.Name == artifact.Name:
+			diff.UnchangedCount++
+		default:
+			diff.Changed = append(diff.Changed, types.ArtifactChange{
+				Key:           key,
+				BaseVersion:   artifactVersion(previous),
+				TargetVersion: artifactVersion(artifact),
+				Base:          previous,
+				Target:        artifact,
+			})
+		}
+	}
+
+	for _, artifact := range base {
+		if !seen[artifactKey(artifact)] {
+			diff.Removed = append(diff.Removed, artifact)
+		}
+	}
+	sortArtifactsByKey(diff.Added)
+	sortArtifactsByKey(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Key < diff.Changed[j].Key })
+}
+
+// sortArtifactsByKey orders artifacts by their version-less key so the diff
+// reads the same between requests
+func sortArtifactsByKey(artifacts []types.DeployedArtifact) {
+	sort.SliceStable(artifacts, func(i, j int) bool { return artifactKey(artifacts[i]) < artifactKey(artifacts[j]) })
+}
+
+// commitsBetween collects the commits of every build after base up to and
+// including target when both belong to the same job; otherwise only the
+// target's own changes are known. It reports whether the list is complete.
+func (a *ArtifactsServiceImpl) commitsBetween(ctx context.Context, baseURL string, baseInfo *types.BuildInfo, targetURL string, targetInfo *types.BuildInfo) ([]types.ChangeSet, bool) {
+	commits := []types.ChangeSet{}
+	jobRoot := buildJobRoot(targetURL)
+	if jobRoot == "" || buildJobRoot(baseURL) != jobRoot || targetInfo.Number <= baseInfo.Number {
+		return append(commits, targetInfo.Changes...), false
+	}
+
+	complete := true
+	first := baseInfo.Number + 1
+	if targetInfo.Number-first >= maxDiffIntermediateBuilds {
+		first = targetInfo.Number - maxDiffIntermediateBuilds + 1
+		complete = false
+	}
+
+	seen := make(map[string]bool)
+	for number := first; number <= targetInfo.Number; number++ {
+		changes := targetInfo.Changes
+		if number != targetInfo.Number {
+			info, err := a.GetBuildInfo(ctx, jobRoot+strconv.Itoa(number)+"/")
+			if err != nil {
+				// Discarded builds leave gaps in the history
+				complete = false
+				continue
+			}
+			changes = info.Changes
+		}
+		for _, change := range changes {
+			if change.CommitID != "" && seen[change.CommitID] {
+				continue
+			}
+			seen[change.CommitID] = true
+			commits = append(commits, change)
+		}
+	}
+	return commits, complete
+}
+
+// diffParameters lists parameters added, removed or changed between two builds
+func diffParameters(base, target []types.JobParameter) []types.ParameterChange {
+	baseValues := make(map[string]string, len(base))
+	for _, parameter := range base {
+		baseValues[parameter.Name] = parameter.Value
+	}
+	targetValues := make(map[string]string, len(target))
+	for _, parameter := range target {
+		targetValues[parameter.Name] = parameter.Value
+	}
+
+	changes := []types.ParameterChange{}
+	for name, targetValue := range targetValues {
+		baseValue, ok := baseValues[name]
+		switch {
+		case !ok:
+			changes = append(changes, types.ParameterChange{Name: name, Change: "added", TargetValue: targetValue})
+		case baseValue != targetValue:
+			changes = append(changes, types.ParameterChange{Name: name, Change: "changed", BaseValue: baseValue, TargetValue: targetValue})
+		}
+	}
+	for name, baseValue := range baseValues {
+		if _, ok := targetValues[name]; !ok {
+			changes = append(changes, types.ParameterChange{Name: name, Change: "removed", BaseValue: baseValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// artifactKey identifies an artifact across builds: its name without version
+// segments, qualified by its Maven repository directory when known
+func artifactKey(artifact types.DeployedArtifact) string {
+	name := artifact.Name
+	if version := artifact.Metadata["version"]; version != "" {
+		name = strings.Replace(name, "-"+version, "", 1)
+	}
+	name = versionTokenPattern.ReplaceAllString(name, "")
+
+	if segments := strings.Split(artifact.Path, "/"); artifact.Repository != "" && len(segments) >= 3 {
+		// group/artifactId/version/file: drop the version and file
+		return strings.Join(segments[:len(segments)-2], "/") + "/" + name
+	}
+	return name
+}
+
+// artifactVersion returns the version of an artifact from its metadata, its
+// repository path, or the version segments of its name
+func artifactVersion(artifact types.DeployedArtifact) string {
+	if version := artifact.Metadata["version"]; version != "" {
+		return version
+	}
+	if segments := strings.Split(artifact.Path, "/"); artifact.Repository != "" && len(segments) >= 3 {
+		version := segments[len(segments)-2]
+		if strings.HasSuffix(version, "-SNAPSHOT") {
+			// Snapshot files carry the timestamped version that actually differs
+			timestamped := snapshotVersion(artifact.Name, segments[len(segments)-3])
+			if timestamped != "" && timestamped != strings.TrimSuffix(version, "-SNAPSHOT") {
+				return timestamped
+			}
+		}
+		return version
+	}
+	tokens := versionTokenPattern.FindAllString(artifact.Name, -1)
+	for i, token := range tokens {
+		tokens[i] = strings.TrimPrefix(token, "-")
+	}
+	return strings.Join(tokens, "-")
+}
+
+// snapshotVersion extracts the timestamped version from a snapshot file name,
+// e.g. "10.4-x-20240101.101010-1" from "att-orchestration-10.4-x-20240101.101010-1-src.zip"
+func snapshotVersion(fileName, artifactID string) string {
+	rest := strings.TrimPrefix(fileName, artifactID+"-")
+	if rest == fileName {
+		return ""
+	}
+	matches := versionTokenPattern.FindAllStringIndex("-"+rest, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return rest[:matches[len(matches)-1][1]-1]
+}
+
+// buildJobRoot returns the job URL of a build URL ending in its number, with a
+// trailing slash, or "" when the URL does not end in a build number
+func buildJobRoot(buildURL string) string {
+	trimmed := strings.TrimSuffix(buildURL, "/")
+	index := strings.LastIndex(trimmed, "/")
+	if index < 0 {
+		return ""
+	}
+	if _, err := strconv.Atoi(trimmed[index+1:]); err != nil {
+		return ""
+	}
+	return trimmed[:index+1]
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"app/internal/jenkins"
+	jenkinsconfig "app/internal/jenkins/config"
+	"app/internal/jenkins/types"
+)
+
+// DefaultConfigWatchInterval is how often Watch checks the jobs file for changes
+const DefaultConfigWatchInterval = 5 * time.Second
+
+// ConfigServiceImpl implements the ConfigService interface over the installed
+// jobs configuration. Reloads validate the new file completely before swapping
+// it in, so a bad edit leaves the running configuration untouched.
+type ConfigServiceImpl struct {
+	pool *jenkins.Pool
+
+	mu         sync.Mutex // serialises reloads
+	listeners  []func(*jenkinsconfig.JobsConfig)
+	watching   bool
+	lastError  string
+	lastReload time.Time
+}
+
+// NewConfigService creates a new config service instance
+func NewConfigService(pool *jenkins.Pool) ConfigService {
+	return &ConfigServiceImpl{pool: pool}
+}
+
+// GetJobConfig retrieves configuration for a specific job
+func (c *ConfigServiceImpl) GetJobConfig(jobName string) (interface{}, error) {
+	return jenkinsconfig.Current().GetJobConfig(jobName)
+}
+
+// ReloadConfig re-reads the jobs configuration from its source and installs it
+// if it is valid. Validation failures are returned as *jenkinsconfig.ValidationError.
+func (c *ConfigServiceImpl) ReloadConfig() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastReload = time.Now().UTC()
+	jobsConfig, source, err := c.readConfig()
+	if err != nil {
+		c.lastError = err.Error()
+		return err
+	}
+
+	jenkinsconfig.Install(jobsConfig, source)
+	c.lastError = ""
+	for _, listener := range c.listeners {
+		listener(jobsConfig)
+	}
+	return nil
+}
+
+// ValidateConfig validates the configuration source without installing it
+func (c *ConfigServiceImpl) ValidateConfig() error {
+	_, _, err := c.readConfig()
+	return err
+}
+
+// GetGlobalConfig retrieves global Jenkins configuration
+func (c *ConfigServiceImpl) GetGlobalConfig() (interface{}, error) {
+	return jenkinsconfig.Current().Global, nil
+}
+
+// Status describes the installed configuration and where it came from
+func (c *ConfigServiceImpl) Status() *types.ConfigStatus {
+	source, loadedAt := jenkinsconfig.Source()
+	jobsConfig := jenkinsconfig.Current()
+
+	jobs := make([]string, 0, len(jobsConfig.Jobs))
+	for jobName := range jobsConfig.Jobs {
+		jobs = append(jobs, jobName)
+	}
+	sort.Strings(jobs)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &types.ConfigStatus{
+		Source:     source,
+		LoadedAt:   loadedAt,
+		Watching:   c.watching,
+		Jobs:       jobs,
+		LastError:  c.lastError,
+		LastReload: c.lastReload,
+	}
+}
+
+// OnReload registers a callback run after each successful reload
+func (c *ConfigServiceImpl) OnReload(fn func(*jenkinsconfig.JobsConfig)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.listeners = append(c.listeners, fn)
+}
+
+// Watch polls the external jobs file and reloads it when its modification
+// time or size changes. It returns immediately when the embedded
+// configuration is in use, since there is no file to watch.
+func (c *ConfigServiceImpl) Watch(ctx context.Context, interval time.Duration) {
+	path := jenkinsconfig.ConfigPath()
+	if path == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultConfigWatchInterval
+	}
+
+	c.setWatching(true)
+	defer c.setWatching(false)
+
+	lastModified, lastSize := fileVersion(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modified, size := fileVersion(path)
+			if modified.Equal(lastModified) && size == lastSize {
+				continue
+			}
+			lastModified, lastSize = modified, size
+			// A failed reload is recorded in Status; the previous configuration stays installed
+			_ = c.ReloadConfig()
+		}
+	}
+}
+
+// readConfig reads and validates the configuration, including the instance
+// references only the pool can check
+func (c *ConfigServiceImpl) readConfig() (*jenkinsconfig.JobsConfig, string, error) {
+	jobsConfig, source, err := jenkinsconfig.ReadConfig()
+	if err != nil {
+		return nil, source, err
+	}
+	if c.pool != nil {
+		if err := c.pool.ValidateJobs(jobsConfig); err != nil {
+			return nil, source, err
+		}
+	}
+	return jobsConfig, source, nil
+}
+
+func (c *ConfigServiceImpl) setWatching(watching bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.watching = watching
+}
+
+// fileVersion returns the modification time and size of a file, or zero values if it cannot be read
+func fileVersion(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
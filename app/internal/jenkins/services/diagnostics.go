@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	jenkinsconfig "app/internal/jenkins/config"
+	"app/internal/jenkins/errors"
+	"app/internal/jenkins/types"
+)
+
+const (
+	defaultDiagnosisContextLines = 5
+	defaultMaxSearchResults      = 200
+)
+
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// FailureCatalog is the compiled form of the diagnostics signatures in jobs.json
+type FailureCatalog struct {
+	signatures       []compiledSignature
+	contextLines     int
+	maxSearchResults int
+}
+
+type compiledSignature struct {
+	jenkinsconfig.FailureSignature
+	patterns []*regexp.Regexp
+}
+
+// NewFailureCatalog compiles the failure signatures from the diagnostics configuration
+func NewFailureCatalog(configuration jenkinsconfig.DiagnosticsConfig) (*FailureCatalog, error) {
+	catalog := &FailureCatalog{
+		contextLines:     configuration.ContextLines,
+		maxSearchResults: configuration.MaxSearchResults,
+	}
+	if catalog.contextLines <= 0 {
+		catalog.contextLines = defaultDiagnosisContextLines
+	}
+	if catalog.maxSearchResults <= 0 {
+		catalog.maxSearchResults = defaultMaxSearchResults
+	}
+
+	for _, signature := range configuration.Signatures {
+		compiled := compiledSignature{FailureSignature: signature}
+		for _, pattern := range signature.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, errors.NewConfigurationError(
+					fmt.Sprintf("invalid pattern for failure signature '%s'", signature.Category), err)
+			}
+			compiled.patterns = append(compiled.patterns, re)
+		}
+		catalog.signatures = append(catalog.signatures, compiled)
+	}
+
+	return catalog, nil
+}
+
+// DiagnosticsServiceImpl implements the DiagnosticsService interface
+type DiagnosticsServiceImpl struct {
+	client    JenkinsClient
+	artifacts ArtifactsService
+	catalog   *FailureCatalog
+}
+
+// NewDiagnosticsService creates a new diagnostics service instance
+func NewDiagnosticsService(client JenkinsClient, artifacts ArtifactsService, catalog *FailureCatalog) DiagnosticsService {
+	return &DiagnosticsServiceImpl{
+		client:    client,
+		artifacts: artifacts,
+		catalog:   catalog,
+	}
+}
+
+// DiagnoseBuild downloads a build's console log and matches it against the failure catalog.
+// Successful builds are only searched, not classified.
+func (d *DiagnosticsServiceImpl) DiagnoseBuild(ctx context.Context, request *types.DiagnosisRequest) (*types.BuildDiagnosis, error) {
+	buildURL := strings.TrimSpace(request.BuildURL)
+	if buildURL == "" {
+		return nil, errors.NewInvalidParametersError("", "build_url is required", nil)
+	}
+
+	buildInfo, err := d.artifacts.GetBuildInfo(ctx, buildURL)
+	if err != nil {
+		return nil, err
+	}
+
+	consoleText, err := d.GetConsoleText(ctx, buildURL)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(ansiEscapePattern.ReplaceAllString(consoleText, ""), "\n")
+
+	diagnosis := &types.BuildDiagnosis{
+		BuildURL:   buildURL,
+		Result:     buildInfo.Result,
+		Building:   buildInfo.Building,
+		TotalLines: len(lines),
+		AnalyzedAt: time.Now().UTC(),
+	}
+
+	if buildInfo.Result != "SUCCESS" {
+		diagnosis.Matches = d.catalog.match(lines)
+		if len(diagnosis.Matches) > 0 {
+			diagnosis.Diagnosed = true
+			diagnosis.Primary = &diagnosis.Matches[0]
+		}
+	}
+
+	if search := strings.TrimSpace(request.Search); search != "" {
+		diagnosis.SearchResults = d.catalog.search(lines, search)
+	}
+
+	return diagnosis, nil
+}
+
+// GetConsoleText retrieves the plain console log of a build
+func (d *DiagnosticsServiceImpl) GetConsoleText(ctx context.Context, buildURL string) (string, error) {
+	consoleURL := strings.TrimSuffix(strings.TrimSuffix(buildURL, "/"), "/api/json") + "/consoleText"
+
+	data, err := d.client.GetWithAuth(ctx, consoleURL)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// match returns one match per signature found in the log, in catalog order
+func (c *FailureCatalog) match(lines []string) []types.FailureMatch {
+	var matches []types.FailureMatch
+	for _, signature := range c.signatures {
+		lastLine := -1
+		occurrences := 0
+		for i, line := range lines {
+			for _, pattern := range signature.patterns {
+				if pattern.MatchString(line) {
+					lastLine = i
+					occurrences++
+					break
+				}
+			}
+		}
+		if lastLine == -1 {
+			continue
+		}
+
+		matches = append(matches, types.FailureMatch{
+			Category:    signature.Category,
+			Description: signature.Description,
+			ErrorType:   signature.ErrorType,
+			Suggestion:  signature.Suggestion,
+			LineNumber:  lastLine + 1,
+			Occurrences: occurrences,
+			Excerpt:     c.excerpt(lines, lastLine),
+		})
+	}
+	return matches
+}
+
+// excerpt returns the lines surrounding index, bounded by the configured context size
+func (c *FailureCatalog) excerpt(lines []string, index int) string {
+	start := index - c.contextLines
+	if start < 0 {
+		start = 0
+	}
+	end := index + c.contextLines + 1
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(lines[start:end], "\n")
+}
+
+// search returns the lines containing text, ignoring case
+func (c *FailureCatalog) search(lines []string, text string) []types.ConsoleLine {
+	needle := strings.ToLower(text)
+	var results []types.ConsoleLine
+	for i, line := range lines {
+		if strings.Contains(strings.ToLower(line), needle) {
+			results = append(results, types.ConsoleLine{Number: i + 1, Text: line})
+			if len(results) >= c.maxSearchResults {
+				break
+			}
+		}
+	}
+	return results
+}
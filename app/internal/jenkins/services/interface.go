@@ -2,8 +2,15 @@ package services
 
 import (
 	"context"
+	"io"
+	"time"
 
+	"app/internal/bitbucket"
+	"app/internal/eks"
+	"app/internal/jenkins"
+	jenkinsconfig "app/internal/jenkins/config"
 	"app/internal/jenkins/types"
+	"app/internal/nexus"
 )
 
 // JenkinsClient defines the interface for Jenkins HTTP operations
@@ -12,7 +19,9 @@ type JenkinsClient interface {
 	Get(ctx context.Context, url string) ([]byte, error)
 	Post(ctx context.Context, url string, data map[string]string) ([]byte, error)
 	GetWithAuth(ctx context.Context, url string) ([]byte, error)
+	OpenWithAuth(ctx context.Context, url string) (io.ReadCloser, error)
 	PostWithAuth(ctx context.Context, url string, data map[string]string) ([]byte, error)
+	TriggerWithAuth(ctx context.Context, url string, data map[string]string) (string, error)
 	
 	// Authentication
 	IsConfigured() bool
@@ -33,8 +42,29 @@ type ScalingService interface {
 	// ValidateScaleRequest validates a scaling request parameters
 	ValidateScaleRequest(request *types.ScaleRequest) (*types.ValidationResult, error)
 	
-	// GetSupportedClusters returns list of clusters that can be scaled (if available)
-	GetSupportedClusters(ctx context.Context) ([]string, error)
+	// GetSupportedClusters returns the clusters that can be scaled from the cluster inventory
+	GetSupportedClusters(ctx context.Context, refresh bool) (*eks.Listing, error)
+	
+	// CheckPolicy returns the scaling policy rules a request would break if sent now
+	CheckPolicy(request *types.ScaleRequest) []types.ScalePolicyViolation
+	
+	// CheckSchedulePolicy returns the policy rules a schedule would break if saved at now
+	CheckSchedulePolicy(schedule *types.ScaleSchedule, now time.Time) []types.ScalePolicyViolation
+	
+	// IsProtected reports whether the policy forbids scaling a cluster down
+	IsProtected(clusterName string) bool
+	
+	// GetScalingHistory returns recent scaling audit entries, newest first
+	GetScalingHistory(clusterName string, limit int) ([]types.ScaleAuditEntry, error)
+	
+	// TriggerBatch scales a list or group of clusters with bounded concurrency
+	TriggerBatch(ctx context.Context, request *types.ScaleBatchRequest) (*types.ScaleBatchResult, error)
+	
+	// GetBatchStatus refreshes the per-cluster job status of a batch
+	GetBatchStatus(ctx context.Context, batchID string) (*types.ScaleBatchResult, error)
+	
+	// GetClusterGroups returns the configured cluster groups with their clusters resolved
+	GetClusterGroups(ctx context.Context) (map[string][]string, error)
 }
 
 // ArtifactsService defines the interface for Jenkins artifacts operations
@@ -53,6 +83,12 @@ type ArtifactsService interface {
 	
 	// FilterArtifacts filters artifacts based on criteria
 	FilterArtifacts(artifacts []types.DeployedArtifact, criteria map[string]interface{}) []types.DeployedArtifact
+	
+	// DownloadArtifacts downloads matching build artifacts into the local cache
+	DownloadArtifacts(ctx context.Context, request *types.ArtifactDownloadRequest) (*types.ArtifactDownloadResponse, error)
+	
+	// DiffBuilds compares the artifacts, parameters and commits of two builds
+	DiffBuilds(ctx context.Context, request *types.BuildDiffRequest) (*types.BuildDiff, error)
 }
 
 // JobService defines the interface for generic Jenkins job operations
@@ -66,6 +102,12 @@ type JobService interface {
 	// GetJobHistory retrieves the build history for a job
 	GetJobHistory(ctx context.Context, jobName string, limit int) ([]*types.JobStatus, error)
 	
+	// QueryJobHistory retrieves a filtered page of builds for a job
+	QueryJobHistory(ctx context.Context, query *types.JobHistoryQuery) (*types.JobHistoryPage, error)
+	
+	// GetJobTrend aggregates success rate and duration for a job over a time window
+	GetJobTrend(ctx context.Context, query *types.JobHistoryQuery) (*types.JobTrend, error)
+	
 	// CancelJob attempts to cancel a running job
 	CancelJob(ctx context.Context, jobURL string) error
 	
@@ -73,6 +115,15 @@ type JobService interface {
 	GetJobHealth(ctx context.Context, jobName string) (*types.JobHealth, error)
 }
 
+// DiagnosticsService defines the interface for classifying Jenkins build failures
+type DiagnosticsService interface {
+	// DiagnoseBuild downloads a build's console log and matches it against the failure catalog
+	DiagnoseBuild(ctx context.Context, request *types.DiagnosisRequest) (*types.BuildDiagnosis, error)
+	
+	// GetConsoleText retrieves the plain console log of a build
+	GetConsoleText(ctx context.Context, buildURL string) (string, error)
+}
+
 // MonitoringService defines the interface for Jenkins monitoring and health checks
 type MonitoringService interface {
 	// HealthCheck performs a health check on Jenkins services
@@ -83,6 +134,9 @@ type MonitoringService interface {
 	
 	// IsJenkinsAvailable checks if Jenkins is available and responsive
 	IsJenkinsAvailable(ctx context.Context) (bool, error)
+	
+	// CheckDependencies checks every external dependency OCD relies on
+	CheckDependencies(ctx context.Context) []types.DependencyStatus
 }
 
 // ConfigService defines the interface for configuration management
@@ -98,6 +152,33 @@ type ConfigService interface {
 	
 	// GetGlobalConfig retrieves global Jenkins configuration
 	GetGlobalConfig() (interface{}, error)
+	
+	// Status describes the installed configuration and where it came from
+	Status() *types.ConfigStatus
+	
+	// OnReload registers a callback run after each successful reload
+	OnReload(fn func(*jenkinsconfig.JobsConfig))
+	
+	// Watch reloads the configuration whenever its file changes, until ctx is done
+	Watch(ctx context.Context, interval time.Duration)
+}
+
+// ScalingScheduler runs recurring scale-up/scale-down schedules stored locally
+type ScalingScheduler interface {
+	// ListSchedules returns every schedule with its next run
+	ListSchedules() []types.ScaleSchedule
+	
+	// GetSchedule returns one schedule
+	GetSchedule(id string) (*types.ScaleSchedule, error)
+	
+	// SaveSchedule validates and creates or replaces a schedule
+	SaveSchedule(schedule *types.ScaleSchedule) (*types.ScaleSchedule, error)
+	
+	// DeleteSchedule removes a schedule
+	DeleteSchedule(id string) error
+	
+	// Run executes due actions every interval until ctx is cancelled
+	Run(ctx context.Context, interval time.Duration)
 }
 
 // LoggingService defines the interface for Jenkins operation logging
@@ -112,35 +193,17 @@ type LoggingService interface {
 	LogMetric(ctx context.Context, metric string, value interface{}, tags map[string]string)
 }
 
-// ServiceFactory defines the interface for creating Jenkins services
-type ServiceFactory interface {
-	// CreateScalingService creates a new scaling service instance
-	CreateScalingService(client JenkinsClient) ScalingService
-	
-	// CreateArtifactsService creates a new artifacts service instance
-	CreateArtifactsService(client JenkinsClient) ArtifactsService
-	
-	// CreateJobService creates a new job service instance
-	CreateJobService(client JenkinsClient) JobService
-	
-	// CreateRNCreationService creates a new RN creation service instance
-	CreateRNCreationService(client JenkinsClient) RNCreationService
-	
-	// CreateMonitoringService creates a new monitoring service instance
-	CreateMonitoringService(client JenkinsClient) MonitoringService
-	
-	// CreateConfigService creates a new config service instance
-	CreateConfigService() ConfigService
-	
-	// CreateLoggingService creates a new logging service instance
-	CreateLoggingService() LoggingService
-}
-
 // ServiceManager defines the interface for managing all Jenkins services
 type ServiceManager interface {
 	// GetScalingService returns the scaling service
 	GetScalingService() ScalingService
 	
+	// GetClusterInventory returns the merged EKS cluster inventory
+	GetClusterInventory() *eks.Inventory
+	
+	// GetScalingScheduler returns the scaling scheduler, nil if its schedules could not be loaded
+	GetScalingScheduler() ScalingScheduler
+	
 	// GetArtifactsService returns the artifacts service
 	GetArtifactsService() ArtifactsService
 	
@@ -159,6 +222,36 @@ type ServiceManager interface {
 	// GetLoggingService returns the logging service
 	GetLoggingService() LoggingService
 	
+	// GetDeliveryClient returns the pooled client of the delivery instance
+	GetDeliveryClient() *jenkins.Client
+	
+	// GetPool returns the shared Jenkins client pool
+	GetPool() *jenkins.Pool
+	
+	// ArtifactsServiceFor returns an artifacts service for the instance serving buildURL
+	ArtifactsServiceFor(buildURL string) (ArtifactsService, error)
+	
+	// DiagnosticsServiceFor returns a diagnostics service for the instance serving buildURL
+	DiagnosticsServiceFor(buildURL string) (DiagnosticsService, error)
+	
+	// GetArtifactCache returns the local artifact cache, nil if it could not be opened
+	GetArtifactCache() *ArtifactCache
+	
+	// GetNexusClient returns the Nexus client
+	GetNexusClient() *nexus.Client
+	
+	// GetBitbucketClient returns the Bitbucket client with the configured default credentials
+	GetBitbucketClient() *bitbucket.Client
+	
+	// GetNexusProxy returns the rewriter from public Nexus URLs to the internal proxy
+	GetNexusProxy() *nexus.ProxyRewriter
+	
+	// WithCredentials scopes Jenkins calls made with the returned context to the given credentials
+	WithCredentials(ctx context.Context, username, token string) context.Context
+	
+	// Go runs background work that is cancelled on Shutdown
+	Go(fn func(ctx context.Context))
+	
 	// Shutdown gracefully shuts down all services
 	Shutdown(ctx context.Context) error
 	
@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"app/internal/config"
+	"app/internal/jenkins"
+	"app/internal/jenkins/errors"
+	"app/internal/jenkins/types"
+)
+
+const (
+	defaultHistoryPageSize = 25
+	maxHistoryPageSize     = 100
+	trendScanPageSize      = 100
+	maxTrendBuilds         = 1000
+	defaultTrendWindow     = 30 * 24 * time.Hour
+)
+
+// historyTreeFields is the build projection requested for history queries
+const historyTreeFields = "number,url,result,building,duration,timestamp," +
+	"actions[parameters[name,value],causes[userId,userName]]"
+
+var queueItemIDPattern = regexp.MustCompile(`/queue/item/(\d+)`)
+
+// JobServiceImpl implements the JobService interface on top of the client pool
+type JobServiceImpl struct {
+	configuration *config.Config
+	pool          *jenkins.Pool
+}
+
+// NewJobService creates a new job service instance
+func NewJobService(configuration *config.Config, pool *jenkins.Pool) JobService {
+	return &JobServiceImpl{
+		configuration: configuration,
+		pool:          pool,
+	}
+}
+
+// jenkinsBuild mirrors the fields of a Jenkins build JSON object used across services
+type jenkinsBuild struct {
+	Number    int    `json:"number"`
+	URL       string `json:"url"`
+	Result    string `json:"result"`
+	Building  bool   `json:"building"`
+	Duration  int64  `json:"duration"`
+	Timestamp int64  `json:"timestamp"`
+	Actions   []struct {
+		Parameters []struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		} `json:"parameters"`
+		Causes []struct {
+			UserID   string `json:"userId"`
+			UserName string `json:"userName"`
+		} `json:"causes"`
+	} `json:"actions"`
+}
+
+// TriggerJob triggers a jobs.json job after applying defaults and validating parameters
+func (s *JobServiceImpl) TriggerJob(ctx context.Context, jobName string, parameters map[string]string) (*types.JobStatus, error) {
+	jobConfig, err := s.pool.Jobs().GetJobConfig(jobName)
+	if err != nil {
+		return nil, errors.NewJobNotFoundError(jobName, "job is not configured", err)
+	}
+
+	params := make(map[string]string, len(parameters))
+	for name, parameter := range jobConfig.Parameters {
+		if parameter.Default != "" {
+			params[name] = parameter.Default
+		}
+	}
+	for name, value := range parameters {
+		params[name] = value
+	}
+	if err := s.pool.Jobs().ValidateJobParameters(jobName, params); err != nil {
+		return nil, errors.NewInvalidParametersError(jobName, err.Error(), err)
+	}
+
+	client, err := s.pool.ForJob(jobName)
+	if err != nil {
+		return nil, err
+	}
+	triggerURL, err := s.pool.Jobs().GetJobURL(client.GetBaseURL(), jobName)
+	if err != nil {
+		return nil, errors.NewConfigurationError("failed to build job URL", err)
+	}
+	rootURL, _ := s.pool.Jobs().GetJobRootURL(client.GetBaseURL(), jobName)
+
+	if _, err := client.PostWithAuth(ctx, triggerURL, params); err != nil {
+		return nil, errors.NewJobExecutionError(jobName, "failed to trigger job", err)
+	}
+
+	return &types.JobStatus{
+		Status:      "queued",
+		URL:         rootURL,
+		Description: fmt.Sprintf("%s triggered", jobName),
+	}, nil
+}
+
+// GetJobStatus retrieves the status of any Jenkins build
+func (s *JobServiceImpl) GetJobStatus(ctx context.Context, jobURL string) (*types.JobStatus, error) {
+	if strings.TrimSpace(jobURL) == "" {
+		return nil, errors.NewInvalidParametersError("", "empty job URL provided", nil)
+	}
+
+	client, err := s.pool.ForURL(jobURL)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := strings.TrimSuffix(jobURL, "/") + "/api/json"
+	data, err := client.GetWithAuth(ctx, apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var build jenkinsBuild
+	if err := json.Unmarshal(data, &build); err != nil {
+		return nil, errors.NewParsingError(apiURL, "failed to parse build status", err)
+	}
+	return build.toJobStatus(), nil
+}
+
+// GetJobHistory retrieves the most recent builds of a jobs.json job
+func (s *JobServiceImpl) GetJobHistory(ctx context.Context, jobName string, limit int) ([]*types.JobStatus, error) {
+	page, err := s.QueryJobHistory(ctx, &types.JobHistoryQuery{JobName: jobName, To: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*types.JobStatus, 0, len(page.Builds))
+	for i := range page.Builds {
+		statuses = append(statuses, &page.Builds[i].JobStatus)
+	}
+	return statuses, nil
+}
+
+// QueryJobHistory returns one page of a job's builds. From and To select the raw
+// Jenkins build range (newest first); filters are applied within that range, so
+// a page may hold fewer builds than requested while NextFrom is still set.
+func (s *JobServiceImpl) QueryJobHistory(ctx context.Context, query *types.JobHistoryQuery) (*types.JobHistoryPage, error) {
+	client, jobURL, err := s.resolveJob(query)
+	if err != nil {
+		return nil, err
+	}
+
+	from := query.From
+	if from < 0 {
+		from = 0
+	}
+	to := query.To
+	if to <= from {
+		to = from + defaultHistoryPageSize
+	}
+	if to-from > maxHistoryPageSize {
+		to = from + maxHistoryPageSize
+	}
+
+	builds, err := s.fetchBuilds(ctx, client, jobURL, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &types.JobHistoryPage{
+		JobURL: jobURL,
+		From:   from,
+		To:     to,
+		Builds: []types.BuildHistoryEntry{},
+	}
+	if len(builds) == to-from {
+		page.NextFrom = to
+	}
+
+	for _, build := range builds {
+		entry := build.toHistoryEntry()
+		if matchesHistoryQuery(&entry, query) {
+			page.Builds = append(page.Builds, entry)
+		}
+	}
+	return page, nil
+}
+
+// GetJobTrend aggregates success rate and duration for the builds of a job that
+// match the query within its time window (the last 30 days by default)
+func (s *JobServiceImpl) GetJobTrend(ctx context.Context, query *types.JobHistoryQuery) (*types.JobTrend, error) {
+	client, jobURL, err := s.resolveJob(query)
+	if err != nil {
+		return nil, err
+	}
+
+	until := time.Now()
+	if query.Until != nil {
+		until = *query.Until
+	}
+	since := until.Add(-defaultTrendWindow)
+	if query.Since != nil {
+		since = *query.Since
+	}
+	if !since.Before(until) {
+		return nil, errors.NewInvalidParametersError(jobURL, "since must be before until", nil)
+	}
+
+	windowed := *query
+	windowed.Since = &since
+	windowed.Until = &until
+
+	trend := &types.JobTrend{
+		JobURL:       jobURL,
+		Since:        since,
+		Until:        until,
+		StatusCounts: make(map[string]int),
+	}
+
+	var totalDuration time.Duration
+	reachedWindowStart := false
+	for from := 0; from < maxTrendBuilds && !reachedWindowStart; from += trendScanPageSize {
+		builds, err := s.fetchBuilds(ctx, client, jobURL, from, from+trendScanPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, build := range builds {
+			entry := build.toHistoryEntry()
+			if entry.StartTime != nil && entry.StartTime.Before(since) {
+				reachedWindowStart = true
+				break
+			}
+			if !matchesHistoryQuery(&entry, &windowed) {
+				continue
+			}
+
+			trend.TotalBuilds++
+			trend.StatusCounts[entry.Status]++
+			if !entry.IsComplete() {
+				continue
+			}
+
+			trend.CompletedBuilds++
+			totalDuration += entry.Duration
+			if entry.IsSuccessful() && trend.LastSuccess == nil {
+				trend.LastSuccess = entry.StartTime
+			}
+			if entry.Status == "failed" && trend.LastFailure == nil {
+				trend.LastFailure = entry.StartTime
+			}
+		}
+
+		if len(builds) < trendScanPageSize {
+			reachedWindowStart = true
+		}
+	}
+	trend.Truncated = !reachedWindowStart
+
+	if trend.CompletedBuilds > 0 {
+		completed := float64(trend.CompletedBuilds)
+		trend.SuccessRate = float64(trend.StatusCounts["success"]) / completed * 100
+		trend.FailureRate = float64(trend.StatusCounts["failed"]) / completed * 100
+		trend.AverageDurationSeconds = totalDuration.Seconds() / completed
+	}
+
+	return trend, nil
+}
+
+// CancelJob cancels a queued item or stops a running build
+func (s *JobServiceImpl) CancelJob(ctx context.Context, jobURL string) error {
+	if strings.TrimSpace(jobURL) == "" {
+		return errors.NewInvalidParametersError("", "empty job URL provided", nil)
+	}
+
+	client, err := s.pool.ForURL(jobURL)
+	if err != nil {
+		return err
+	}
+
+	cancelURL := strings.TrimSuffix(jobURL, "/") + "/stop"
+	if match := queueItemIDPattern.FindStringSubmatch(jobURL); match != nil {
+		root := jobURL[:strings.Index(jobURL, "/queue/item/")]
+		cancelURL = fmt.Sprintf("%s/queue/cancelItem?id=%s", root, match[1])
+	}
+
+	if _, err := client.PostWithAuth(ctx, cancelURL, nil); err != nil {
+		return errors.NewJobExecutionError(jobURL, "failed to cancel job", err)
+	}
+	return nil
+}
+
+// GetJobHealth retrieves the weakest health report of a jobs.json job
+func (s *JobServiceImpl) GetJobHealth(ctx context.Context, jobName string) (*types.JobHealth, error) {
+	client, jobURL, err := s.resolveJob(&types.JobHistoryQuery{JobName: jobName})
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := jobURL + "/api/json?tree=healthReport[score,description,iconUrl]"
+	data, err := client.GetWithAuth(ctx, apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobInfo struct {
+		HealthReport []struct {
+			Score       int    `json:"score"`
+			Description string `json:"description"`
+			IconURL     string `json:"iconUrl"`
+		} `json:"healthReport"`
+	}
+	if err := json.Unmarshal(data, &jobInfo); err != nil {
+		return nil, errors.NewParsingError(apiURL, "failed to parse job health", err)
+	}
+
+	if len(jobInfo.HealthReport) == 0 {
+		return &types.JobHealth{Score: 100, Description: "No health report available"}, nil
+	}
+
+	worst := jobInfo.HealthReport[0]
+	for _, report := range jobInfo.HealthReport[1:] {
+		if report.Score < worst.Score {
+			worst = report
+		}
+	}
+	return &types.JobHealth{
+		Score:       worst.Score,
+		Description: worst.Description,
+		IconURL:     worst.IconURL,
+	}, nil
+}
+
+// Helper methods
+
+// resolveJob returns the pooled client and job URL selected by a history query
+func (s *JobServiceImpl) resolveJob(query *types.JobHistoryQuery) (*jenkins.Client, string, error) {
+	switch {
+	case strings.TrimSpace(query.JobName) != "":
+		client, err := s.pool.ForJob(query.JobName)
+		if err != nil {
+			return nil, "", err
+		}
+		jobURL, err := s.pool.Jobs().GetJobRootURL(client.GetBaseURL(), query.JobName)
+		if err != nil {
+			return nil, "", errors.NewJobNotFoundError(query.JobName, "job is not configured", err)
+		}
+		return client, jobURL, nil
+
+	case strings.TrimSpace(query.Branch) != "":
+		endpoints := config.DefaultEndpoints()
+		if s.configuration != nil {
+			endpoints = s.configuration.Endpoints
+		}
+		jobURL := endpoints.CustomizationBranchJobRoot(strings.TrimSpace(query.Branch))
+		client, err := s.pool.ForURL(jobURL)
+		if err != nil {
+			return nil, "", err
+		}
+		return client, jobURL, nil
+
+	case strings.TrimSpace(query.JobURL) != "":
+		jobURL := strings.TrimSuffix(strings.TrimSpace(query.JobURL), "/")
+		client, err := s.pool.ForURL(jobURL)
+		if err != nil {
+			return nil, "", err
+		}
+		return client, jobURL, nil
+	}
+
+	return nil, "", errors.NewInvalidParametersError("", "one of job_name, branch or job_url is required", nil)
+}
+
+// fetchBuilds requests the builds in the range [from, to) of a job, newest first
+func (s *JobServiceImpl) fetchBuilds(ctx context.Context, client *jenkins.Client, jobURL string, from, to int) ([]jenkinsBuild, error) {
+	tree := fmt.Sprintf("allBuilds[%s]{%d,%d}", historyTreeFields, from, to)
+	apiURL := jobURL + "/api/json?" + url.Values{"tree": {tree}}.Encode()
+
+	data, err := client.GetWithAuth(ctx, apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobInfo struct {
+		AllBuilds []jenkinsBuild `json:"allBuilds"`
+	}
+	if err := json.Unmarshal(data, &jobInfo); err != nil {
+		return nil, errors.NewParsingError(apiURL, "failed to parse build history", err)
+	}
+	return jobInfo.AllBuilds, nil
+}
+
+// matchesHistoryQuery reports whether a build passes the query filters
+func matchesHistoryQuery(entry *types.BuildHistoryEntry, query *types.JobHistoryQuery) bool {
+	if result := strings.ToLower(strings.TrimSpace(query.Result)); result != "" {
+		if result == "failure" {
+			result = "failed"
+		}
+		if entry.Status != result && !strings.EqualFold(entry.Result, result) {
+			return false
+		}
+	}
+
+	if user := strings.TrimSpace(query.User); user != "" &&
+		!strings.EqualFold(entry.TriggeredBy, user) && !strings.EqualFold(entry.TriggeredByName, user) {
+		return false
+	}
+
+	for name, value := range query.Parameters {
+		if actual, ok := entry.Parameters[name]; !ok || !strings.EqualFold(actual, value) {
+			return false
+		}
+	}
+
+	if entry.StartTime != nil {
+		if query.Since != nil && entry.StartTime.Before(*query.Since) {
+			return false
+		}
+		if query.Until != nil && entry.StartTime.After(*query.Until) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// toJobStatus converts a Jenkins build into a JobStatus
+func (b *jenkinsBuild) toJobStatus() *types.JobStatus {
+	// Convert Jenkins status to our status
+	status := "unknown"
+	if b.Building {
+		status = "running"
+	} else if b.Result == "SUCCESS" {
+		status = "success"
+	} else if b.Result == "FAILURE" {
+		status = "failed"
+	} else if b.Result == "ABORTED" {
+		status = "aborted"
+	} else if b.Result == "UNSTABLE" {
+		status = "unstable"
+	}
+
+	// Create description
+	description := fmt.Sprintf("Job #%d", b.Number)
+	if status == "running" {
+		description = fmt.Sprintf("Job #%d is running", b.Number)
+	} else if status == "success" {
+		description = fmt.Sprintf("Job #%d completed successfully", b.Number)
+	} else if status == "failed" {
+		description = fmt.Sprintf("Job #%d failed", b.Number)
+	}
+
+	var startTime, endTime *time.Time
+	if b.Timestamp > 0 {
+		t := time.Unix(b.Timestamp/1000, 0)
+		startTime = &t
+
+		if !b.Building && b.Duration > 0 {
+			et := t.Add(time.Duration(b.Duration) * time.Millisecond)
+			endTime = &et
+		}
+	}
+
+	return &types.JobStatus{
+		Number:      b.Number,
+		Status:      status,
+		URL:         b.URL,
+		Duration:    time.Duration(b.Duration) * time.Millisecond,
+		Description: description,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Result:      b.Result,
+		Building:    b.Building,
+	}
+}
+
+// toHistoryEntry converts a Jenkins build into a history entry with its
+// parameters and the user (or cause) that started it
+func (b *jenkinsBuild) toHistoryEntry() types.BuildHistoryEntry {
+	entry := types.BuildHistoryEntry{JobStatus: *b.toJobStatus()}
+
+	for _, action := range b.Actions {
+		for _, parameter := range action.Parameters {
+			if entry.Parameters == nil {
+				entry.Parameters = make(map[string]string)
+			}
+			if parameter.Value != nil {
+				entry.Parameters[parameter.Name] = fmt.Sprintf("%v", parameter.Value)
+			} else {
+				entry.Parameters[parameter.Name] = ""
+			}
+		}
+		for _, cause := range action.Causes {
+			if entry.TriggeredBy == "" && (cause.UserID != "" || cause.UserName != "") {
+				entry.TriggeredBy = cause.UserID
+				entry.TriggeredByName = cause.UserName
+			}
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"app/internal/logging"
+)
+
+// sensitiveDetailKeys are masked in log output unless log_sensitive_data is enabled
+var sensitiveDetailKeys = []string{"token", "password", "secret", "credential", "authorization"}
+
+// LoggingServiceImpl implements the LoggingService interface on top of the application logger
+type LoggingServiceImpl struct {
+	logger       *logging.Logger
+	logSensitive bool
+}
+
+// NewLoggingService creates a new logging service instance
+func NewLoggingService(logger *logging.Logger, logSensitive bool) LoggingService {
+	if logger == nil {
+		logger = logging.New()
+	}
+	return &LoggingServiceImpl{
+		logger:       logger,
+		logSensitive: logSensitive,
+	}
+}
+
+// LogOperation logs a Jenkins operation with context
+func (l *LoggingServiceImpl) LogOperation(ctx context.Context, operation string, details map[string]interface{}) {
+	l.logger.Infof("jenkins %s%s", operation, l.formatDetails(details))
+}
+
+// LogError logs a Jenkins error with context
+func (l *LoggingServiceImpl) LogError(ctx context.Context, operation string, err error, details map[string]interface{}) {
+	l.logger.Errorf("jenkins %s failed: %v%s", operation, err, l.formatDetails(details))
+}
+
+// LogMetric logs a metric about Jenkins operations
+func (l *LoggingServiceImpl) LogMetric(ctx context.Context, metric string, value interface{}, tags map[string]string) {
+	details := make(map[string]interface{}, len(tags))
+	for key, tag := range tags {
+		details[key] = tag
+	}
+	l.logger.Infof("jenkins metric %s=%v%s", metric, value, l.formatDetails(details))
+}
+
+// formatDetails renders details as sorted key=value pairs, masking sensitive values
+func (l *LoggingServiceImpl) formatDetails(details map[string]interface{}) string {
+	if len(details) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		value := fmt.Sprintf("%v", details[key])
+		if !l.logSensitive && isSensitiveKey(key) {
+			value = "***"
+		}
+		fmt.Fprintf(&builder, " %s=%s", key, value)
+	}
+	return builder.String()
+}
+
+func isSensitiveKey(key string) bool {
+	lowered := strings.ToLower(key)
+	for _, sensitive := range sensitiveDetailKeys {
+		if strings.Contains(lowered, sensitive) {
+			return true
+		}
+	}
+	return false
+}
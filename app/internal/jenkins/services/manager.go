@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"app/internal/bitbucket"
+	"app/internal/config"
+	"app/internal/eks"
+	"app/internal/executor"
+	"app/internal/jenkins"
+	jenkinsconfig "app/internal/jenkins/config"
+	"app/internal/jenkins/types"
+	"app/internal/logging"
+	"app/internal/nexus"
+)
+
+// artifactDownloadTimeout bounds one artifact download from outside Jenkins
+const artifactDownloadTimeout = 10 * time.Minute
+
+// ServiceManagerImpl implements the ServiceManager interface. It builds every
+// Jenkins service once from configuration on top of the shared client pool and
+// owns the background work (watchers, pollers) started through Go.
+type ServiceManagerImpl struct {
+	configuration *config.Config
+	pool          *jenkins.Pool
+
+	deliveryClient *jenkins.Client
+	scaling        ScalingService
+	scheduler      ScalingScheduler
+	clusters       *eks.Inventory
+	artifacts      ArtifactsService // delivery instance
+	artifactCache  *ArtifactCache
+	artifactHTTP   *http.Client
+	nexus          *nexus.Client
+	bitbucket      *bitbucket.Client
+	nexusProxy     *nexus.ProxyRewriter
+	jobs           JobService
+	rnCreation     RNCreationService
+	monitoring     MonitoringService
+	configService  ConfigService
+	logging        LoggingService
+
+	catalogMu      sync.RWMutex
+	failureCatalog *FailureCatalog // recompiled on config reload
+
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	shutdownOnce sync.Once
+}
+
+// NewServiceManager constructs all Jenkins services from configuration
+func NewServiceManager(configuration *config.Config, pool *jenkins.Pool, logger *logging.Logger) (*ServiceManagerImpl, error) {
+	deliveryClient, err := pool.Get(config.JenkinsInstanceDelivery)
+	if err != nil {
+		return nil, err
+	}
+	scalingClient, err := pool.ForJob("scaling")
+	if err != nil {
+		return nil, err
+	}
+	storageClient, err := pool.Get(config.JenkinsInstanceStorage)
+	if err != nil {
+		return nil, err
+	}
+
+	failureCatalog, err := NewFailureCatalog(pool.Jobs().Diagnostics)
+	if err != nil {
+		return nil, err
+	}
+
+	loggingService := NewLoggingService(logger, pool.Jobs().ErrorHandling.LogSensitiveData)
+
+	// A missing cache only disables artifact downloads
+	artifactCache, err := NewArtifactCache(configuration.ArtifactCache.Dir, configuration.ArtifactCache.MaxSizeMB)
+	if err != nil {
+		loggingService.LogError(context.Background(), "artifact cache", err, map[string]interface{}{
+			"dir": configuration.ArtifactCache.Dir,
+		})
+		artifactCache = nil
+	}
+	artifactHTTP := &http.Client{Timeout: artifactDownloadTimeout}
+	if configuration.TLS.InsecureSkipVerify {
+		artifactHTTP.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	nexusClient := nexus.New(nexus.Config{
+		BaseURL:            configuration.Endpoints.NexusBaseURL(),
+		Username:           configuration.Nexus.Username,
+		Password:           configuration.Nexus.Password,
+		InsecureSkipVerify: configuration.TLS.InsecureSkipVerify,
+	})
+	nexusProxy := nexus.NewProxyRewriter(configuration.Endpoints.NexusRepositoryBaseURL, configuration.Endpoints.NexusInternalProxyBaseURL)
+
+	bitbucketClient := bitbucket.New(bitbucket.Config{
+		BaseURL:            configuration.Endpoints.BitbucketBaseURL,
+		ProjectKey:         configuration.Endpoints.BitbucketProjectKey,
+		Username:           configuration.Bitbucket.Username,
+		Token:              configuration.Bitbucket.Token,
+		InsecureSkipVerify: configuration.TLS.InsecureSkipVerify,
+	})
+
+	// AWS knows region, account and status; the scaling job's choices fill in
+	// clusters the local AWS profile cannot see
+	clusterInventory := eks.NewInventory(
+		time.Duration(configuration.EKS.InventoryTTLSeconds)*time.Second,
+		eks.NewAWSCLISource(executor.RunScript, configuration.EKS.Regions),
+		NewScalingClusterSource(configuration, scalingClient),
+	)
+	scalingPolicy, err := NewScalingPolicy(configuration.Scaling)
+	if err != nil {
+		return nil, err
+	}
+	scaling := NewScalingService(configuration, scalingClient, clusterInventory, scalingPolicy, NewScalingAudit(configuration.Scaling.AuditFile))
+	// Unreadable schedules disable scheduled scaling rather than being overwritten
+	scheduler, err := NewScalingScheduler(configuration.Scaling.SchedulesFile, scaling, loggingService)
+	if err != nil {
+		loggingService.LogError(context.Background(), "scaling scheduler", err, map[string]interface{}{
+			"path": configuration.Scaling.SchedulesFile,
+		})
+		scheduler = nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := &ServiceManagerImpl{
+		configuration:  configuration,
+		pool:           pool,
+		deliveryClient: deliveryClient,
+		scaling:        scaling,
+		scheduler:      scheduler,
+		clusters:       clusterInventory,
+		artifacts:      NewArtifactsService(deliveryClient, artifactCache, artifactHTTP),
+		artifactCache:  artifactCache,
+		artifactHTTP:   artifactHTTP,
+		nexus:          nexusClient,
+		bitbucket:      bitbucketClient,
+		nexusProxy:     nexusProxy,
+		jobs:           NewJobService(configuration, pool),
+		rnCreation:     NewRNCreationService(configuration, deliveryClient, storageClient, bitbucketClient),
+		monitoring:     NewMonitoringService(configuration, pool),
+		configService:  NewConfigService(pool),
+		logging:        loggingService,
+		failureCatalog: failureCatalog,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+
+	manager.configService.OnReload(manager.reloadFailureCatalog)
+	manager.Go(func(ctx context.Context) {
+		manager.configService.Watch(ctx, DefaultConfigWatchInterval)
+	})
+	if scheduler != nil {
+		manager.Go(func(ctx context.Context) {
+			scheduler.Run(ctx, DefaultSchedulerInterval)
+		})
+	}
+	return manager, nil
+}
+
+// reloadFailureCatalog recompiles the failure signatures after a config reload.
+// The signatures were validated before install, so a compile error here only
+// keeps the previous catalog.
+func (m *ServiceManagerImpl) reloadFailureCatalog(jobsConfig *jenkinsconfig.JobsConfig) {
+	failureCatalog, err := NewFailureCatalog(jobsConfig.Diagnostics)
+	if err != nil {
+		return
+	}
+
+	m.catalogMu.Lock()
+	defer m.catalogMu.Unlock()
+
+	m.failureCatalog = failureCatalog
+}
+
+// GetScalingService returns the scaling service
+func (m *ServiceManagerImpl) GetScalingService() ScalingService { return m.scaling }
+
+// GetClusterInventory returns the merged EKS cluster inventory
+func (m *ServiceManagerImpl) GetClusterInventory() *eks.Inventory { return m.clusters }
+
+// GetScalingScheduler returns the scaling scheduler, nil if its schedules could not be loaded
+func (m *ServiceManagerImpl) GetScalingScheduler() ScalingScheduler { return m.scheduler }
+
+// GetArtifactsService returns the artifacts service of the delivery instance
+func (m *ServiceManagerImpl) GetArtifactsService() ArtifactsService { return m.artifacts }
+
+// GetJobService returns the job service
+func (m *ServiceManagerImpl) GetJobService() JobService { return m.jobs }
+
+// GetRNCreationService returns the RN creation service
+func (m *ServiceManagerImpl) GetRNCreationService() RNCreationService { return m.rnCreation }
+
+// GetMonitoringService returns the monitoring service
+func (m *ServiceManagerImpl) GetMonitoringService() MonitoringService { return m.monitoring }
+
+// GetConfigService returns the config service
+func (m *ServiceManagerImpl) GetConfigService() ConfigService { return m.configService }
+
+// GetLoggingService returns the logging service
+func (m *ServiceManagerImpl) GetLoggingService() LoggingService { return m.logging }
+
+// GetDeliveryClient returns the pooled client of the delivery instance
+func (m *ServiceManagerImpl) GetDeliveryClient() *jenkins.Client { return m.deliveryClient }
+
+// GetPool returns the shared Jenkins client pool
+func (m *ServiceManagerImpl) GetPool() *jenkins.Pool { return m.pool }
+
+// ArtifactsServiceFor returns an artifacts service using the pooled client of
+// the Jenkins instance that serves buildURL
+func (m *ServiceManagerImpl) ArtifactsServiceFor(buildURL string) (ArtifactsService, error) {
+	client, err := m.pool.ForURL(buildURL)
+	if err != nil {
+		return nil, err
+	}
+	if client == m.deliveryClient {
+		return m.artifacts, nil
+	}
+	return NewArtifactsService(client, m.artifactCache, m.artifactHTTP), nil
+}
+
+// DiagnosticsServiceFor returns a diagnostics service using the pooled client of
+// the Jenkins instance that serves buildURL
+func (m *ServiceManagerImpl) DiagnosticsServiceFor(buildURL string) (DiagnosticsService, error) {
+	client, err := m.pool.ForURL(buildURL)
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := m.ArtifactsServiceFor(buildURL)
+	if err != nil {
+		return nil, err
+	}
+	m.catalogMu.RLock()
+	failureCatalog := m.failureCatalog
+	m.catalogMu.RUnlock()
+
+	return NewDiagnosticsService(client, artifacts, failureCatalog), nil
+}
+
+// GetArtifactCache returns the local artifact cache, nil if it could not be opened
+func (m *ServiceManagerImpl) GetArtifactCache() *ArtifactCache { return m.artifactCache }
+
+// GetNexusClient returns the Nexus client
+func (m *ServiceManagerImpl) GetNexusClient() *nexus.Client { return m.nexus }
+
+// GetBitbucketClient returns the Bitbucket client with the configured default credentials
+func (m *ServiceManagerImpl) GetBitbucketClient() *bitbucket.Client { return m.bitbucket }
+
+// GetNexusProxy returns the rewriter from public Nexus URLs to the internal proxy
+func (m *ServiceManagerImpl) GetNexusProxy() *nexus.ProxyRewriter { return m.nexusProxy }
+
+// WithCredentials scopes Jenkins calls made with the returned context to the
+// given credentials. Blank credentials leave the context unchanged so the
+// instance defaults apply.
+func (m *ServiceManagerImpl) WithCredentials(ctx context.Context, username, token string) context.Context {
+	username = strings.TrimSpace(username)
+	token = strings.TrimSpace(token)
+	if username == "" || token == "" {
+		return ctx
+	}
+	return jenkins.WithCredentials(ctx, username, token)
+}
+
+// Go runs fn in the background with a context that is cancelled on Shutdown
+func (m *ServiceManagerImpl) Go(fn func(ctx context.Context)) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		fn(m.ctx)
+	}()
+}
+
+// Shutdown cancels background work and waits for it to finish, or for ctx to expire
+func (m *ServiceManagerImpl) Shutdown(ctx context.Context) error {
+	m.shutdownOnce.Do(m.cancel)
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		m.pool.Close()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HealthCheck reports one status per dependency from the monitoring service
+func (m *ServiceManagerImpl) HealthCheck(ctx context.Context) map[string]*types.ServiceStatus {
+	statuses := make(map[string]*types.ServiceStatus)
+	for _, dependency := range m.monitoring.CheckDependencies(ctx) {
+		details := dependency.Details
+		if dependency.Error != "" {
+			details = dependency.Error
+		}
+		statuses[dependency.Name] = &types.ServiceStatus{
+			Service:     dependency.Name,
+			Status:      dependency.Status,
+			LastChecked: dependency.LastChecked,
+			Details:     details,
+			Metrics: map[string]interface{}{
+				"latency_ms": dependency.LatencyMS,
+			},
+		}
+	}
+	return statuses
+}
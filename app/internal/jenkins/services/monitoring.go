@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"app/internal/config"
+	"app/internal/jenkins"
+	"app/internal/jenkins/types"
+)
+
+const dependencyCheckTimeout = 10 * time.Second
+
+// Dependency status values
+const (
+	DependencyHealthy   = "healthy"
+	DependencyUnhealthy = "unhealthy"
+	DependencySkipped   = "skipped"
+)
+
+// dependencyCheck is one probe of an external dependency. A skipped check
+// does not apply to this machine, e.g. WSL on Linux.
+type dependencyCheck struct {
+	name string
+	kind string
+	run  func(ctx context.Context) (details string, skipped bool, err error)
+}
+
+// MonitoringServiceImpl implements the MonitoringService interface
+type MonitoringServiceImpl struct {
+	configuration *config.Config
+	pool          *jenkins.Pool
+	httpClient    *http.Client
+
+	mu          sync.Mutex
+	lastSuccess map[string]time.Time
+	lastResults []types.DependencyStatus
+	lastChecked time.Time
+	checksRun   int
+}
+
+// NewMonitoringService creates a new monitoring service instance
+func NewMonitoringService(configuration *config.Config, pool *jenkins.Pool) MonitoringService {
+	httpClient := &http.Client{Timeout: dependencyCheckTimeout}
+	if configuration != nil && configuration.TLS.InsecureSkipVerify {
+		httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	return &MonitoringServiceImpl{
+		configuration: configuration,
+		pool:          pool,
+		httpClient:    httpClient,
+		lastSuccess:   make(map[string]time.Time),
+	}
+}
+
+// HealthCheck summarises the dependency checks into a single service status
+func (m *MonitoringServiceImpl) HealthCheck(ctx context.Context) (*types.ServiceStatus, error) {
+	results := m.CheckDependencies(ctx)
+
+	healthy, unhealthy, jenkinsUp := 0, 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case DependencyHealthy:
+			healthy++
+			if result.Kind == "jenkins" {
+				jenkinsUp++
+			}
+		case DependencyUnhealthy:
+			unhealthy++
+		}
+	}
+
+	status := "healthy"
+	if unhealthy > 0 {
+		status = "degraded"
+	}
+	if jenkinsUp == 0 && len(m.pool.Names()) > 0 {
+		status = "unhealthy"
+	}
+
+	return &types.ServiceStatus{
+		Service:     "monitoring",
+		Status:      status,
+		LastChecked: time.Now().UTC(),
+		Details:     fmt.Sprintf("%d of %d dependencies healthy", healthy, healthy+unhealthy),
+		Metrics: map[string]interface{}{
+			"healthy":      healthy,
+			"unhealthy":    unhealthy,
+			"dependencies": results,
+		},
+	}, nil
+}
+
+// GetServiceMetrics returns counters from the most recent dependency checks
+func (m *MonitoringServiceImpl) GetServiceMetrics(ctx context.Context) (map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unhealthy := 0
+	for _, result := range m.lastResults {
+		if result.Status == DependencyUnhealthy {
+			unhealthy++
+		}
+	}
+
+	metrics := map[string]interface{}{
+		"checks_run":       m.checksRun,
+		"dependencies":     len(m.lastResults),
+		"unhealthy":        unhealthy,
+		"jenkins_breakers": jenkins.BreakerStates(),
+	}
+	if !m.lastChecked.IsZero() {
+		metrics["last_checked"] = m.lastChecked
+	}
+	return metrics, nil
+}
+
+// IsJenkinsAvailable checks if the delivery Jenkins is available and responsive
+func (m *MonitoringServiceImpl) IsJenkinsAvailable(ctx context.Context) (bool, error) {
+	client, err := m.pool.Get(config.JenkinsInstanceDelivery)
+	if err != nil {
+		return false, err
+	}
+	if err := client.Health(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CheckDependencies runs every dependency check concurrently and returns the
+// results in a stable order
+func (m *MonitoringServiceImpl) CheckDependencies(ctx context.Context) []types.DependencyStatus {
+	checks := m.dependencyChecks()
+	results := make([]types.DependencyStatus, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check dependencyCheck) {
+			defer wg.Done()
+			results[i] = m.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	m.lastResults = results
+	m.lastChecked = time.Now().UTC()
+	m.checksRun++
+	m.mu.Unlock()
+
+	return results
+}
+
+// runCheck runs a single check with a timeout and records its latency
+func (m *MonitoringServiceImpl) runCheck(ctx context.Context, check dependencyCheck) types.DependencyStatus {
+	checkCtx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
+
+	started := time.Now()
+	details, skipped, err := check.run(checkCtx)
+	result := types.DependencyStatus{
+		Name:        check.name,
+		Kind:        check.kind,
+		LatencyMS:   time.Since(started).Milliseconds(),
+		LastChecked: started.UTC(),
+		Details:     details,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case skipped:
+		result.Status = DependencySkipped
+	case err != nil:
+		result.Status = DependencyUnhealthy
+		result.Error = err.Error()
+	default:
+		result.Status = DependencyHealthy
+		m.lastSuccess[check.name] = result.LastChecked
+	}
+	if lastSuccess, ok := m.lastSuccess[check.name]; ok {
+		result.LastSuccess = &lastSuccess
+	}
+	return result
+}
+
+// dependencyChecks lists the checks for this machine and configuration
+func (m *MonitoringServiceImpl) dependencyChecks() []dependencyCheck {
+	endpoints := config.DefaultEndpoints()
+	if m.configuration != nil {
+		endpoints = m.configuration.Endpoints
+	}
+
+	var checks []dependencyCheck
+	for _, name := range m.pool.Names() {
+		checks = append(checks, m.jenkinsCheck(name))
+	}
+
+	checks = append(checks,
+		m.httpCheck("bitbucket", strings.TrimRight(endpoints.BitbucketBaseURL, "/")+"/status"),
+		m.httpCheck("nexus", endpoints.NexusBaseURL()+"/service/rest/v1/status"),
+	)
+
+	for _, binary := range []string{"kubectl", "aws", "helm"} {
+		checks = append(checks, m.binaryCheck(binary))
+	}
+
+	checks = append(checks, m.wslCheck(), mavenSettingsCheck())
+	return checks
+}
+
+// jenkinsCheck probes a pooled Jenkins instance through Client.Health
+func (m *MonitoringServiceImpl) jenkinsCheck(name string) dependencyCheck {
+	return dependencyCheck{
+		name: "jenkins:" + name,
+		kind: "jenkins",
+		run: func(ctx context.Context) (string, bool, error) {
+			client, err := m.pool.Get(name)
+			if err != nil {
+				return "", false, err
+			}
+			return client.GetBaseURL(), false, client.Health(ctx)
+		},
+	}
+}
+
+// httpCheck reports whether a URL answers at all; only 5xx responses count as unhealthy
+func (m *MonitoringServiceImpl) httpCheck(name, target string) dependencyCheck {
+	return dependencyCheck{
+		name: name,
+		kind: "http",
+		run: func(ctx context.Context) (string, bool, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+			if err != nil {
+				return target, false, err
+			}
+			resp, err := m.httpClient.Do(req)
+			if err != nil {
+				return target, false, err
+			}
+			resp.Body.Close()
+
+			details := fmt.Sprintf("%s (HTTP %d)", target, resp.StatusCode)
+			if resp.StatusCode >= 500 {
+				return details, false, fmt.Errorf("server error: HTTP %d", resp.StatusCode)
+			}
+			return details, false, nil
+		},
+	}
+}
+
+// binaryCheck looks a binary up in the login shell the deploy scripts run in
+func (m *MonitoringServiceImpl) binaryCheck(binary string) dependencyCheck {
+	return dependencyCheck{
+		name: binary,
+		kind: "binary",
+		run: func(ctx context.Context) (string, bool, error) {
+			output, err := m.runLoginShell(ctx, "command -v "+binary)
+			if err != nil {
+				return "", false, fmt.Errorf("%s not found on PATH", binary)
+			}
+			return output, false, nil
+		},
+	}
+}
+
+// wslCheck verifies that the configured WSL user can start a shell (Windows only)
+func (m *MonitoringServiceImpl) wslCheck() dependencyCheck {
+	return dependencyCheck{
+		name: "wsl",
+		kind: "wsl",
+		run: func(ctx context.Context) (string, bool, error) {
+			if runtime.GOOS != "windows" {
+				return "not required on " + runtime.GOOS, true, nil
+			}
+			if _, err := exec.LookPath("wsl"); err != nil {
+				return "", false, fmt.Errorf("WSL is not installed")
+			}
+			output, err := m.runLoginShell(ctx, "uname -r")
+			if err != nil {
+				return "", false, err
+			}
+			return output, false, nil
+		},
+	}
+}
+
+// mavenSettingsCheck verifies that the Maven settings.xml the build scripts expect exists
+func mavenSettingsCheck() dependencyCheck {
+	return dependencyCheck{
+		name: "maven-settings",
+		kind: "file",
+		run: func(ctx context.Context) (string, bool, error) {
+			path := os.Getenv("MAVEN_SETTINGS_PATH")
+			if path == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return "", false, err
+				}
+				path = filepath.Join(home, ".m2", "settings.xml")
+			}
+			if _, err := os.Stat(path); err != nil {
+				return path, false, fmt.Errorf("Maven settings.xml not found at %s", path)
+			}
+			return path, false, nil
+		},
+	}
+}
+
+// runLoginShell runs a command in a login shell, inside WSL on Windows, and
+// returns its trimmed output
+func (m *MonitoringServiceImpl) runLoginShell(ctx context.Context, command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		wslUser := "k8s"
+		if m.configuration != nil && m.configuration.WSLUser != "" {
+			wslUser = m.configuration.WSLUser
+		}
+		cmd = exec.CommandContext(ctx, "wsl", "--user", wslUser, "bash", "-l", "-c", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "bash", "-l", "-c", command)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -2,14 +2,9 @@ package services
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"net/http/cookiejar"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,9 +12,12 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"app/internal/bitbucket"
 	"app/internal/config"
+	"app/internal/jenkins"
 	"app/internal/jenkins/types"
 	ocdscripts "deploy-scripts"
 )
@@ -39,23 +37,30 @@ const (
 // RNCreationServiceImpl implements the RNCreationService interface
 type RNCreationServiceImpl struct {
 	configuration *config.Config
-	client        JenkinsClient
+	client        JenkinsClient // delivery Jenkins (customization jobs)
+	storageClient JenkinsClient // storage Jenkins (ATT_Storage_Creation)
+	bitbucket     *bitbucket.Client
 }
 
 // NewRNCreationService creates a new RN Creation service instance
-func NewRNCreationService(configuration *config.Config, client JenkinsClient) RNCreationService {
+func NewRNCreationService(configuration *config.Config, client, storageClient JenkinsClient, bitbucketClient *bitbucket.Client) RNCreationService {
 	return &RNCreationServiceImpl{
 		configuration: configuration,
 		client:        client,
+		storageClient: storageClient,
+		bitbucket:     bitbucketClient,
 	}
 }
 
 func (s *RNCreationServiceImpl) storageJobURL(parts ...string) string {
-	if s.configuration == nil {
-		defaults := config.DefaultEndpoints()
-		return defaults.StorageJobURL(parts...)
+	endpoints := config.DefaultEndpoints()
+	if s.configuration != nil {
+		endpoints = s.configuration.Endpoints
+	}
+	if s.storageClient != nil {
+		endpoints.StorageJenkinsBaseURL = s.storageClient.GetBaseURL()
 	}
-	return s.configuration.Endpoints.StorageJobURL(parts...)
+	return endpoints.StorageJobURL(parts...)
 }
 
 func (s *RNCreationServiceImpl) customizationBaseURL() string {
@@ -69,23 +74,13 @@ func (s *RNCreationServiceImpl) customizationBaseURL() string {
 	return strings.TrimRight(base, "/")
 }
 
-func (s *RNCreationServiceImpl) bitbucketBaseURL() string {
-	base := config.DefaultEndpoints().BitbucketBaseURL
+func (s *RNCreationServiceImpl) customizationBranchJobRoot(branch string) string {
+	endpoints := config.DefaultEndpoints()
 	if s.configuration != nil {
-		configured := strings.TrimSpace(s.configuration.Endpoints.BitbucketBaseURL)
-		if configured != "" {
-			base = configured
-		}
+		endpoints = s.configuration.Endpoints
 	}
-	return strings.TrimRight(base, "/")
-}
-
-func (s *RNCreationServiceImpl) httpClient(timeout time.Duration) *http.Client {
-	client := &http.Client{Timeout: timeout}
-	if s.configuration != nil && s.configuration.TLS.InsecureSkipVerify {
-		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
-	}
-	return client
+	endpoints.CustomizationJenkinsBaseURL = s.customizationBaseURL()
+	return endpoints.CustomizationBranchJobRoot(branch)
 }
 
 // TriggerStorageCreation triggers the ATT_Storage_Creation Jenkins job
@@ -110,10 +105,9 @@ func (s *RNCreationServiceImpl) TriggerStorageCreation(ctx context.Context, requ
 		"layering":            request.Layering,
 	}
 
-	// Make POST request to trigger job using direct HTTP client
-	// since this Jenkins server is different from the configured one
-	// For the original method, we cannot access credentials, so this will likely fail
-	// Users should use TriggerStorageCreationWithCredentials instead
+	// Make POST request to trigger job with the storage instance's configured credentials.
+	// By default that instance takes credentials from the request only, so callers
+	// should use TriggerStorageCreationWithCredentials.
 	err := s.makeStorageCreationRequestWithAuth(ctx, jobURL, params, "", "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to trigger storage creation job: %w", err)
@@ -138,13 +132,9 @@ func (s *RNCreationServiceImpl) GetLatestCustomizationJob(ctx context.Context, b
 		return nil, fmt.Errorf("branch name cannot be empty")
 	}
 
-	// Encode branch name for URL - Jenkins job names with slashes need %252F encoding
-	encodedBranch := strings.ReplaceAll(branch, "/", "%252F")
-
 	// Jenkins delivery customization job URL with build details
 	// Use tree parameter to get build results in one call
-	base := s.customizationBaseURL()
-	jobURL := fmt.Sprintf("%s/job/Delivery/job/ATT_OSO/job/customization/job/%s/api/json?tree=builds[number,url,result,timestamp,building]", base, encodedBranch)
+	jobURL := s.customizationBranchJobRoot(branch) + "/api/json?tree=builds[number,url,result,timestamp,building]"
 
 	// Get job information
 	data, err := s.client.GetWithAuth(ctx, jobURL)
@@ -272,68 +262,15 @@ func (s *RNCreationServiceImpl) GetOniImageFromBitbucket(ctx context.Context, br
 		return "", fmt.Errorf("Bitbucket credentials (username and token) are required")
 	}
 
-	client := s.httpClient(30 * time.Second)
-
-	// Bitbucket API URL for commits
-	base := s.bitbucketBaseURL()
-	project := ""
-	if s.configuration != nil {
-		project = s.configuration.Endpoints.BitbucketProjectKey
-	}
-	if project == "" {
-		project = "ATTSVO"
-	}
-	url := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/commits?until=%s&limit=50", base, project, repoName, branch)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	commits, err := s.bitbucket.WithCredentials(username, token).Commits(ctx, repoName, bitbucket.CommitQuery{Until: branch, Max: 50})
 	if err != nil {
-		return "", fmt.Errorf("failed to create Bitbucket request: %w", err)
-	}
-
-	// Set basic auth
-	req.SetBasicAuth(username, token)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to make Bitbucket request for branch '%s': %w", branch, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		if resp.StatusCode == 401 {
-			return "", fmt.Errorf("authentication failed for Bitbucket (status %d). Please check your credentials", resp.StatusCode)
-		}
-		if resp.StatusCode == 404 {
-			return "", fmt.Errorf("repository or branch not found (status %d): %s/%s", resp.StatusCode, repoName, branch)
+		if bitbucket.IsNotFound(err) {
+			return "", fmt.Errorf("repository or branch not found (status 404): %s/%s", repoName, branch)
 		}
-		return "", fmt.Errorf("Bitbucket API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("failed to fetch Bitbucket commits for branch '%s': %w", branch, err)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read Bitbucket response: %w", err)
-	}
-
-	if len(body) == 0 {
-		return "", fmt.Errorf("empty response from Bitbucket for branch '%s'", branch)
-	}
-
-	// Parse commits response
-	var commitsResp struct {
-		Values []struct {
-			Author struct {
-				DisplayName string `json:"displayName"`
-			} `json:"author"`
-			Message string `json:"message"`
-		} `json:"values"`
-	}
-
-	if err := json.Unmarshal(body, &commitsResp); err != nil {
-		return "", fmt.Errorf("failed to parse Bitbucket commits response: %w", err)
-	}
-
-	if len(commitsResp.Values) == 0 {
+	if len(commits) == 0 {
 		return "", fmt.Errorf("no commits found for branch '%s' in repository '%s'", branch, repoName)
 	}
 
@@ -341,7 +278,7 @@ func (s *RNCreationServiceImpl) GetOniImageFromBitbucket(ctx context.Context, br
 	re := regexp.MustCompile(`update oni_docker_version with value\s*(.+)`)
 	jenkinsCommitCount := 0
 
-	for _, commit := range commitsResp.Values {
+	for _, commit := range commits {
 		if strings.ToLower(commit.Author.DisplayName) == "jenkins" {
 			jenkinsCommitCount++
 			matches := re.FindStringSubmatch(commit.Message)
@@ -508,110 +445,21 @@ func (s *RNCreationServiceImpl) TriggerStorageCreationWithCredentials(ctx contex
 	}, nil
 }
 
-// makeStorageCreationRequestWithAuth makes a direct HTTP request to the storage creation Jenkins server with auth
+// makeStorageCreationRequestWithAuth posts to the storage creation Jenkins server.
+// The pooled storage client handles the CSRF crumb and session cookies for that instance.
 func (s *RNCreationServiceImpl) makeStorageCreationRequestWithAuth(ctx context.Context, jobURL string, params map[string]string, username, token string) error {
-	// Create HTTP client with TLS config and cookie jar for session management
-	jar, _ := cookiejar.New(nil)
-	client := s.httpClient(30 * time.Second)
-	client.Jar = jar // Enable cookie support for session management
-
-	// Get crumb token first for CSRF protection (this will establish session)
-	crumb, crumbField, err := s.getCrumbToken(ctx, client, username, token)
-	if err != nil {
-		return fmt.Errorf("failed to get crumb token: %w", err)
-	}
-
-	// Build form data
-	data := url.Values{}
-	for key, value := range params {
-		data.Set(key, value)
-	}
-
-	// Create POST request
-	req, err := http.NewRequestWithContext(ctx, "POST", jobURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	// Add CSRF crumb if available
-	if crumb != "" && crumbField != "" {
-		req.Header.Set(crumbField, crumb)
-	}
-
-	// Use explicit Jenkins credentials
+	// Use explicit Jenkins credentials when provided
 	if username != "" && token != "" {
-		req.SetBasicAuth(username, token)
+		ctx = jenkins.WithCredentials(ctx, username, token)
 	}
 
-	// Make the request (cookies from crumb request will be automatically included)
-	resp, err := client.Do(req)
-	if err != nil {
+	if _, err := s.storageClient.PostWithAuth(ctx, jobURL, params); err != nil {
 		return fmt.Errorf("failed to make request to storage creation Jenkins: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("storage creation request failed with status %d: %s", resp.StatusCode, string(body))
-	}
 
 	return nil
 }
 
-// getCrumbToken retrieves the CSRF crumb token from Jenkins
-func (s *RNCreationServiceImpl) getCrumbToken(ctx context.Context, client *http.Client, username, token string) (string, string, error) {
-	// Get the base URL from the job URL
-	baseURL := config.DefaultEndpoints().StorageJenkinsBaseURL
-	if s.configuration != nil {
-		configured := strings.TrimSpace(s.configuration.Endpoints.StorageJenkinsBaseURL)
-		if configured != "" {
-			baseURL = configured
-		}
-	}
-	baseURL = strings.TrimRight(baseURL, "/")
-	crumbURL := baseURL + "/crumbIssuer/api/json"
-
-	req, err := http.NewRequestWithContext(ctx, "GET", crumbURL, nil)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to create crumb request: %w", err)
-	}
-
-	// Set authentication
-	if username != "" && token != "" {
-		req.SetBasicAuth(username, token)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to get crumb: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", "", fmt.Errorf("failed to get crumb, status %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to read crumb response: %w", err)
-	}
-
-	var crumbResp struct {
-		Crumb             string `json:"crumb"`
-		CrumbRequestField string `json:"crumbRequestField"`
-	}
-
-	if err := json.Unmarshal(body, &crumbResp); err != nil {
-		return "", "", fmt.Errorf("failed to parse crumb response: %w", err)
-	}
-
-	return crumbResp.Crumb, crumbResp.CrumbRequestField, nil
-}
-
 // GetCorePatchCharts executes kubectl commands on EKS cluster to get helm charts info
 func (s *RNCreationServiceImpl) GetCorePatchCharts(ctx context.Context, clusterName string) ([]types.CorePatchInfo, error) {
 	startTime := time.Now()
@@ -921,7 +769,7 @@ type ColumnData struct {
 	Duration time.Duration
 }
 
-// GenerateRNTableData generates the complete data structure for RN table using safe sequential execution
+// GenerateRNTableData generates the complete data structure for RN table, fetching the cluster data in parallel
 func (s *RNCreationServiceImpl) GenerateRNTableData(ctx context.Context, request *types.RNTableRequest) (*types.RNTableData, error) {
 	overallStart := time.Now()
 	log.Printf("[TIMING] GenerateRNTableData started")
@@ -939,34 +787,58 @@ func (s *RNCreationServiceImpl) GenerateRNTableData(ctx context.Context, request
 	}
 	log.Printf("[TIMING] Get EKS cluster name: %v", time.Since(clusterStart))
 
-	// Step 2: Get TLC version (no kubectl dependency)
-	tlcStart := time.Now()
-	tlcVersion, err := s.GetTLCVersionFromJob(ctx, request.CustomizationJobURL)
-	if err != nil {
-		log.Printf("ERROR: Failed to get TLC version: %v", err)
-		tlcVersion = "[TLC version unavailable]"
-	}
-	log.Printf("[TIMING] Get TLC version: %v", time.Since(tlcStart))
+	// Steps 2-4 run in parallel: each cluster query connects through its own
+	// temporary kubeconfig, so the helm and kubectl scripts cannot clash
+	var (
+		wg                                            sync.WaitGroup
+		tlcVersion, corePatchCharts                   string
+		attImage, guidedTaskImage, customizationImage string
+	)
+	wg.Add(3)
 
-	// Step 3: Get core patch charts (uses kubectl - must be sequential)
-	coreStart := time.Now()
-	corePatchCharts, err := s.populateCorePatchChartsColumn(ctx, clusterName)
-	if err != nil {
-		log.Printf("ERROR: Failed to get core patch charts: %v", err)
-		corePatchCharts = fmt.Sprintf("[Error connecting to cluster '%s']", clusterName)
-	}
-	log.Printf("[TIMING] Get core patch charts: %v", time.Since(coreStart))
+	// Step 2: Get TLC version (no kubectl dependency)
+	go func() {
+		defer wg.Done()
+		tlcStart := time.Now()
+		var err error
+		tlcVersion, err = s.GetTLCVersionFromJob(ctx, request.CustomizationJobURL)
+		if err != nil {
+			log.Printf("ERROR: Failed to get TLC version: %v", err)
+			tlcVersion = "[TLC version unavailable]"
+		}
+		log.Printf("[TIMING] Get TLC version: %v", time.Since(tlcStart))
+	}()
+
+	// Step 3: Get core patch charts (uses helm)
+	go func() {
+		defer wg.Done()
+		coreStart := time.Now()
+		var err error
+		corePatchCharts, err = s.populateCorePatchChartsColumn(ctx, clusterName)
+		if err != nil {
+			log.Printf("ERROR: Failed to get core patch charts: %v", err)
+			corePatchCharts = fmt.Sprintf("[Error connecting to cluster '%s']", clusterName)
+		}
+		log.Printf("[TIMING] Get core patch charts: %v", time.Since(coreStart))
+	}()
+
+	// Step 4: Get image versions (uses kubectl)
+	go func() {
+		defer wg.Done()
+		imageStart := time.Now()
+		var err error
+		attImage, guidedTaskImage, customizationImage, err = s.GetImageVersions(ctx, clusterName)
+		if err != nil {
+			log.Printf("ERROR: Failed to get image versions: %v", err)
+			attImage = "[ATT image unavailable]"
+			guidedTaskImage = "[Guided task image unavailable]"
+			customizationImage = "[Customization image unavailable]"
+		}
+		log.Printf("[TIMING] Get image versions: %v", time.Since(imageStart))
+	}()
 
-	// Step 4: Get image versions (uses kubectl - must be sequential after step 3)
-	imageStart := time.Now()
-	attImage, guidedTaskImage, customizationImage, err := s.GetImageVersions(ctx, clusterName)
-	if err != nil {
-		log.Printf("ERROR: Failed to get image versions: %v", err)
-		attImage = "[ATT image unavailable]"
-		guidedTaskImage = "[Guided task image unavailable]"
-		customizationImage = "[Customization image unavailable]"
-	}
-	log.Printf("[TIMING] Get image versions: %v", time.Since(imageStart))
+	wg.Wait()
+	log.Printf("[TIMING] Fetch cluster data (parallel): %v", time.Since(clusterStart))
 
 	// Step 5: Format all data
 	formatStart := time.Now()
@@ -999,9 +871,6 @@ func (s *RNCreationServiceImpl) validateRNTableRequest(request *types.RNTableReq
 	return nil
 }
 
-// NOTE: Parallel execution functions removed to prevent kubectl config corruption
-// Sequential execution is safer and more reliable for kubectl operations
-
 // populateCorePatchChartsColumn fetches and formats data for the Core Patch/Charts column
 func (s *RNCreationServiceImpl) populateCorePatchChartsColumn(ctx context.Context, clusterName string) (string, error) {
 	corePatchInfo, err := s.GetCorePatchCharts(ctx, clusterName)
@@ -1021,8 +890,8 @@ func (s *RNCreationServiceImpl) populateCorePatchChartsColumn(ctx context.Contex
 }
 
 // populateCommentsInstructionsColumn fetches and formats data for the Comments/Instructions column
-// NOTE: This function now expects TLC version and image versions to be passed in,
-// since they're fetched sequentially in the main function to prevent kubectl config corruption
+// NOTE: This function expects TLC version and image versions to be passed in,
+// since they're fetched in parallel by the main function
 func (s *RNCreationServiceImpl) formatCommentsInstructionsFromData(tlcVersion, clusterName, oniImage, attImage, guidedTaskImage, customizationImage, storageJobURL string) string {
 	return s.formatCommentsInstructions(tlcVersion, clusterName, oniImage, attImage, guidedTaskImage, customizationImage, storageJobURL)
 }
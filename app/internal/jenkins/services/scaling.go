@@ -8,26 +8,48 @@ import (
 	"time"
 
 	"app/internal/config"
+	"app/internal/eks"
+	jenkinsconfig "app/internal/jenkins/config"
 	"app/internal/jenkins/errors"
 	"app/internal/jenkins/types"
 )
 
 const scalingJobBuildSuffix = "/buildWithParameters"
 
+// clusterParameter is the scaling job parameter naming the cluster
+const clusterParameter = "eks_clustername"
+
 // ScalingServiceImpl implements the ScalingService interface
 type ScalingServiceImpl struct {
 	configuration *config.Config
 	client        JenkinsClient
+	inventory     *eks.Inventory
+	policy        *ScalingPolicy
+	audit         *ScalingAudit
+	batches       *scaleBatches
 }
 
-// NewScalingService creates a new scaling service instance
-func NewScalingService(configuration *config.Config, client JenkinsClient) ScalingService {
+// NewScalingService creates a new scaling service instance. Every request is
+// checked against policy and recorded in audit.
+func NewScalingService(configuration *config.Config, client JenkinsClient, inventory *eks.Inventory, policy *ScalingPolicy, audit *ScalingAudit) ScalingService {
 	return &ScalingServiceImpl{
 		configuration: configuration,
 		client:        client,
+		inventory:     inventory,
+		policy:        policy,
+		audit:         audit,
+		batches:       newScaleBatches(),
 	}
 }
 
+// NewScalingClusterSource lists the clusters the scaling job offers: the
+// choices of its eks_clustername parameter in Jenkins, or the allowed_values
+// configured for it in jobs.json
+func NewScalingClusterSource(configuration *config.Config, client JenkinsClient) eks.Source {
+	service := &ScalingServiceImpl{configuration: configuration, client: client}
+	return eks.NewSource("jenkins", service.clusterChoices)
+}
+
 // TriggerScale initiates a scaling operation for an EKS cluster
 func (s *ScalingServiceImpl) TriggerScale(ctx context.Context, request *types.ScaleRequest) (*types.ScaleResponse, error) {
 	// Validate the request
@@ -43,6 +65,15 @@ func (s *ScalingServiceImpl) TriggerScale(ctx context.Context, request *types.Sc
 		)
 	}
 
+	if request.Source == "" {
+		request.Source = types.ScaleSourceManual
+	}
+	if violations := s.policy.Evaluate(request, time.Now()); len(violations) > 0 {
+		policyErr := &ScalePolicyError{Violations: violations}
+		s.recordAudit(request, types.ScaleAuditRefused, policyErr.Error(), "")
+		return nil, policyErr
+	}
+
 	// Get the job URL from configuration
 	jobURL, err := s.getScalingJobURL()
 	if err != nil {
@@ -51,9 +82,9 @@ func (s *ScalingServiceImpl) TriggerScale(ctx context.Context, request *types.Sc
 
 	// Prepare parameters
 	params := map[string]string{
-		"eks_clustername": request.ClusterName,
-		"scale_type":      request.ScaleType,
-		"account":         request.Account,
+		clusterParameter: request.ClusterName,
+		"scale_type":     request.ScaleType,
+		"account":        request.Account,
 	}
 
 	// Add any additional options
@@ -61,9 +92,10 @@ func (s *ScalingServiceImpl) TriggerScale(ctx context.Context, request *types.Sc
 		params[key] = value
 	}
 
-	// Execute the scaling job
-	responseBody, err := s.client.PostWithAuth(ctx, jobURL, params)
+	// Execute the scaling job; Jenkins answers with the queue item in the Location header
+	queueURL, err := s.client.TriggerWithAuth(ctx, jobURL, params)
 	if err != nil {
+		s.recordAudit(request, types.ScaleAuditFailed, err.Error(), "")
 		return nil, errors.NewJobExecutionError(
 			"scaling",
 			"failed to trigger scaling job",
@@ -71,10 +103,6 @@ func (s *ScalingServiceImpl) TriggerScale(ctx context.Context, request *types.Sc
 		)
 	}
 
-	// For Jenkins, POST to buildWithParameters returns the queue URL in Location header
-	// We need to handle this differently since we don't have direct access to headers here
-	// Let's assume the job was queued successfully and create a response
-
 	// Get the base job URL for linking
 	baseJobURL, _ := s.getScalingJobURL()
 	baseJobURL = strings.TrimSuffix(baseJobURL, scalingJobBuildSuffix)
@@ -83,6 +111,7 @@ func (s *ScalingServiceImpl) TriggerScale(ctx context.Context, request *types.Sc
 		JobStatus: &types.JobStatus{
 			Status:      "queued",
 			URL:         baseJobURL, // Set the job URL so the frontend can show the Jenkins link
+			QueueURL:    queueURL,
 			Description: fmt.Sprintf("Scaling %s cluster %s", request.ScaleType, request.ClusterName),
 		},
 		Message:   "Scaling job triggered successfully",
@@ -95,15 +124,31 @@ func (s *ScalingServiceImpl) TriggerScale(ctx context.Context, request *types.Sc
 		},
 	}
 
-	// If we have response data, try to parse it for additional information
-	if len(responseBody) > 0 {
-		// Jenkins might return queue information in some cases
-		s.parseQueueResponse(responseBody, response)
-	}
-
+	s.recordAudit(request, types.ScaleAuditTriggered, response.Message, baseJobURL)
 	return response, nil
 }
 
+// CheckPolicy returns the policy rules a request would break if sent now
+func (s *ScalingServiceImpl) CheckPolicy(request *types.ScaleRequest) []types.ScalePolicyViolation {
+	return s.policy.Evaluate(request, time.Now())
+}
+
+// CheckSchedulePolicy returns the policy rules a schedule would break if saved at now
+func (s *ScalingServiceImpl) CheckSchedulePolicy(schedule *types.ScaleSchedule, now time.Time) []types.ScalePolicyViolation {
+	return s.policy.EvaluateSchedule(schedule, now)
+}
+
+// IsProtected reports whether the policy forbids scaling a cluster down
+func (s *ScalingServiceImpl) IsProtected(clusterName string) bool {
+	return s.policy.IsProtected(clusterName)
+}
+
+// GetScalingHistory returns recent audit entries, newest first; an empty
+// cluster name returns every cluster
+func (s *ScalingServiceImpl) GetScalingHistory(clusterName string, limit int) ([]types.ScaleAuditEntry, error) {
+	return s.audit.Recent(clusterName, limit)
+}
+
 // GetScaleJobStatus retrieves the status of a scaling job
 func (s *ScalingServiceImpl) GetScaleJobStatus(ctx context.Context, jobNumber int) (*types.JobStatus, error) {
 	if jobNumber <= 0 {
@@ -226,19 +271,83 @@ func (s *ScalingServiceImpl) ValidateScaleRequest(request *types.ScaleRequest) (
 	return result, nil
 }
 
-// GetSupportedClusters returns list of clusters that can be scaled
-func (s *ScalingServiceImpl) GetSupportedClusters(ctx context.Context) ([]string, error) {
-	// This would typically fetch from Jenkins job configuration or a separate API
-	// For now, return a placeholder implementation
-	return []string{
-		"develop-malkz",
-		"staging-cluster",
-		"prod-cluster",
-	}, nil
+// GetSupportedClusters returns the clusters that can be scaled from the cluster inventory
+func (s *ScalingServiceImpl) GetSupportedClusters(ctx context.Context, refresh bool) (*eks.Listing, error) {
+	if s.inventory == nil {
+		return nil, errors.NewConfigurationError("no cluster inventory configured", nil)
+	}
+	return s.inventory.List(ctx, refresh)
+}
+
+// clusterChoices reads the cluster choices of the scaling job
+func (s *ScalingServiceImpl) clusterChoices(ctx context.Context) ([]eks.Cluster, error) {
+	var names []string
+	if jobConfig, err := jenkinsconfig.Current().GetJobConfig("scaling"); err == nil {
+		if parameter, ok := jobConfig.Parameters[clusterParameter]; ok {
+			names = append(names, parameter.AllowedValues...)
+		}
+	}
+
+	apiURL := s.scalingJobBaseURL() + "/api/json?tree=property[parameterDefinitions[name,choices]]"
+	responseBody, err := s.client.GetWithAuth(ctx, apiURL)
+	if err != nil {
+		if len(names) > 0 {
+			return clustersNamed(names), nil
+		}
+		return nil, err
+	}
+
+	var job struct {
+		Property []struct {
+			ParameterDefinitions []struct {
+				Name    string   `json:"name"`
+				Choices []string `json:"choices"`
+			} `json:"parameterDefinitions"`
+		} `json:"property"`
+	}
+	if err := json.Unmarshal(responseBody, &job); err != nil {
+		return nil, errors.NewParsingError(apiURL, "failed to parse scaling job parameters", err)
+	}
+	for _, property := range job.Property {
+		for _, definition := range property.ParameterDefinitions {
+			if definition.Name == clusterParameter {
+				names = append(names, definition.Choices...)
+			}
+		}
+	}
+	return clustersNamed(names), nil
+}
+
+func clustersNamed(names []string) []eks.Cluster {
+	clusters := make([]eks.Cluster, 0, len(names))
+	for _, name := range names {
+		clusters = append(clusters, eks.Cluster{Name: name})
+	}
+	return clusters
 }
 
 // Helper methods
 
+// recordAudit writes one audit entry; a failing audit log never blocks scaling
+func (s *ScalingServiceImpl) recordAudit(request *types.ScaleRequest, outcome, message, jobURL string) {
+	requestedBy := request.RequestedBy
+	if requestedBy == "" {
+		requestedBy = "unknown"
+	}
+	s.audit.Record(types.ScaleAuditEntry{
+		Time:        time.Now().UTC(),
+		ClusterName: request.ClusterName,
+		ScaleType:   request.ScaleType,
+		Account:     request.Account,
+		RequestedBy: requestedBy,
+		Source:      request.Source,
+		Reason:      strings.TrimSpace(request.Reason),
+		Outcome:     outcome,
+		Message:     message,
+		JobURL:      jobURL,
+	})
+}
+
 // getScalingJobURL constructs the URL for the scaling job
 func (s *ScalingServiceImpl) getScalingJobURL() (string, error) {
 	return s.scalingJobBaseURL() + scalingJobBuildSuffix, nil
@@ -261,67 +370,11 @@ func (s *ScalingServiceImpl) scalingJobPath() string {
 
 // parseJobStatusResponse parses the JSON response from Jenkins job status API
 func (s *ScalingServiceImpl) parseJobStatusResponse(data []byte) (*types.JobStatus, error) {
-	var jenkinsResp struct {
-		Number            int    `json:"number"`
-		Result            string `json:"result"`
-		Building          bool   `json:"building"`
-		Duration          int64  `json:"duration"`
-		URL               string `json:"url"`
-		FullDisplayName   string `json:"fullDisplayName"`
-		Timestamp         int64  `json:"timestamp"`
-		EstimatedDuration int64  `json:"estimatedDuration"`
-	}
-
-	if err := json.Unmarshal(data, &jenkinsResp); err != nil {
+	var build jenkinsBuild
+	if err := json.Unmarshal(data, &build); err != nil {
 		return nil, err
 	}
-
-	// Convert Jenkins status to our status
-	status := "unknown"
-	if jenkinsResp.Building {
-		status = "running"
-	} else if jenkinsResp.Result == "SUCCESS" {
-		status = "success"
-	} else if jenkinsResp.Result == "FAILURE" {
-		status = "failed"
-	} else if jenkinsResp.Result == "ABORTED" {
-		status = "aborted"
-	} else if jenkinsResp.Result == "UNSTABLE" {
-		status = "unstable"
-	}
-
-	// Create description
-	description := fmt.Sprintf("Job #%d", jenkinsResp.Number)
-	if status == "running" {
-		description = fmt.Sprintf("Job #%d is running", jenkinsResp.Number)
-	} else if status == "success" {
-		description = fmt.Sprintf("Job #%d completed successfully", jenkinsResp.Number)
-	} else if status == "failed" {
-		description = fmt.Sprintf("Job #%d failed", jenkinsResp.Number)
-	}
-
-	var startTime, endTime *time.Time
-	if jenkinsResp.Timestamp > 0 {
-		t := time.Unix(jenkinsResp.Timestamp/1000, 0)
-		startTime = &t
-
-		if !jenkinsResp.Building && jenkinsResp.Duration > 0 {
-			et := t.Add(time.Duration(jenkinsResp.Duration) * time.Millisecond)
-			endTime = &et
-		}
-	}
-
-	return &types.JobStatus{
-		Number:      jenkinsResp.Number,
-		Status:      status,
-		URL:         jenkinsResp.URL,
-		Duration:    time.Duration(jenkinsResp.Duration) * time.Millisecond,
-		Description: description,
-		StartTime:   startTime,
-		EndTime:     endTime,
-		Result:      jenkinsResp.Result,
-		Building:    jenkinsResp.Building,
-	}, nil
+	return build.toJobStatus(), nil
 }
 
 // parseQueueStatusResponse parses the JSON response from Jenkins queue API
@@ -380,13 +433,6 @@ func (s *ScalingServiceImpl) parseQueueStatusResponse(data []byte) (*types.JobSt
 	}, nil
 }
 
-// parseQueueResponse attempts to parse queue information from response
-func (s *ScalingServiceImpl) parseQueueResponse(data []byte, response *types.ScaleResponse) {
-	// This is a placeholder for parsing queue response
-	// In practice, Jenkins returns the queue URL in the Location header
-	// which we would need to handle at the HTTP client level
-}
-
 // generateRequestID generates a unique request ID
 func generateRequestID() string {
 	return fmt.Sprintf("scale-%d", time.Now().UnixNano())
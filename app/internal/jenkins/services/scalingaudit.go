@@ -0,0 +1,100 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"app/internal/jenkins/errors"
+	"app/internal/jenkins/types"
+)
+
+// scalingAuditMaxBytes is the size at which the audit log is rotated to .1
+const scalingAuditMaxBytes = 5 << 20
+
+// ScalingAudit is an append-only JSON lines record of scaling attempts: who
+// scaled which cluster, why, and whether the job was triggered
+type ScalingAudit struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewScalingAudit records to the file at path, created on first use
+func NewScalingAudit(path string) *ScalingAudit {
+	return &ScalingAudit{path: path}
+}
+
+// Record appends one entry
+func (a *ScalingAudit) Record(entry types.ScaleAuditEntry) error {
+	if a == nil || a.path == "" {
+		return nil
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+		return errors.NewConfigurationError("failed to create scaling audit directory", err)
+	}
+	if info, err := os.Stat(a.path); err == nil && info.Size() > scalingAuditMaxBytes {
+		os.Rename(a.path, a.path+".1")
+	}
+	file, err := os.OpenFile(a.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.NewConfigurationError("failed to open scaling audit log", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return errors.NewConfigurationError("failed to write scaling audit log", err)
+	}
+	return nil
+}
+
+// Recent returns up to limit entries, newest first, optionally for one cluster
+func (a *ScalingAudit) Recent(clusterName string, limit int) ([]types.ScaleAuditEntry, error) {
+	entries := []types.ScaleAuditEntry{}
+	if a == nil || a.path == "" {
+		return entries, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	file, err := os.Open(a.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, errors.NewConfigurationError("failed to read scaling audit log", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var entry types.ScaleAuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // a torn line from a crash is not worth failing the whole log
+		}
+		if clusterName != "" && !strings.EqualFold(entry.ClusterName, clusterName) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewConfigurationError("failed to read scaling audit log", err)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"app/internal/jenkins/errors"
+	"app/internal/jenkins/types"
+)
+
+const (
+	// defaultBatchConcurrency is how many scaling jobs a batch triggers at once
+	defaultBatchConcurrency = 4
+	maxBatchConcurrency     = 10
+
+	// keptBatches is how many recent batches can still be looked up
+	keptBatches = 20
+)
+
+// scaleBatches keeps the most recent batch results for status polling
+type scaleBatches struct {
+	mu    sync.Mutex
+	byID  map[string]*types.ScaleBatchResult
+	order []string
+}
+
+func newScaleBatches() *scaleBatches {
+	return &scaleBatches{byID: make(map[string]*types.ScaleBatchResult)}
+}
+
+func (b *scaleBatches) put(result *types.ScaleBatchResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, exists := b.byID[result.BatchID]; !exists {
+		b.order = append(b.order, result.BatchID)
+	}
+	b.byID[result.BatchID] = copyBatch(result)
+	for len(b.order) > keptBatches {
+		delete(b.byID, b.order[0])
+		b.order = b.order[1:]
+	}
+}
+
+func (b *scaleBatches) get(id string) (*types.ScaleBatchResult, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result, ok := b.byID[id]
+	if !ok {
+		return nil, false
+	}
+	return copyBatch(result), true
+}
+
+func copyBatch(result *types.ScaleBatchResult) *types.ScaleBatchResult {
+	copied := *result
+	copied.Items = append([]types.ScaleBatchItem(nil), result.Items...)
+	return &copied
+}
+
+// BatchConfirmationPhrase is what a user types to confirm a batch that
+// includes production-like clusters
+func BatchConfirmationPhrase(scaleType string, clusters int) string {
+	return fmt.Sprintf("scale %s %d clusters", scaleType, clusters)
+}
+
+// TriggerBatch scales every cluster of the request with bounded concurrency.
+// Missing reasons or confirmations refuse the whole batch up front; clusters
+// the policy refuses for other reasons, or whose job cannot be triggered, are
+// reported in the result while the rest go ahead.
+func (s *ScalingServiceImpl) TriggerBatch(ctx context.Context, request *types.ScaleBatchRequest) (*types.ScaleBatchResult, error) {
+	clusters, err := s.batchClusters(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if request.Source == "" {
+		request.Source = types.ScaleSourceManual
+	}
+
+	confirmed := request.Confirmation != "" &&
+		strings.Join(strings.Fields(request.Confirmation), " ") == BatchConfirmationPhrase(request.ScaleType, len(clusters))
+	requests := make([]*types.ScaleRequest, len(clusters))
+	var problems []string
+	for i, cluster := range clusters {
+		options := make(map[string]string, len(request.Options))
+		for key, value := range request.Options {
+			options[key] = value
+		}
+		requests[i] = &types.ScaleRequest{
+			ClusterName: cluster,
+			ScaleType:   request.ScaleType,
+			Account:     request.Account,
+			Options:     options,
+			Reason:      request.Reason,
+			RequestedBy: request.RequestedBy,
+			Source:      request.Source,
+		}
+		if confirmed {
+			requests[i].Confirmation = ConfirmationPhrase(requests[i])
+		}
+		validation, _ := s.ValidateScaleRequest(requests[i])
+		if !validation.Valid {
+			problems = append(problems, fmt.Sprintf("%s: %s", cluster, strings.Join(validation.Errors, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, errors.NewInvalidParametersError("scaling", "validation failed: "+strings.Join(problems, "; "), nil)
+	}
+
+	// What the user can fix by answering is asked for once for the whole batch
+	if violations := s.batchViolations(requests, len(clusters)); len(violations) > 0 {
+		policyErr := &ScalePolicyError{Violations: violations}
+		for _, scaleRequest := range requests {
+			s.recordAudit(scaleRequest, types.ScaleAuditRefused, policyErr.Error(), "")
+		}
+		return nil, policyErr
+	}
+
+	concurrency := request.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > maxBatchConcurrency {
+		concurrency = maxBatchConcurrency
+	}
+
+	now := time.Now()
+	result := &types.ScaleBatchResult{
+		BatchID:   fmt.Sprintf("batch-%d", now.UnixNano()),
+		ScaleType: request.ScaleType,
+		Group:     request.Group,
+		Total:     len(clusters),
+		Items:     make([]types.ScaleBatchItem, len(clusters)),
+		StartedAt: now,
+	}
+
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, scaleRequest := range requests {
+		wg.Add(1)
+		go func(i int, scaleRequest *types.ScaleRequest) {
+			defer wg.Done()
+			item := types.ScaleBatchItem{ClusterName: scaleRequest.ClusterName}
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				item.State = types.ScaleBatchFailed
+				item.Message = "batch cancelled before the job was triggered"
+				result.Items[i] = item
+				return
+			}
+
+			response, err := s.TriggerScale(ctx, scaleRequest)
+			var policyErr *ScalePolicyError
+			switch {
+			case stderrors.As(err, &policyErr):
+				item.State = types.ScaleBatchRefused
+				item.Message = policyErr.Error()
+				item.Violations = policyErr.Violations
+			case err != nil:
+				item.State = types.ScaleBatchFailed
+				item.Message = err.Error()
+			default:
+				item.State = types.ScaleBatchTriggered
+				item.Message = response.Message
+				item.JobStatus = response.JobStatus
+				if response.JobStatus != nil {
+					item.QueueURL = response.JobStatus.QueueURL
+				}
+			}
+			result.Items[i] = item
+		}(i, scaleRequest)
+	}
+	wg.Wait()
+
+	summarizeBatch(result)
+	s.batches.put(result)
+	return result, nil
+}
+
+// GetBatchStatus follows each triggered cluster from its queue item to its
+// build and returns the refreshed batch
+func (s *ScalingServiceImpl) GetBatchStatus(ctx context.Context, batchID string) (*types.ScaleBatchResult, error) {
+	result, ok := s.batches.get(batchID)
+	if !ok {
+		return nil, errors.NewJobNotFoundError("scaling", fmt.Sprintf("batch %s not found", batchID), nil)
+	}
+
+	var wg sync.WaitGroup
+	for i := range result.Items {
+		item := &result.Items[i]
+		if item.State != types.ScaleBatchTriggered && item.State != types.ScaleBatchRunning {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.refreshBatchItem(ctx, item)
+		}()
+	}
+	wg.Wait()
+
+	summarizeBatch(result)
+	s.batches.put(result)
+	return result, nil
+}
+
+// refreshBatchItem moves one cluster forward: queue item, then build
+func (s *ScalingServiceImpl) refreshBatchItem(ctx context.Context, item *types.ScaleBatchItem) {
+	if item.JobStatus == nil || item.JobStatus.Number == 0 {
+		if item.QueueURL == "" {
+			item.State = types.ScaleBatchUnknown
+			item.Message = "Jenkins did not return a queue item; check the job in Jenkins"
+			return
+		}
+		queued, err := s.GetQueueStatus(ctx, item.QueueURL)
+		if err != nil {
+			item.Message = err.Error()
+			return
+		}
+		item.JobStatus = queued
+		item.Message = queued.Description
+		switch {
+		case queued.Status == "aborted":
+			item.State = types.ScaleBatchFailed
+			return
+		case queued.Number == 0:
+			return // still waiting in the queue
+		}
+	}
+
+	build, err := s.GetScaleJobStatus(ctx, item.JobStatus.Number)
+	if err != nil {
+		item.Message = err.Error()
+		return
+	}
+	item.JobStatus = build
+	item.Message = build.Description
+	switch build.Status {
+	case "success":
+		item.State = types.ScaleBatchSucceeded
+	case "failed", "aborted", "unstable":
+		item.State = types.ScaleBatchFailed
+	default:
+		item.State = types.ScaleBatchRunning
+	}
+}
+
+// summarizeBatch recounts the states of a batch
+func summarizeBatch(result *types.ScaleBatchResult) {
+	result.Counts = make(map[string]int)
+	result.Done = true
+	for _, item := range result.Items {
+		result.Counts[item.State]++
+		if item.State == types.ScaleBatchTriggered || item.State == types.ScaleBatchRunning {
+			result.Done = false
+		}
+	}
+	result.UpdatedAt = time.Now()
+}
+
+// batchViolations collects the reason and confirmation violations of a batch
+// as one violation each, with a confirmation phrase for the whole batch
+func (s *ScalingServiceImpl) batchViolations(requests []*types.ScaleRequest, clusters int) []types.ScalePolicyViolation {
+	var needConfirmation, needReason []string
+	now := time.Now()
+	for _, request := range requests {
+		for _, violation := range s.policy.Evaluate(request, now) {
+			switch violation.Rule {
+			case types.ScalePolicyConfirmation:
+				needConfirmation = append(needConfirmation, request.ClusterName)
+			case types.ScalePolicyReason:
+				needReason = append(needReason, request.ClusterName)
+			}
+		}
+	}
+
+	var violations []types.ScalePolicyViolation
+	if len(needConfirmation) > 0 {
+		phrase := BatchConfirmationPhrase(requests[0].ScaleType, clusters)
+		verb := "looks"
+		if len(needConfirmation) > 1 {
+			verb = "look"
+		}
+		violations = append(violations, types.ScalePolicyViolation{
+			Rule:               types.ScalePolicyConfirmation,
+			Message:            fmt.Sprintf("%s %s like production; type %q to confirm", strings.Join(needConfirmation, ", "), verb, phrase),
+			ConfirmationPhrase: phrase,
+		})
+	}
+	if len(needReason) > 0 {
+		violations = append(violations, types.ScalePolicyViolation{
+			Rule:    types.ScalePolicyReason,
+			Message: "a reason is required to scale down or to scale a production-like cluster",
+		})
+	}
+	return violations
+}
+
+// batchClusters resolves the named clusters and the group into a unique list
+func (s *ScalingServiceImpl) batchClusters(ctx context.Context, request *types.ScaleBatchRequest) ([]string, error) {
+	names := append([]string(nil), request.Clusters...)
+	if group := strings.TrimSpace(request.Group); group != "" {
+		groups, err := s.GetClusterGroups(ctx)
+		if err != nil {
+			return nil, err
+		}
+		members, ok := groups[group]
+		if !ok {
+			return nil, errors.NewInvalidParametersError("scaling", fmt.Sprintf("unknown cluster group %q", group), nil)
+		}
+		names = append(names, members...)
+	}
+
+	seen := make(map[string]bool)
+	var clusters []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		clusters = append(clusters, name)
+	}
+	if len(clusters) == 0 {
+		return nil, errors.NewInvalidParametersError("scaling", "no clusters to scale: give clusters or a group with clusters", nil)
+	}
+	return clusters, nil
+}
+
+// GetClusterGroups reads the groups file and resolves glob members against
+// the cluster inventory. The file is read on every call so edits apply
+// without a restart.
+func (s *ScalingServiceImpl) GetClusterGroups(ctx context.Context) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	if s.configuration == nil || s.configuration.Scaling.GroupsFile == "" {
+		return groups, nil
+	}
+	file := s.configuration.Scaling.GroupsFile
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return groups, nil
+	}
+	if err != nil {
+		return nil, errors.NewConfigurationError(fmt.Sprintf("failed to read cluster groups %s", file), err)
+	}
+	var patterns map[string][]string
+	if err := json.Unmarshal(data, &patterns); err != nil {
+		return nil, errors.NewConfigurationError(fmt.Sprintf("failed to parse cluster groups %s", file), err)
+	}
+
+	var known []string
+	for name, members := range patterns {
+		var clusters []string
+		for _, member := range members {
+			member = strings.TrimSpace(member)
+			if !strings.ContainsAny(member, "*?[") {
+				clusters = append(clusters, member)
+				continue
+			}
+			if known == nil {
+				listing, err := s.GetSupportedClusters(ctx, false)
+				if err != nil {
+					return nil, err
+				}
+				known = listing.Names()
+			}
+			pattern := strings.ToLower(member)
+			for _, cluster := range known {
+				if ok, _ := path.Match(pattern, strings.ToLower(cluster)); ok {
+					clusters = append(clusters, cluster)
+				}
+			}
+		}
+		sort.Strings(clusters)
+		groups[name] = clusters
+	}
+	return groups, nil
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"app/internal/config"
+	"app/internal/jenkins/errors"
+	"app/internal/jenkins/types"
+)
+
+// ScalingPolicy decides whether a scaling request may run. It guards
+// protected clusters, asks for a typed confirmation on production-like
+// clusters, keeps scale-downs out of business hours and requires a reason for
+// anything disruptive.
+type ScalingPolicy struct {
+	protected []string
+	confirm   []string
+	hours     *businessHours
+}
+
+// businessHours is a weekly window such as mon-fri 08:00-19:00
+type businessHours struct {
+	days     map[time.Weekday]bool
+	start    int // minutes after midnight
+	end      int
+	location *time.Location
+	spec     string
+}
+
+// NewScalingPolicy builds the policy from the scaling configuration
+func NewScalingPolicy(cfg config.ScalingConfig) (*ScalingPolicy, error) {
+	policy := &ScalingPolicy{}
+	var err error
+	if policy.protected, err = clusterPatterns(cfg.ProtectedClusters); err != nil {
+		return nil, errors.NewConfigurationError("invalid OCD_SCALING_PROTECTED_CLUSTERS", err)
+	}
+	if policy.confirm, err = clusterPatterns(cfg.ConfirmClusters); err != nil {
+		return nil, errors.NewConfigurationError("invalid OCD_SCALING_CONFIRM_CLUSTERS", err)
+	}
+	if policy.hours, err = parseBusinessHours(cfg.BusinessHours, cfg.BusinessHoursTimezone); err != nil {
+		return nil, errors.NewConfigurationError("invalid OCD_SCALING_BUSINESS_HOURS", err)
+	}
+	return policy, nil
+}
+
+// Evaluate returns every rule the request breaks at now; none means it may run
+func (p *ScalingPolicy) Evaluate(request *types.ScaleRequest, now time.Time) []types.ScalePolicyViolation {
+	if p == nil {
+		return nil
+	}
+	var violations []types.ScalePolicyViolation
+	scaleDown := request.ScaleType == "down"
+	scheduled := request.Source == types.ScaleSourceSchedule
+	needsConfirmation := matchesCluster(p.confirm, request.ClusterName)
+
+	if scaleDown && p.IsProtected(request.ClusterName) {
+		violations = append(violations, types.ScalePolicyViolation{
+			Rule:    types.ScalePolicyProtected,
+			Message: fmt.Sprintf("cluster %s is protected and cannot be scaled down", request.ClusterName),
+		})
+	}
+
+	// Schedules are confirmed when they are saved, not on every run
+	if needsConfirmation && !scheduled {
+		phrase := ConfirmationPhrase(request)
+		if strings.Join(strings.Fields(request.Confirmation), " ") != phrase {
+			violations = append(violations, types.ScalePolicyViolation{
+				Rule:               types.ScalePolicyConfirmation,
+				Message:            fmt.Sprintf("cluster %s looks like production; type %q to confirm", request.ClusterName, phrase),
+				ConfirmationPhrase: phrase,
+			})
+		}
+	}
+
+	if scaleDown && p.hours.contains(now) {
+		violations = append(violations, types.ScalePolicyViolation{
+			Rule:    types.ScalePolicyBusinessHours,
+			Message: fmt.Sprintf("clusters cannot be scaled down during business hours (%s)", p.hours.spec),
+		})
+	}
+
+	if (scaleDown || needsConfirmation) && strings.TrimSpace(request.Reason) == "" {
+		violations = append(violations, types.ScalePolicyViolation{
+			Rule:    types.ScalePolicyReason,
+			Message: "a reason is required to scale down or to scale a production-like cluster",
+		})
+	}
+	return violations
+}
+
+// EvaluateSchedule returns every rule a schedule breaks when it is saved at
+// now. Scheduled runs are not confirmed again, so production-like clusters
+// need the confirmation phrase and a reason here instead, and a down rule that
+// Evaluate would refuse on some run for falling inside business hours is
+// refused now rather than failing later.
+func (p *ScalingPolicy) EvaluateSchedule(schedule *types.ScaleSchedule, now time.Time) []types.ScalePolicyViolation {
+	if p == nil {
+		return nil
+	}
+	var violations []types.ScalePolicyViolation
+	for i, rule := range schedule.Rules {
+		if rule.ScaleType != "down" {
+			continue
+		}
+		if p.IsProtected(schedule.ClusterName) {
+			violations = append(violations, types.ScalePolicyViolation{
+				Rule:    types.ScalePolicyProtected,
+				Message: fmt.Sprintf("rules[%d]: cluster %s is protected and cannot be scaled down", i, schedule.ClusterName),
+			})
+		}
+		if at, ok := p.hours.firstScheduled(schedule, rule, now); ok {
+			violations = append(violations, types.ScalePolicyViolation{
+				Rule: types.ScalePolicyBusinessHours,
+				Message: fmt.Sprintf("rules[%d]: scaling down %s at %s %s falls inside business hours (%s), first on %s",
+					i, strings.Join(rule.Days, ","), rule.Time, schedule.Timezone, p.hours.spec, at.Format("Mon 2006-01-02 15:04 MST")),
+			})
+		}
+	}
+
+	if !matchesCluster(p.confirm, schedule.ClusterName) {
+		return violations
+	}
+	phrase := ScheduleConfirmationPhrase(schedule)
+	if strings.Join(strings.Fields(schedule.Confirmation), " ") != phrase {
+		violations = append(violations, types.ScalePolicyViolation{
+			Rule:               types.ScalePolicyConfirmation,
+			Message:            fmt.Sprintf("cluster %s looks like production; type %q to confirm the schedule", schedule.ClusterName, phrase),
+			ConfirmationPhrase: phrase,
+		})
+	}
+	if strings.TrimSpace(schedule.Reason) == "" {
+		violations = append(violations, types.ScalePolicyViolation{
+			Rule:    types.ScalePolicyReason,
+			Message: "a reason is required to schedule scaling of a production-like cluster",
+		})
+	}
+	return violations
+}
+
+// IsProtected reports whether a cluster may never be scaled down
+func (p *ScalingPolicy) IsProtected(clusterName string) bool {
+	return p != nil && matchesCluster(p.protected, clusterName)
+}
+
+// ConfirmationPhrase is what a user types to confirm scaling a production-like cluster
+func ConfirmationPhrase(request *types.ScaleRequest) string {
+	return fmt.Sprintf("scale %s %s", request.ScaleType, request.ClusterName)
+}
+
+// ScheduleConfirmationPhrase is what a user types to confirm a schedule for a production-like cluster
+func ScheduleConfirmationPhrase(schedule *types.ScaleSchedule) string {
+	return fmt.Sprintf("schedule scaling %s", schedule.ClusterName)
+}
+
+// ScalePolicyError is returned when the scaling policy refuses a request. It
+// matches errors.ErrInvalidParameters.
+type ScalePolicyError struct {
+	Violations []types.ScalePolicyViolation
+}
+
+func (e *ScalePolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return "scaling refused by policy: " + strings.Join(messages, "; ")
+}
+
+// Is lets the error be handled like any other invalid request
+func (e *ScalePolicyError) Is(target error) bool {
+	return target == errors.ErrInvalidParameters
+}
+
+// clusterPatterns lower-cases and checks cluster globs
+func clusterPatterns(patterns []string) ([]string, error) {
+	checked := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad cluster pattern %q: %w", pattern, err)
+		}
+		checked = append(checked, pattern)
+	}
+	return checked, nil
+}
+
+func matchesCluster(patterns []string, clusterName string) bool {
+	name := strings.ToLower(strings.TrimSpace(clusterName))
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBusinessHours parses "<days> HH:MM-HH:MM" where days is a range
+// (mon-fri), a list (mon,wed,fri) or a group (weekdays). Empty disables the
+// restriction.
+func parseBusinessHours(spec, timezone string) (*businessHours, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("%q must look like mon-fri 08:00-19:00", spec)
+	}
+
+	hours := &businessHours{days: make(map[time.Weekday]bool), location: time.Local, spec: spec}
+	if strings.TrimSpace(timezone) != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", timezone)
+		}
+		hours.location = location
+		hours.spec += " " + timezone
+	}
+
+	if first, last, ok := strings.Cut(fields[0], "-"); ok {
+		from, fromOK := scheduleWeekdays[strings.ToLower(first)]
+		to, toOK := scheduleWeekdays[strings.ToLower(last)]
+		if !fromOK || !toOK {
+			return nil, fmt.Errorf("unknown day range %q", fields[0])
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			hours.days[day] = true
+			if day == to {
+				break
+			}
+		}
+	} else {
+		days, err := expandDays(strings.Split(fields[0], ","))
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			hours.days[scheduleWeekdays[day]] = true
+		}
+	}
+
+	start, end, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return nil, fmt.Errorf("%q must be a HH:MM-HH:MM range", fields[1])
+	}
+	startHour, startMinute, err := parseClock(start)
+	if err != nil {
+		return nil, err
+	}
+	endHour, endMinute, err := parseClock(end)
+	if err != nil {
+		return nil, err
+	}
+	hours.start = startHour*60 + startMinute
+	hours.end = endHour*60 + endMinute
+	if hours.end <= hours.start {
+		return nil, fmt.Errorf("%q must end after it starts", fields[1])
+	}
+	return hours, nil
+}
+
+// firstScheduled returns the first run of a schedule rule within a year of now
+// that falls inside the window. A year covers every weekday under both
+// daylight saving offsets of the schedule's and the window's timezones.
+func (h *businessHours) firstScheduled(schedule *types.ScaleSchedule, rule types.ScheduleRule, now time.Time) (time.Time, bool) {
+	if h == nil {
+		return time.Time{}, false
+	}
+	single := *schedule
+	single.Rules = []types.ScheduleRule{rule}
+	for _, occurrence := range scheduleOccurrences(&single, now, now.AddDate(1, 0, 0)) {
+		if h.contains(occurrence.At) {
+			return occurrence.At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// contains reports whether t falls inside the window
+func (h *businessHours) contains(t time.Time) bool {
+	if h == nil {
+		return false
+	}
+	local := t.In(h.location)
+	minute := local.Hour()*60 + local.Minute()
+	return h.days[local.Weekday()] && minute >= h.start && minute < h.end
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"app/internal/config"
+	"app/internal/jenkins/types"
+)
+
+func TestEvaluateSchedule(t *testing.T) {
+	policy, err := NewScalingPolicy(config.ScalingConfig{
+		ProtectedClusters:     []string{"core-*"},
+		ConfirmClusters:       []string{"prod-*"},
+		BusinessHours:         "mon-fri 08:00-19:00",
+		BusinessHoursTimezone: "Asia/Jerusalem",
+	})
+	if err != nil {
+		t.Fatalf("NewScalingPolicy: %v", err)
+	}
+	// A Monday in winter, when Jerusalem is UTC+2
+	now := time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		cluster      string
+		timezone     string
+		rules        []types.ScheduleRule
+		reason       string
+		confirmation string
+		want         []string // violated policy rules, in order
+	}{
+		{
+			name:     "down inside business hours",
+			cluster:  "dev-eks",
+			timezone: "Asia/Jerusalem",
+			rules:    []types.ScheduleRule{{ScaleType: "down", Days: []string{"mon", "tue", "wed", "thu", "fri"}, Time: "12:00"}},
+			want:     []string{types.ScalePolicyBusinessHours},
+		},
+		{
+			name:     "down after business hours",
+			cluster:  "dev-eks",
+			timezone: "Asia/Jerusalem",
+			rules:    []types.ScheduleRule{{ScaleType: "down", Days: []string{"mon", "tue", "wed", "thu", "fri"}, Time: "20:00"}},
+		},
+		{
+			name:     "down on a day outside business hours",
+			cluster:  "dev-eks",
+			timezone: "Asia/Jerusalem",
+			rules:    []types.ScheduleRule{{ScaleType: "down", Days: []string{"sat"}, Time: "12:00"}},
+		},
+		{
+			name:     "up inside business hours",
+			cluster:  "dev-eks",
+			timezone: "Asia/Jerusalem",
+			rules:    []types.ScheduleRule{{ScaleType: "up", Days: []string{"mon"}, Time: "09:00"}},
+		},
+		{
+			name:     "another timezone reaches business hours in summer only",
+			cluster:  "dev-eks",
+			timezone: "UTC",
+			rules:    []types.ScheduleRule{{ScaleType: "down", Days: []string{"mon"}, Time: "05:30"}},
+			want:     []string{types.ScalePolicyBusinessHours},
+		},
+		{
+			name:     "another timezone before business hours all year",
+			cluster:  "dev-eks",
+			timezone: "UTC",
+			rules:    []types.ScheduleRule{{ScaleType: "down", Days: []string{"mon"}, Time: "04:30"}},
+		},
+		{
+			name:     "protected cluster",
+			cluster:  "core-eks",
+			timezone: "Asia/Jerusalem",
+			rules:    []types.ScheduleRule{{ScaleType: "down", Days: []string{"sat"}, Time: "02:00"}},
+			want:     []string{types.ScalePolicyProtected},
+		},
+		{
+			name:     "production-like cluster without confirmation or reason",
+			cluster:  "prod-eks",
+			timezone: "Asia/Jerusalem",
+			rules:    []types.ScheduleRule{{ScaleType: "up", Days: []string{"mon"}, Time: "07:00"}},
+			want:     []string{types.ScalePolicyConfirmation, types.ScalePolicyReason},
+		},
+		{
+			name:         "production-like cluster confirmed with a reason",
+			cluster:      "prod-eks",
+			timezone:     "Asia/Jerusalem",
+			rules:        []types.ScheduleRule{{ScaleType: "down", Days: []string{"fri"}, Time: "22:00"}},
+			reason:       "weekend savings",
+			confirmation: "schedule  scaling prod-eks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &types.ScaleSchedule{
+				ClusterName:  tt.cluster,
+				Timezone:     tt.timezone,
+				Rules:        tt.rules,
+				Reason:       tt.reason,
+				Confirmation: tt.confirmation,
+			}
+			violations := policy.EvaluateSchedule(schedule, now)
+			got := make([]string, 0, len(violations))
+			for _, violation := range violations {
+				got = append(got, violation.Rule)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("violations = %+v, want rules %v", violations, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("violation %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,562 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // schedule timezones must resolve on Windows hosts without a zoneinfo database
+
+	"app/internal/jenkins/errors"
+	"app/internal/jenkins/types"
+)
+
+const (
+	// DefaultSchedulerInterval is how often the scheduler looks for due actions
+	DefaultSchedulerInterval = 30 * time.Second
+
+	// scheduleOnTimeGrace is how late an action may run and still count as on time
+	scheduleOnTimeGrace = 5 * time.Minute
+
+	defaultCatchUpWindow = 12 * time.Hour
+
+	// scheduleLookback bounds how far back missed actions are searched for
+	scheduleLookback = 14 * 24 * time.Hour
+
+	// scheduleRecentRuns is how many run records each schedule keeps
+	scheduleRecentRuns = 20
+)
+
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+var scheduleDayGroups = map[string][]string{
+	"weekdays": {"mon", "tue", "wed", "thu", "fri"},
+	"weekends": {"sat", "sun"},
+	"daily":    {"mon", "tue", "wed", "thu", "fri", "sat", "sun"},
+}
+
+// ScalingSchedulerImpl implements the ScalingScheduler interface. Schedules
+// are kept in a local JSON file together with how far each has been
+// evaluated, so actions missed while OCD was not running are found on the
+// next start and handled by the schedule's catch-up policy.
+type ScalingSchedulerImpl struct {
+	path    string
+	scaling ScalingService
+	logging LoggingService
+
+	mu        sync.Mutex
+	schedules map[string]*types.ScaleSchedule
+}
+
+// NewScalingScheduler loads the schedules stored at path. A missing file is
+// an empty schedule list.
+func NewScalingScheduler(path string, scaling ScalingService, logging LoggingService) (ScalingScheduler, error) {
+	scheduler := &ScalingSchedulerImpl{
+		path:      path,
+		scaling:   scaling,
+		logging:   logging,
+		schedules: make(map[string]*types.ScaleSchedule),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return scheduler, nil
+	}
+	if err != nil {
+		return nil, errors.NewConfigurationError(fmt.Sprintf("failed to read scaling schedules %s", path), err)
+	}
+	var stored []*types.ScaleSchedule
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, errors.NewConfigurationError(fmt.Sprintf("failed to parse scaling schedules %s", path), err)
+	}
+	for _, schedule := range stored {
+		scheduler.schedules[schedule.ID] = schedule
+	}
+	return scheduler, nil
+}
+
+// ListSchedules returns every schedule with its next run, ordered by cluster
+func (s *ScalingSchedulerImpl) ListSchedules() []types.ScaleSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	schedules := make([]types.ScaleSchedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		schedules = append(schedules, withNextRun(schedule, now))
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].ClusterName != schedules[j].ClusterName {
+			return schedules[i].ClusterName < schedules[j].ClusterName
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+	return schedules
+}
+
+// GetSchedule returns one schedule with its next run
+func (s *ScalingSchedulerImpl) GetSchedule(id string) (*types.ScaleSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return nil, errors.NewJobNotFoundError("scaling-schedule", fmt.Sprintf("schedule %s not found", id), nil)
+	}
+	result := withNextRun(schedule, time.Now())
+	return &result, nil
+}
+
+// SaveSchedule validates and stores a schedule. A schedule without an ID is
+// created; otherwise the existing schedule is replaced, keeping its history.
+func (s *ScalingSchedulerImpl) SaveSchedule(schedule *types.ScaleSchedule) (*types.ScaleSchedule, error) {
+	if err := normalizeSchedule(schedule); err != nil {
+		return nil, err
+	}
+	schedule.Reason = strings.TrimSpace(schedule.Reason)
+	if violations := s.scaling.CheckSchedulePolicy(schedule, time.Now()); len(violations) > 0 {
+		for i := range violations {
+			violations[i].ClusterName = schedule.ClusterName
+		}
+		return nil, &ScalePolicyError{Violations: violations}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	saved := *schedule
+	saved.Confirmation = ""
+	saved.NextRun = nil
+	saved.UpdatedAt = now
+	if saved.ID == "" {
+		saved.ID = fmt.Sprintf("schedule-%d", now.UnixNano())
+		saved.CreatedAt = now
+		saved.Recent = nil
+		// New schedules only act on occurrences from now on
+		saved.LastChecked = &now
+	} else {
+		existing, ok := s.schedules[saved.ID]
+		if !ok {
+			return nil, errors.NewJobNotFoundError("scaling-schedule", fmt.Sprintf("schedule %s not found", saved.ID), nil)
+		}
+		saved.CreatedAt = existing.CreatedAt
+		saved.Recent = existing.Recent
+		saved.LastChecked = existing.LastChecked
+		// Resuming a paused schedule must not replay what was due while paused
+		if existing.Paused && !saved.Paused {
+			saved.LastChecked = &now
+		}
+	}
+
+	previous, existed := s.schedules[saved.ID]
+	s.schedules[saved.ID] = &saved
+	if err := s.save(); err != nil {
+		if existed {
+			s.schedules[saved.ID] = previous
+		} else {
+			delete(s.schedules, saved.ID)
+		}
+		return nil, err
+	}
+
+	result := withNextRun(&saved, now)
+	return &result, nil
+}
+
+// DeleteSchedule removes a schedule
+func (s *ScalingSchedulerImpl) DeleteSchedule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return errors.NewJobNotFoundError("scaling-schedule", fmt.Sprintf("schedule %s not found", id), nil)
+	}
+	delete(s.schedules, id)
+	if err := s.save(); err != nil {
+		s.schedules[id] = schedule
+		return err
+	}
+	return nil
+}
+
+// Run evaluates the schedules every interval until ctx is cancelled. The first
+// evaluation happens immediately so missed actions are caught up on start.
+func (s *ScalingSchedulerImpl) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSchedulerInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scheduledAction is a due action picked by tick, run outside the lock
+type scheduledAction struct {
+	schedule types.ScaleSchedule
+	due      types.ScheduledScale
+	catchUp  bool
+}
+
+// tick handles every action that fell due since each schedule was last checked
+func (s *ScalingSchedulerImpl) tick(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	var actions []scheduledAction
+	changed := false
+	for _, schedule := range s.schedules {
+		if schedule.Paused {
+			continue
+		}
+		from := now
+		if schedule.LastChecked != nil {
+			from = *schedule.LastChecked
+		}
+		if from.Before(now.Add(-scheduleLookback)) {
+			from = now.Add(-scheduleLookback)
+		}
+
+		// LastChecked is only persisted along with a handled action; an older
+		// value on disk just rescans a window that held nothing due
+		due := scheduleOccurrences(schedule, from, now)
+		checked := now
+		schedule.LastChecked = &checked
+		if len(due) == 0 {
+			continue
+		}
+		changed = true
+
+		// Only the latest action decides the cluster's state; earlier ones are superseded
+		latest := due[len(due)-1]
+		for _, missed := range due[:len(due)-1] {
+			recordScheduleRun(schedule, types.ScheduleRun{
+				ScaleType:    missed.ScaleType,
+				ScheduledFor: missed.At,
+				HandledAt:    now,
+				Status:       types.ScheduleRunSkipped,
+				CatchUp:      true,
+				Message:      "superseded by a later scheduled action",
+			})
+		}
+
+		late := now.Sub(latest.At)
+		switch {
+		case late <= scheduleOnTimeGrace:
+			actions = append(actions, scheduledAction{schedule: *schedule, due: latest})
+		case schedule.CatchUp == types.CatchUpRunLatest && late <= catchUpWindow(schedule):
+			actions = append(actions, scheduledAction{schedule: *schedule, due: latest, catchUp: true})
+		default:
+			recordScheduleRun(schedule, types.ScheduleRun{
+				ScaleType:    latest.ScaleType,
+				ScheduledFor: latest.At,
+				HandledAt:    now,
+				Status:       types.ScheduleRunSkipped,
+				CatchUp:      true,
+				Message:      fmt.Sprintf("missed by %s while OCD was not running", late.Round(time.Minute)),
+			})
+		}
+	}
+	if changed {
+		s.saveLogged(ctx)
+	}
+	s.mu.Unlock()
+
+	for _, action := range actions {
+		run := s.trigger(ctx, action)
+		s.mu.Lock()
+		if schedule, ok := s.schedules[action.schedule.ID]; ok {
+			recordScheduleRun(schedule, run)
+			s.saveLogged(ctx)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// trigger starts the scaling job of one action with the instance's default credentials
+func (s *ScalingSchedulerImpl) trigger(ctx context.Context, action scheduledAction) types.ScheduleRun {
+	run := types.ScheduleRun{
+		ScaleType:    action.due.ScaleType,
+		ScheduledFor: action.due.At,
+		HandledAt:    time.Now(),
+		CatchUp:      action.catchUp,
+	}
+
+	options := make(map[string]string, len(action.schedule.Options))
+	for key, value := range action.schedule.Options {
+		options[key] = value
+	}
+	reason := "schedule " + action.schedule.ID
+	if action.schedule.Name != "" {
+		reason = fmt.Sprintf("schedule %q (%s)", action.schedule.Name, action.schedule.ID)
+	}
+	if action.schedule.Reason != "" {
+		reason += ": " + action.schedule.Reason
+	}
+	response, err := s.scaling.TriggerScale(ctx, &types.ScaleRequest{
+		ClusterName: action.schedule.ClusterName,
+		ScaleType:   action.due.ScaleType,
+		Account:     action.schedule.Account,
+		Options:     options,
+		Reason:      reason,
+		RequestedBy: "scheduler",
+		Source:      types.ScaleSourceSchedule,
+	})
+	if err != nil {
+		run.Status = types.ScheduleRunFailed
+		run.Message = err.Error()
+		if s.logging != nil {
+			s.logging.LogError(ctx, "scheduled scaling", err, map[string]interface{}{
+				"schedule_id":  action.schedule.ID,
+				"cluster_name": action.schedule.ClusterName,
+				"scale_type":   action.due.ScaleType,
+			})
+		}
+		return run
+	}
+
+	run.Status = types.ScheduleRunTriggered
+	run.Message = response.Message
+	if response.JobStatus != nil {
+		run.JobURL = response.JobStatus.URL
+	}
+	if s.logging != nil {
+		s.logging.LogOperation(ctx, "scheduled scaling", map[string]interface{}{
+			"schedule_id":  action.schedule.ID,
+			"cluster_name": action.schedule.ClusterName,
+			"scale_type":   action.due.ScaleType,
+			"catch_up":     action.catchUp,
+		})
+	}
+	return run
+}
+
+// save writes all schedules to disk; callers hold s.mu
+func (s *ScalingSchedulerImpl) save() error {
+	stored := make([]*types.ScaleSchedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		stored = append(stored, schedule)
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].ID < stored[j].ID })
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return errors.NewConfigurationError("failed to create scaling schedules directory", err)
+	}
+	if err := os.WriteFile(s.path+".tmp", data, 0o644); err != nil {
+		return errors.NewConfigurationError("failed to write scaling schedules", err)
+	}
+	if err := os.Rename(s.path+".tmp", s.path); err != nil {
+		return errors.NewConfigurationError("failed to write scaling schedules", err)
+	}
+	return nil
+}
+
+// saveLogged saves from the scheduler loop, where a failure can only be logged
+func (s *ScalingSchedulerImpl) saveLogged(ctx context.Context) {
+	if err := s.save(); err != nil && s.logging != nil {
+		s.logging.LogError(ctx, "scheduled scaling", err, map[string]interface{}{"path": s.path})
+	}
+}
+
+// normalizeSchedule validates a schedule and fills in defaults
+func normalizeSchedule(schedule *types.ScaleSchedule) error {
+	var problems []string
+
+	schedule.ClusterName = strings.TrimSpace(schedule.ClusterName)
+	schedule.Account = strings.TrimSpace(schedule.Account)
+	if schedule.ClusterName == "" {
+		problems = append(problems, "cluster_name is required")
+	}
+	if schedule.Account == "" {
+		problems = append(problems, "account is required")
+	}
+
+	if strings.TrimSpace(schedule.Timezone) == "" {
+		schedule.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("unknown timezone %q", schedule.Timezone))
+	}
+
+	switch schedule.CatchUp {
+	case "":
+		schedule.CatchUp = types.CatchUpSkip
+	case types.CatchUpSkip, types.CatchUpRunLatest:
+	default:
+		problems = append(problems, fmt.Sprintf("catch_up must be %q or %q", types.CatchUpSkip, types.CatchUpRunLatest))
+	}
+	if schedule.CatchUpWindowMinutes < 0 {
+		problems = append(problems, "catch_up_window_minutes must not be negative")
+	}
+
+	if len(schedule.Rules) == 0 {
+		problems = append(problems, "at least one rule is required")
+	}
+	for i := range schedule.Rules {
+		rule := &schedule.Rules[i]
+		rule.ScaleType = strings.ToLower(strings.TrimSpace(rule.ScaleType))
+		if rule.ScaleType != "up" && rule.ScaleType != "down" {
+			problems = append(problems, fmt.Sprintf("rules[%d].scale_type must be 'up' or 'down'", i))
+		}
+		if _, _, err := parseClock(rule.Time); err != nil {
+			problems = append(problems, fmt.Sprintf("rules[%d].time: %v", i, err))
+		}
+		days, err := expandDays(rule.Days)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("rules[%d].days: %v", i, err))
+		}
+		rule.Days = days
+	}
+
+	for i, holiday := range schedule.Holidays {
+		holiday = strings.TrimSpace(holiday)
+		schedule.Holidays[i] = holiday
+		if _, err := time.Parse("2006-01-02", holiday); err == nil {
+			continue
+		}
+		if _, err := time.Parse("01-02", holiday); err == nil {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("holidays[%d] %q must be YYYY-MM-DD or MM-DD", i, holiday))
+	}
+
+	if len(problems) > 0 {
+		return errors.NewInvalidParametersError("scaling-schedule", strings.Join(problems, "; "), nil)
+	}
+	return nil
+}
+
+// parseClock parses a 24-hour HH:MM time of day
+func parseClock(value string) (int, int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q is not a HH:MM time", value)
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
+
+// expandDays resolves day names and groups into sorted, unique day names
+func expandDays(days []string) ([]string, error) {
+	if len(days) == 0 {
+		return nil, fmt.Errorf("at least one day is required")
+	}
+	seen := make(map[string]bool)
+	for _, name := range days {
+		day := strings.ToLower(strings.TrimSpace(name))
+		if group, ok := scheduleDayGroups[day]; ok {
+			for _, member := range group {
+				seen[member] = true
+			}
+			continue
+		}
+		if len(day) > 3 {
+			day = day[:3] // monday -> mon
+		}
+		if _, ok := scheduleWeekdays[day]; !ok {
+			return nil, fmt.Errorf("unknown day %q", name)
+		}
+		seen[day] = true
+	}
+
+	expanded := make([]string, 0, len(seen))
+	for day := range seen {
+		expanded = append(expanded, day)
+	}
+	sort.Slice(expanded, func(i, j int) bool { return scheduleWeekdays[expanded[i]] < scheduleWeekdays[expanded[j]] })
+	return expanded, nil
+}
+
+// scheduleOccurrences returns the actions of a schedule falling in (after, until],
+// oldest first, in the schedule's timezone and skipping holidays
+func scheduleOccurrences(schedule *types.ScaleSchedule, after, until time.Time) []types.ScheduledScale {
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil
+	}
+	holidays := make(map[string]bool, len(schedule.Holidays))
+	for _, holiday := range schedule.Holidays {
+		holidays[holiday] = true
+	}
+
+	var occurrences []types.ScheduledScale
+	start := after.In(location)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+	for !day.After(until) {
+		if !holidays[day.Format("2006-01-02")] && !holidays[day.Format("01-02")] {
+			for _, rule := range schedule.Rules {
+				if !ruleRunsOn(rule, day.Weekday()) {
+					continue
+				}
+				hour, minute, err := parseClock(rule.Time)
+				if err != nil {
+					continue
+				}
+				at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, location)
+				if at.After(after) && !at.After(until) {
+					occurrences = append(occurrences, types.ScheduledScale{ScaleType: rule.ScaleType, At: at})
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool { return occurrences[i].At.Before(occurrences[j].At) })
+	return occurrences
+}
+
+func ruleRunsOn(rule types.ScheduleRule, weekday time.Weekday) bool {
+	for _, day := range rule.Days {
+		if scheduleWeekdays[day] == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// withNextRun copies a schedule with its next upcoming action filled in
+func withNextRun(schedule *types.ScaleSchedule, now time.Time) types.ScaleSchedule {
+	result := *schedule
+	result.NextRun = nil
+	if !schedule.Paused {
+		// Two weeks ahead covers every weekday plus a run of holidays
+		if upcoming := scheduleOccurrences(schedule, now, now.Add(scheduleLookback)); len(upcoming) > 0 {
+			next := upcoming[0]
+			result.NextRun = &next
+		}
+	}
+	return result
+}
+
+func catchUpWindow(schedule *types.ScaleSchedule) time.Duration {
+	if schedule.CatchUpWindowMinutes > 0 {
+		return time.Duration(schedule.CatchUpWindowMinutes) * time.Minute
+	}
+	return defaultCatchUpWindow
+}
+
+// recordScheduleRun prepends a run record, keeping the newest scheduleRecentRuns
+func recordScheduleRun(schedule *types.ScaleSchedule, run types.ScheduleRun) {
+	schedule.Recent = append([]types.ScheduleRun{run}, schedule.Recent...)
+	if len(schedule.Recent) > scheduleRecentRuns {
+		schedule.Recent = schedule.Recent[:scheduleRecentRuns]
+	}
+}
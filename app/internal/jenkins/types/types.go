@@ -33,10 +33,108 @@ type DeployedArtifact struct {
 
 // ScaleRequest represents a request to scale an EKS cluster
 type ScaleRequest struct {
-	ClusterName string            `json:"cluster_name"`
-	ScaleType   string            `json:"scale_type"` // "up" or "down"
-	Account     string            `json:"account"`
-	Options     map[string]string `json:"options,omitempty"` // Additional scaling options
+	ClusterName  string            `json:"cluster_name"`
+	ScaleType    string            `json:"scale_type"` // "up" or "down"
+	Account      string            `json:"account"`
+	Options      map[string]string `json:"options,omitempty"`      // Additional scaling options
+	Reason       string            `json:"reason,omitempty"`       // why; required to scale down
+	Confirmation string            `json:"confirmation,omitempty"` // typed phrase for production-like clusters
+
+	RequestedBy string `json:"-"` // set by the server, recorded in the audit log
+	Source      string `json:"-"` // ScaleSourceManual or ScaleSourceSchedule
+}
+
+// Where a scaling request came from
+const (
+	ScaleSourceManual   = "manual"
+	ScaleSourceSchedule = "schedule"
+)
+
+// Scaling policy rules a request can violate
+const (
+	ScalePolicyProtected     = "protected_cluster"
+	ScalePolicyConfirmation  = "confirmation_required"
+	ScalePolicyBusinessHours = "business_hours"
+	ScalePolicyReason        = "reason_required"
+)
+
+// ScalePolicyViolation explains why the scaling policy refused a request
+type ScalePolicyViolation struct {
+	ClusterName        string `json:"cluster_name,omitempty"`
+	Rule               string `json:"rule"`
+	Message            string `json:"message"`
+	ConfirmationPhrase string `json:"confirmation_phrase,omitempty"` // what to type, for confirmation_required
+}
+
+// ScaleBatchRequest scales several clusters the same way: the named clusters
+// plus every cluster of Group
+type ScaleBatchRequest struct {
+	Clusters     []string          `json:"clusters,omitempty"`
+	Group        string            `json:"group,omitempty"`
+	ScaleType    string            `json:"scale_type"`
+	Account      string            `json:"account"`
+	Options      map[string]string `json:"options,omitempty"`
+	Reason       string            `json:"reason,omitempty"`
+	Confirmation string            `json:"confirmation,omitempty"` // the batch phrase confirms every production-like cluster
+	Concurrency  int               `json:"concurrency,omitempty"`  // jobs triggered at once
+
+	RequestedBy string `json:"-"`
+	Source      string `json:"-"`
+}
+
+// Per-cluster states of a batch
+const (
+	ScaleBatchTriggered = "triggered" // job queued, status not yet known
+	ScaleBatchRefused   = "refused"   // the scaling policy refused the cluster
+	ScaleBatchFailed    = "failed"    // the job could not be triggered or failed
+	ScaleBatchRunning   = "running"
+	ScaleBatchSucceeded = "succeeded"
+	ScaleBatchUnknown   = "unknown" // triggered, but Jenkins returned no queue item to follow
+)
+
+// ScaleBatchItem is the state of one cluster of a batch
+type ScaleBatchItem struct {
+	ClusterName string                 `json:"cluster_name"`
+	State       string                 `json:"state"`
+	Message     string                 `json:"message,omitempty"`
+	QueueURL    string                 `json:"queue_url,omitempty"`
+	JobStatus   *JobStatus             `json:"job_status,omitempty"`
+	Violations  []ScalePolicyViolation `json:"violations,omitempty"`
+}
+
+// ScaleBatchResult aggregates a batch. Done is set once every cluster is refused,
+// failed or succeeded.
+type ScaleBatchResult struct {
+	BatchID   string           `json:"batch_id"`
+	ScaleType string           `json:"scale_type"`
+	Group     string           `json:"group,omitempty"`
+	Total     int              `json:"total"`
+	Counts    map[string]int   `json:"counts"` // state -> clusters
+	Done      bool             `json:"done"`
+	Items     []ScaleBatchItem `json:"items"`
+	StartedAt time.Time        `json:"started_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// Outcomes recorded in the scaling audit log
+const (
+	ScaleAuditTriggered = "triggered"
+	ScaleAuditRefused   = "refused"
+	ScaleAuditFailed    = "failed"
+)
+
+// ScaleAuditEntry records one scaling attempt: who scaled what, why, and what happened
+type ScaleAuditEntry struct {
+	Time        time.Time `json:"time"`
+	ClusterName string    `json:"cluster_name"`
+	ScaleType   string    `json:"scale_type"`
+	Account     string    `json:"account"`
+	RequestedBy string    `json:"requested_by"`
+	Source      string    `json:"source"`
+	Reason      string    `json:"reason,omitempty"`
+	Outcome     string    `json:"outcome"`
+	Message     string    `json:"message,omitempty"`
+	JobURL      string    `json:"job_url,omitempty"`
 }
 
 // ScaleResponse represents the response from a scaling operation
@@ -47,6 +145,65 @@ type ScaleResponse struct {
 	Metadata  map[string]string `json:"metadata,omitempty"`
 }
 
+// Catch-up policies for scheduled scaling actions missed while OCD was not running
+const (
+	CatchUpSkip      = "skip"       // missed actions are recorded and not run
+	CatchUpRunLatest = "run_latest" // the most recent missed action runs, within the catch-up window
+)
+
+// Outcomes of a scheduled scaling action
+const (
+	ScheduleRunTriggered = "triggered"
+	ScheduleRunFailed    = "failed"
+	ScheduleRunSkipped   = "skipped"
+)
+
+// ScaleSchedule is a recurring scale-up/scale-down plan for one cluster
+type ScaleSchedule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name,omitempty"`
+	ClusterName          string            `json:"cluster_name"`
+	Account              string            `json:"account"`
+	Timezone             string            `json:"timezone"` // IANA name, e.g. Asia/Jerusalem
+	Rules                []ScheduleRule    `json:"rules"`
+	Holidays             []string          `json:"holidays,omitempty"` // 2006-01-02 once, or 01-02 every year
+	CatchUp              string            `json:"catch_up"`
+	CatchUpWindowMinutes int               `json:"catch_up_window_minutes,omitempty"` // run_latest only; default 12h
+	Paused               bool              `json:"paused"`
+	Options              map[string]string `json:"options,omitempty"`
+	Reason               string            `json:"reason,omitempty"`       // recorded on every scaling the schedule triggers
+	Confirmation         string            `json:"confirmation,omitempty"` // typed phrase for production-like clusters; never stored
+	CreatedAt            time.Time         `json:"created_at"`
+	UpdatedAt            time.Time         `json:"updated_at"`
+	LastChecked          *time.Time        `json:"last_checked,omitempty"` // actions up to here have been handled
+	NextRun              *ScheduledScale   `json:"next_run,omitempty"`
+	Recent               []ScheduleRun     `json:"recent,omitempty"` // newest first
+}
+
+// ScheduleRule scales a cluster at a wall-clock time on the given weekdays
+type ScheduleRule struct {
+	ScaleType string   `json:"scale_type"` // "up" or "down"
+	Days      []string `json:"days"`       // mon..sun, or weekdays, weekends, daily
+	Time      string   `json:"time"`       // 15:04 in the schedule timezone
+}
+
+// ScheduledScale is one occurrence of a schedule rule
+type ScheduledScale struct {
+	ScaleType string    `json:"scale_type"`
+	At        time.Time `json:"at"`
+}
+
+// ScheduleRun records what happened to one scheduled action
+type ScheduleRun struct {
+	ScaleType    string    `json:"scale_type"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	HandledAt    time.Time `json:"handled_at"`
+	Status       string    `json:"status"` // triggered, failed or skipped
+	CatchUp      bool      `json:"catch_up,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	JobURL       string    `json:"job_url,omitempty"`
+}
+
 // ArtifactExtractionRequest represents a request to extract artifacts
 type ArtifactExtractionRequest struct {
 	BuildURL    string            `json:"build_url"`
@@ -54,13 +211,87 @@ type ArtifactExtractionRequest struct {
 	Options     map[string]string `json:"options,omitempty"`
 }
 
+// Artifact extraction methods
+const (
+	ExtractionMethodJSONAPI = "json_api"     // build api/json, archived and deployed artifacts
+	ExtractionMethodHTML    = "html_parsing" // "Deployed Artifacts" section of the build page
+)
+
 // ArtifactExtractionResponse represents the response from artifact extraction
 type ArtifactExtractionResponse struct {
-	Artifacts   []DeployedArtifact `json:"artifacts"`
-	TotalCount  int                `json:"total_count"`
-	BuildURL    string             `json:"build_url"`
-	ExtractedAt time.Time          `json:"extracted_at"`
-	Metadata    map[string]string  `json:"metadata,omitempty"`
+	Artifacts        []DeployedArtifact `json:"artifacts"`
+	TotalCount       int                `json:"total_count"`
+	BuildURL         string             `json:"build_url"`
+	ExtractionMethod string             `json:"extraction_method"`
+	ExtractedAt      time.Time          `json:"extracted_at"`
+	Metadata         map[string]string  `json:"metadata,omitempty"`
+}
+
+// ArtifactDownloadRequest selects artifacts of a build to download into the local cache
+type ArtifactDownloadRequest struct {
+	BuildURL string   `json:"build_url"`
+	Patterns []string `json:"patterns"` // glob patterns on artifact names, e.g. "att-orchestration*src.zip"
+}
+
+// CachedArtifact describes an artifact stored in the local cache
+type CachedArtifact struct {
+	Hash         string    `json:"hash"` // SHA-256 of the content, also the cache key
+	Name         string    `json:"name"`
+	URL          string    `json:"url"`
+	Size         int64     `json:"size"`
+	ChecksumType string    `json:"checksum_type,omitempty"` // "sha1" or "md5" when verified against a sibling file
+	Checksum     string    `json:"checksum,omitempty"`
+	Verified     bool      `json:"verified"`
+	CachedAt     time.Time `json:"cached_at"`
+	LastAccess   time.Time `json:"last_access"`
+	DownloadURL  string    `json:"download_url"`
+	CacheHit     bool      `json:"cache_hit"`
+}
+
+// ArtifactDownloadResponse lists the artifacts downloaded or found in the cache
+type ArtifactDownloadResponse struct {
+	BuildURL  string           `json:"build_url"`
+	Artifacts []CachedArtifact `json:"artifacts"`
+	Errors    []string         `json:"errors,omitempty"` // per artifact failures
+}
+
+// BuildDiffRequest identifies the two builds to compare
+type BuildDiffRequest struct {
+	BaseBuildURL   string `json:"base_build_url"`   // e.g. the last good build
+	TargetBuildURL string `json:"target_build_url"` // e.g. the first bad build
+}
+
+// ArtifactChange is an artifact present in both builds at different versions
+type ArtifactChange struct {
+	Key           string           `json:"key"` // artifact name with its version removed
+	BaseVersion   string           `json:"base_version"`
+	TargetVersion string           `json:"target_version"`
+	Base          DeployedArtifact `json:"base"`
+	Target        DeployedArtifact `json:"target"`
+}
+
+// ParameterChange is a build parameter that differs between two builds
+type ParameterChange struct {
+	Name        string `json:"name"`
+	Change      string `json:"change"` // "added", "removed" or "changed"
+	BaseValue   string `json:"base_value,omitempty"`
+	TargetValue string `json:"target_value,omitempty"`
+}
+
+// BuildDiff reports what changed between two builds
+type BuildDiff struct {
+	BaseBuildURL     string             `json:"base_build_url"`
+	TargetBuildURL   string             `json:"target_build_url"`
+	BaseResult       string             `json:"base_result"`
+	TargetResult     string             `json:"target_result"`
+	Added            []DeployedArtifact `json:"added"`
+	Removed          []DeployedArtifact `json:"removed"`
+	Changed          []ArtifactChange   `json:"changed"`
+	UnchangedCount   int                `json:"unchanged_count"`
+	ParameterChanges []ParameterChange  `json:"parameter_changes"`
+	Commits          []ChangeSet        `json:"commits"`
+	CommitsComplete  bool               `json:"commits_complete"` // false when intermediate builds could not all be read
+	ComparedAt       time.Time          `json:"compared_at"`
 }
 
 // AuthCredentials represents Jenkins authentication credentials
@@ -75,6 +306,7 @@ type ClientOptions struct {
 	Timeout       time.Duration     `json:"timeout"`
 	RetryAttempts int               `json:"retry_attempts"`
 	RetryDelay    time.Duration     `json:"retry_delay"`
+	RetryMaxDelay time.Duration     `json:"retry_max_delay"`
 	UserAgent     string            `json:"user_agent"`
 	Headers       map[string]string `json:"headers,omitempty"`
 }
@@ -142,6 +374,90 @@ type JobHealth struct {
 	IconURL     string `json:"icon_url,omitempty"`
 }
 
+// JobHistoryQuery selects a job and filters its builds. Exactly one of JobName
+// (a job from jobs.json), Branch (a customization branch) or JobURL is used.
+type JobHistoryQuery struct {
+	JobName    string            `json:"job_name,omitempty"`
+	Branch     string            `json:"branch,omitempty"`
+	JobURL     string            `json:"job_url,omitempty"`
+	From       int               `json:"from"` // Jenkins build range, newest first
+	To         int               `json:"to"`
+	Result     string            `json:"result,omitempty"` // success, failed, unstable, aborted, running
+	User       string            `json:"user,omitempty"`   // user ID or display name that started the build
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Since      *time.Time        `json:"since,omitempty"`
+	Until      *time.Time        `json:"until,omitempty"`
+}
+
+// BuildHistoryEntry represents one build in a job history
+type BuildHistoryEntry struct {
+	JobStatus
+	TriggeredBy     string            `json:"triggered_by,omitempty"` // user ID of the user that started the build
+	TriggeredByName string            `json:"triggered_by_name,omitempty"`
+	Parameters      map[string]string `json:"parameters,omitempty"`
+}
+
+// JobHistoryPage represents one page of filtered job history
+type JobHistoryPage struct {
+	JobURL   string              `json:"job_url"`
+	From     int                 `json:"from"`
+	To       int                 `json:"to"`
+	NextFrom int                 `json:"next_from,omitempty"` // set when Jenkins has older builds
+	Builds   []BuildHistoryEntry `json:"builds"`
+}
+
+// JobTrend represents aggregate build statistics for a job over a time window
+type JobTrend struct {
+	JobURL                 string         `json:"job_url"`
+	Since                  time.Time      `json:"since"`
+	Until                  time.Time      `json:"until"`
+	TotalBuilds            int            `json:"total_builds"`
+	CompletedBuilds        int            `json:"completed_builds"`
+	StatusCounts           map[string]int `json:"status_counts"`
+	SuccessRate            float64        `json:"success_rate"` // percentage of completed builds
+	FailureRate            float64        `json:"failure_rate"`
+	AverageDurationSeconds float64        `json:"average_duration_seconds"`
+	LastSuccess            *time.Time     `json:"last_success,omitempty"`
+	LastFailure            *time.Time     `json:"last_failure,omitempty"`
+	Truncated              bool           `json:"truncated"` // scan limit reached before the window start
+}
+
+// DiagnosisRequest represents a request to classify a build failure
+type DiagnosisRequest struct {
+	BuildURL string `json:"build_url"`
+	Search   string `json:"search,omitempty"` // optional case-insensitive text to find in the console log
+}
+
+// ConsoleLine represents one line of a Jenkins console log
+type ConsoleLine struct {
+	Number int    `json:"number"` // 1-based
+	Text   string `json:"text"`
+}
+
+// FailureMatch represents a failure signature found in a console log
+type FailureMatch struct {
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	ErrorType   string `json:"error_type"`
+	Suggestion  string `json:"suggestion"`
+	LineNumber  int    `json:"line_number"` // last matching line
+	Occurrences int    `json:"occurrences"`
+	Excerpt     string `json:"excerpt"`
+}
+
+// BuildDiagnosis represents the classified result of a build's console log
+type BuildDiagnosis struct {
+	BuildURL      string         `json:"build_url"`
+	Result        string         `json:"result"`
+	Building      bool           `json:"building"`
+	Diagnosed     bool           `json:"diagnosed"`
+	Primary       *FailureMatch  `json:"primary,omitempty"`
+	Matches       []FailureMatch `json:"matches,omitempty"`
+	SearchResults []ConsoleLine  `json:"search_results,omitempty"`
+	TotalLines    int            `json:"total_lines"`
+	AnalyzedAt    time.Time      `json:"analyzed_at"`
+}
+
 // ServiceStatus represents the overall status of a Jenkins service
 type ServiceStatus struct {
 	Service     string                 `json:"service"`
@@ -151,6 +467,28 @@ type ServiceStatus struct {
 	Metrics     map[string]interface{} `json:"metrics,omitempty"`
 }
 
+// DependencyStatus represents the health of one external dependency
+type DependencyStatus struct {
+	Name        string     `json:"name"`
+	Kind        string     `json:"kind"`   // "jenkins", "http", "binary", "wsl", "file"
+	Status      string     `json:"status"` // "healthy", "unhealthy", "skipped"
+	LatencyMS   int64      `json:"latency_ms"`
+	LastChecked time.Time  `json:"last_checked"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Details     string     `json:"details,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// ConfigStatus describes the installed jobs configuration
+type ConfigStatus struct {
+	Source     string    `json:"source"` // "embedded" or the external file path
+	LoadedAt   time.Time `json:"loaded_at"`
+	Watching   bool      `json:"watching"`
+	Jobs       []string  `json:"jobs"`
+	LastError  string    `json:"last_error,omitempty"` // most recent failed reload
+	LastReload time.Time `json:"last_reload,omitempty"`
+}
+
 // ValidationResult represents the result of parameter validation
 type ValidationResult struct {
 	Valid    bool     `json:"valid"`
@@ -0,0 +1,236 @@
+// Package kube talks to a cluster's Kubernetes API server directly, with the
+// credentials of a kubeconfig context, instead of running kubectl and helm in
+// a login shell and parsing their text output. It covers what OCD deploys and
+// inspects: the microservice custom resource, pods and helm release secrets.
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Client talks to one cluster's API server
+type Client struct {
+	server     string
+	namespace  string
+	token      string
+	username   string
+	password   string
+	exec       *execCredentials
+	httpClient *http.Client
+
+	microserviceAPI  string
+	discoverMu       sync.Mutex
+	microservicePath string // /apis/<group>/<version>, once discovered
+}
+
+// StatusError is returned when the API server answers with an unexpected
+// HTTP status; Reason and Message come from the server's Status object
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Reason     string
+	Message    string
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("Kubernetes API %s %s returned HTTP %d: %s", e.Method, e.Path, e.StatusCode, message)
+}
+
+// IsNotFound reports whether err is a 404 from the API server
+func IsNotFound(err error) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.StatusCode == http.StatusNotFound
+}
+
+// IsForbidden reports whether err is a 403 from the API server, as when the
+// kubeconfig user may not list a resource
+func IsForbidden(err error) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.StatusCode == http.StatusForbidden
+}
+
+// IsConflict reports whether a write was refused because the object changed
+// since it was read: a resource version conflict or a failed JSON patch test
+func IsConflict(err error) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && (statusErr.StatusCode == http.StatusConflict || statusErr.StatusCode == http.StatusUnprocessableEntity)
+}
+
+// New creates a client for the API server of config
+func New(config *Config) (*Client, error) {
+	if config == nil || config.Server == "" {
+		return nil, fmt.Errorf("kubernetes API server is not configured")
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	if len(config.CAData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.CAData) {
+			return nil, fmt.Errorf("kubeconfig certificate authority is not valid PEM")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(config.ClientCertData) > 0 {
+		certificate, err := tls.X509KeyPair(config.ClientCertData, config.ClientKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	transport := &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment}
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig proxy-url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	client := &Client{
+		server:          strings.TrimRight(config.Server, "/"),
+		namespace:       config.Namespace,
+		token:           config.Token,
+		username:        config.Username,
+		password:        config.Password,
+		httpClient:      &http.Client{Timeout: timeout, Transport: transport},
+		microserviceAPI: strings.Trim(config.MicroserviceAPI, "/"),
+	}
+	if config.Exec != nil && config.Token == "" {
+		client.exec = &execCredentials{config: config.Exec}
+	}
+	return client, nil
+}
+
+// Server returns the API server URL
+func (c *Client) Server() string {
+	return c.server
+}
+
+// Namespace returns the namespace of the kubeconfig context, if it names one
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
+// get decodes the JSON answer of a GET into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, "", nil, out)
+}
+
+// do sends a request and decodes the JSON answer into out, if given; a
+// *[]byte receives the body as is. A credential plugin token the server
+// refuses is renewed once.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, contentType string, body []byte, out interface{}) error {
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, path, query, contentType, body, out)
+		statusErr, ok := err.(*StatusError)
+		if ok && statusErr.StatusCode == http.StatusUnauthorized && c.exec != nil && attempt == 0 {
+			c.exec.Reset()
+			continue
+		}
+		return err
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, body []byte, out interface{}) error {
+	target := c.server + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case c.exec != nil:
+		token, err := c.exec.Token(ctx)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+	case c.token != "":
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "":
+		request.SetBasicAuth(c.username, c.password)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("kubernetes API request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		statusErr := &StatusError{Method: method, Path: path, StatusCode: response.StatusCode}
+		var status struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		}
+		if data, _ := io.ReadAll(io.LimitReader(response.Body, 64<<10)); json.Unmarshal(data, &status) == nil {
+			statusErr.Reason, statusErr.Message = status.Reason, status.Message
+		}
+		return statusErr
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, response.Body)
+		return nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		if *raw, err = io.ReadAll(response.Body); err != nil {
+			return fmt.Errorf("failed to read kubernetes API response for %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode kubernetes API response for %s: %w", path, err)
+	}
+	return nil
+}
+
+// listQuery builds the query of a list call
+func listQuery(labelSelector string) url.Values {
+	query := url.Values{}
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+	return query
+}
+
+// namespaced returns the collection path of a resource, across all namespaces
+// when namespace is empty
+func namespaced(prefix, namespace, resource string) string {
+	if namespace == "" {
+		return prefix + "/" + resource
+	}
+	return prefix + "/namespaces/" + url.PathEscape(namespace) + "/" + resource
+}
@@ -0,0 +1,213 @@
+package kube_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app/internal/kube"
+	"app/internal/kube/kubetest"
+)
+
+func newFake(t *testing.T) (*kubetest.FakeServer, *kube.Client) {
+	t.Helper()
+	fake := kubetest.NewFakeServer()
+	t.Cleanup(fake.Close)
+	fake.AddMicroservice("dop", "dop-backend",
+		kube.Container{Name: "wait-for-db", Image: "registry/att/wait:1.0"},
+		kube.Container{Name: "copy-application-files", Image: "registry/att/dop-backend:1.0"},
+		kube.Container{Name: "customization", Image: "registry/att/customization:1.0"})
+	fake.AddMicroservice("dop", "dop-ui", kube.Container{Name: "init", Image: "registry/att/source-code:2.0"})
+	fake.AddMicroservice("qa", "dop-backend", kube.Container{Name: "copy-application-files", Image: "registry/att/dop-backend:0.9"})
+	return fake, fake.Client()
+}
+
+func TestMicroservices(t *testing.T) {
+	_, client := newFake(t)
+	ctx := context.Background()
+
+	microservice, err := client.GetMicroservice(ctx, "dop", "dop-backend")
+	if err != nil {
+		t.Fatalf("GetMicroservice: %v", err)
+	}
+	if len(microservice.InitContainers) != 3 || microservice.InitContainers[1].Image != "registry/att/dop-backend:1.0" {
+		t.Errorf("init containers = %+v", microservice.InitContainers)
+	}
+	if microservice.Namespace != "dop" || microservice.ResourceVersion == "" {
+		t.Errorf("metadata = %s/%s at %q", microservice.Namespace, microservice.Name, microservice.ResourceVersion)
+	}
+
+	if _, err := client.GetMicroservice(ctx, "dop", "missing"); !kube.IsNotFound(err) {
+		t.Errorf("missing microservice error = %v, want a 404", err)
+	}
+
+	namespaced, err := client.ListMicroservices(ctx, "dop")
+	if err != nil {
+		t.Fatalf("ListMicroservices: %v", err)
+	}
+	if len(namespaced) != 2 {
+		t.Errorf("got %d microservices in dop, want 2", len(namespaced))
+	}
+	all, err := client.ListMicroservices(ctx, "")
+	if err != nil {
+		t.Fatalf("ListMicroservices across namespaces: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("got %d microservices across namespaces, want 3", len(all))
+	}
+}
+
+func TestFindInitContainer(t *testing.T) {
+	tests := []struct {
+		name           string
+		initContainers []kube.Container
+		pattern        string
+		wantIndex      int
+		wantDetectedBy string
+	}{
+		{
+			name:           "image wins over an earlier name",
+			initContainers: []kube.Container{{Name: "copy-application-files", Image: "registry/att/base:1.0"}, {Name: "init", Image: "registry/att/source-code:2.0"}},
+			pattern:        kube.ApplicationContainerPattern,
+			wantIndex:      1, wantDetectedBy: "image",
+		},
+		{
+			name:           "name when no image matches",
+			initContainers: []kube.Container{{Name: "wait", Image: "busybox"}, {Name: "copy-application-files", Image: "registry/att/base:1.0"}},
+			pattern:        kube.ApplicationContainerPattern,
+			wantIndex:      1, wantDetectedBy: "name",
+		},
+		{
+			name:           "no match",
+			initContainers: []kube.Container{{Name: "wait", Image: "busybox"}},
+			pattern:        kube.CustomizationContainerPattern,
+			wantIndex:      -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			microservice := kube.Microservice{InitContainers: tt.initContainers}
+			index, detectedBy, err := microservice.FindInitContainer(tt.pattern)
+			if err != nil {
+				t.Fatalf("FindInitContainer: %v", err)
+			}
+			if index != tt.wantIndex || detectedBy != tt.wantDetectedBy {
+				t.Errorf("FindInitContainer = %d, %q, want %d, %q", index, detectedBy, tt.wantIndex, tt.wantDetectedBy)
+			}
+		})
+	}
+
+	if _, _, err := (&kube.Microservice{}).FindInitContainer("("); err == nil {
+		t.Error("an invalid pattern was accepted")
+	}
+}
+
+func TestUpdateInitContainerImage(t *testing.T) {
+	fake, client := newFake(t)
+	ctx := context.Background()
+
+	update, err := client.UpdateInitContainerImage(ctx, "dop", "dop-backend", kube.ApplicationContainerPattern, "registry/att/dop-backend:1.1")
+	if err != nil {
+		t.Fatalf("UpdateInitContainerImage: %v", err)
+	}
+	want := kube.ImageUpdate{
+		Microservice:  "dop-backend",
+		Namespace:     "dop",
+		Container:     "copy-application-files",
+		Index:         1,
+		DetectedBy:    "name",
+		PreviousImage: "registry/att/dop-backend:1.0",
+		Image:         "registry/att/dop-backend:1.1",
+	}
+	if *update != want {
+		t.Errorf("update = %+v, want %+v", *update, want)
+	}
+	patched := fake.Microservice("dop", "dop-backend")
+	if patched.InitContainers[1].Image != "registry/att/dop-backend:1.1" || patched.InitContainers[2].Image != "registry/att/customization:1.0" {
+		t.Errorf("init containers after the patch = %+v", patched.InitContainers)
+	}
+	if other := fake.Microservice("qa", "dop-backend"); other.InitContainers[0].Image != "registry/att/dop-backend:0.9" {
+		t.Errorf("the microservice of another namespace was patched: %+v", other.InitContainers)
+	}
+
+	if _, err := client.UpdateInitContainerImage(ctx, "dop", "dop-ui", kube.CustomizationContainerPattern, "registry/att/customization:2.0"); err == nil {
+		t.Error("a microservice without a matching init container was patched")
+	}
+	if fake.Patches() != 1 {
+		t.Errorf("got %d patches, want 1", fake.Patches())
+	}
+}
+
+func TestPatchInitContainerImageConflict(t *testing.T) {
+	fake, client := newFake(t)
+	ctx := context.Background()
+
+	read, err := client.GetMicroservice(ctx, "dop", "dop-backend")
+	if err != nil {
+		t.Fatalf("GetMicroservice: %v", err)
+	}
+	// Another deploy changes the image between the read and the patch
+	fake.AddMicroservice("dop", "dop-backend",
+		kube.Container{Name: "wait-for-db", Image: "registry/att/wait:1.0"},
+		kube.Container{Name: "copy-application-files", Image: "registry/att/dop-backend:1.5"})
+
+	_, err = client.PatchInitContainerImage(ctx, "dop", "dop-backend", 1, read.InitContainers[1], "registry/att/dop-backend:1.1")
+	if !kube.IsConflict(err) {
+		t.Fatalf("patch of a changed microservice error = %v, want a conflict", err)
+	}
+	if image := fake.Microservice("dop", "dop-backend").InitContainers[1].Image; image != "registry/att/dop-backend:1.5" {
+		t.Errorf("image after the failed patch = %q, want it untouched", image)
+	}
+
+	// A container moved to another index fails the name test the same way
+	_, err = client.PatchInitContainerImage(ctx, "dop", "dop-backend", 0, read.InitContainers[1], "registry/att/dop-backend:1.1")
+	if !kube.IsConflict(err) {
+		t.Errorf("patch of a moved container error = %v, want a conflict", err)
+	}
+	if fake.Patches() != 0 {
+		t.Errorf("got %d patches, want none", fake.Patches())
+	}
+}
+
+func TestListHelmReleases(t *testing.T) {
+	fake, client := newFake(t)
+	deployed := time.Date(2024, 6, 11, 9, 30, 0, 0, time.UTC)
+	for _, release := range []kube.HelmRelease{
+		{Name: "dop", Namespace: "dop", Revision: 1, Status: "superseded", ChartName: "dop", ChartVersion: "10.4.0", Updated: deployed.Add(-time.Hour)},
+		{Name: "dop", Namespace: "dop", Revision: 12, Status: "deployed", ChartName: "dop", ChartVersion: "10.4.2", AppVersion: "10.4", Updated: deployed},
+		{Name: "dop", Namespace: "dop", Revision: 2, Status: "superseded", ChartName: "dop", ChartVersion: "10.4.1", Updated: deployed.Add(-time.Minute)},
+		{Name: "logging", Namespace: "on-logging", Revision: 3, Status: "failed", ChartName: "fluent-bit", ChartVersion: "0.21.0", Updated: deployed},
+		{Name: "auth", Namespace: "dop", Revision: 1, Status: "deployed", ChartName: "auth", ChartVersion: "1.0.0", Updated: deployed},
+	} {
+		fake.AddHelmRelease(release)
+	}
+
+	releases, err := client.ListHelmReleases(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListHelmReleases: %v", err)
+	}
+	want := []kube.HelmRelease{
+		{Name: "auth", Namespace: "dop", Revision: 1, Status: "deployed", ChartName: "auth", ChartVersion: "1.0.0", Updated: deployed},
+		{Name: "dop", Namespace: "dop", Revision: 12, Status: "deployed", ChartName: "dop", ChartVersion: "10.4.2", AppVersion: "10.4", Updated: deployed},
+		{Name: "logging", Namespace: "on-logging", Revision: 3, Status: "failed", ChartName: "fluent-bit", ChartVersion: "0.21.0", Updated: deployed},
+	}
+	if len(releases) != len(want) {
+		t.Fatalf("got %d releases %+v, want %d", len(releases), releases, len(want))
+	}
+	for i := range want {
+		got := releases[i]
+		if got.Name != want[i].Name || got.Namespace != want[i].Namespace || got.Revision != want[i].Revision ||
+			got.Status != want[i].Status || got.Chart() != want[i].Chart() || got.AppVersion != want[i].AppVersion || !got.Updated.Equal(want[i].Updated) {
+			t.Errorf("release %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	namespaced, err := client.ListHelmReleases(context.Background(), "on-logging")
+	if err != nil {
+		t.Fatalf("ListHelmReleases in a namespace: %v", err)
+	}
+	if len(namespaced) != 1 || namespaced[0].Chart() != "fluent-bit-0.21.0" {
+		t.Errorf("releases of on-logging = %+v", namespaced)
+	}
+}
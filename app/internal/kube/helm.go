@@ -0,0 +1,166 @@
+package kube
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// helmReleaseSecretType is the type of the secrets helm 3 stores releases in
+const helmReleaseSecretType = "helm.sh/release.v1"
+
+// HelmRelease is the latest revision of a helm release, as `helm ls` shows it
+type HelmRelease struct {
+	Name         string    `json:"name"`
+	Namespace    string    `json:"namespace"`
+	Revision     int       `json:"revision"`
+	Status       string    `json:"status"` // deployed, failed, pending-upgrade, ...
+	ChartName    string    `json:"chart_name"`
+	ChartVersion string    `json:"chart_version"`
+	AppVersion   string    `json:"app_version,omitempty"`
+	Updated      time.Time `json:"updated"`
+}
+
+// Chart returns the chart as the CHART column of `helm ls` shows it: name-version
+func (r *HelmRelease) Chart() string {
+	return r.ChartName + "-" + r.ChartVersion
+}
+
+// secretObject is the API shape of a secret; data values are base64 in JSON,
+// which decodes into []byte
+type secretObject struct {
+	Metadata objectMeta        `json:"metadata"`
+	Type     string            `json:"type"`
+	Data     map[string][]byte `json:"data"`
+}
+
+// ListHelmReleases reads the latest revision of every helm release of a
+// namespace, or of every namespace when it is empty, from helm's release
+// secrets; releases are sorted by namespace and name
+func (c *Client) ListHelmReleases(ctx context.Context, namespace string) ([]HelmRelease, error) {
+	var list struct {
+		Items []secretObject `json:"items"`
+	}
+	if err := c.get(ctx, namespaced("/api/v1", namespace, "secrets"), listQuery("owner=helm"), &list); err != nil {
+		return nil, err
+	}
+
+	// Every revision is a secret; only the payload of the latest one is decoded
+	latest := make(map[string]*secretObject)
+	revisions := make(map[string]int)
+	for i := range list.Items {
+		secret := &list.Items[i]
+		if secret.Type != helmReleaseSecretType {
+			continue
+		}
+		revision, _ := strconv.Atoi(secret.Metadata.Labels["version"])
+		key := secret.Metadata.Namespace + "/" + secret.Metadata.Labels["name"]
+		if current, ok := latest[key]; !ok || revision > revisions[key] || (revision == revisions[key] && secret.Metadata.Name > current.Metadata.Name) {
+			latest[key] = secret
+			revisions[key] = revision
+		}
+	}
+
+	releases := make([]HelmRelease, 0, len(latest))
+	for _, secret := range latest {
+		release, err := decodeHelmRelease(secret.Data["release"])
+		if err != nil {
+			return nil, fmt.Errorf("helm release secret %s/%s: %w", secret.Metadata.Namespace, secret.Metadata.Name, err)
+		}
+		if release.Namespace == "" {
+			release.Namespace = secret.Metadata.Namespace
+		}
+		releases = append(releases, *release)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Namespace != releases[j].Namespace {
+			return releases[i].Namespace < releases[j].Namespace
+		}
+		return releases[i].Name < releases[j].Name
+	})
+	return releases, nil
+}
+
+// decodeHelmRelease decodes helm's release encoding: base64 of gzipped JSON
+func decodeHelmRelease(data []byte) (*HelmRelease, error) {
+	compressed, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release encoding: %w", err)
+	}
+	payload := compressed
+	if bytes.HasPrefix(compressed, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, fmt.Errorf("invalid release compression: %w", err)
+		}
+		defer reader.Close()
+		if payload, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("invalid release compression: %w", err)
+		}
+	}
+
+	var release struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		Version   int    `json:"version"`
+		Info      struct {
+			Status       string    `json:"status"`
+			LastDeployed time.Time `json:"last_deployed"`
+		} `json:"info"`
+		Chart struct {
+			Metadata struct {
+				Name       string `json:"name"`
+				Version    string `json:"version"`
+				AppVersion string `json:"appVersion"`
+			} `json:"metadata"`
+		} `json:"chart"`
+	}
+	if err := json.Unmarshal(payload, &release); err != nil {
+		return nil, fmt.Errorf("invalid release payload: %w", err)
+	}
+	return &HelmRelease{
+		Name:         release.Name,
+		Namespace:    release.Namespace,
+		Revision:     release.Version,
+		Status:       release.Info.Status,
+		ChartName:    release.Chart.Metadata.Name,
+		ChartVersion: release.Chart.Metadata.Version,
+		AppVersion:   release.Chart.Metadata.AppVersion,
+		Updated:      release.Info.LastDeployed,
+	}, nil
+}
+
+// EncodeHelmRelease encodes a release the way helm stores it in the release
+// secret, for fakes and fixtures
+func EncodeHelmRelease(release HelmRelease) ([]byte, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"name":      release.Name,
+		"namespace": release.Namespace,
+		"version":   release.Revision,
+		"info":      map[string]interface{}{"status": release.Status, "last_deployed": release.Updated},
+		"chart": map[string]interface{}{"metadata": map[string]interface{}{
+			"name":       release.ChartName,
+			"version":    release.ChartVersion,
+			"appVersion": release.AppVersion,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(compressed.Bytes())), nil
+}
@@ -0,0 +1,340 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config describes how to reach one cluster's API server, resolved from a
+// kubeconfig context
+type Config struct {
+	Server             string // scheme://host[:port] of the API server
+	Namespace          string // the context's namespace, if any
+	CAData             []byte // PEM; the system roots are used when empty
+	InsecureSkipVerify bool
+	ClientCertData     []byte // PEM client certificate and key, for certificate auth
+	ClientKeyData      []byte
+	Token              string
+	Username           string
+	Password           string
+	Exec               *ExecConfig // credential plugin, e.g. `aws eks get-token`
+	ProxyURL           string      // the environment's proxy is used when empty
+
+	// MicroserviceAPI is the group/version of the microservice resource, e.g.
+	// "example.com/v1"; it is discovered from the server when empty
+	MicroserviceAPI string
+	Timeout         time.Duration
+}
+
+// ExecConfig runs a client-go credential plugin for a bearer token
+type ExecConfig struct {
+	APIVersion string
+	Command    string
+	Args       []string
+	Env        map[string]string
+	Dir        string // where relative commands are resolved: the kubeconfig's directory
+	Shell      Shell  // runs the plugin in this shell instead of directly, when set
+}
+
+// Shell builds the command that runs a bash command line in the environment
+// the deploy scripts run in, e.g. `wsl --user k8s bash -l -c` with the proxy
+// on, so kubectl and credential plugins there see what the scripts see
+type Shell func(ctx context.Context, commandLine string) *exec.Cmd
+
+// kubeconfig is the part of a kubeconfig file OCD reads
+type kubeconfig struct {
+	CurrentContext string `json:"current-context"`
+	Clusters       []struct {
+		Name    string `json:"name"`
+		Cluster struct {
+			Server                   string `json:"server"`
+			CertificateAuthority     string `json:"certificate-authority"`
+			CertificateAuthorityData string `json:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+			ProxyURL                 string `json:"proxy-url"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+	Contexts []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster   string `json:"cluster"`
+			User      string `json:"user"`
+			Namespace string `json:"namespace"`
+		} `json:"context"`
+	} `json:"contexts"`
+	Users []struct {
+		Name string `json:"name"`
+		User struct {
+			Token                 string `json:"token"`
+			TokenFile             string `json:"tokenFile"`
+			ClientCertificate     string `json:"client-certificate"`
+			ClientCertificateData string `json:"client-certificate-data"`
+			ClientKey             string `json:"client-key"`
+			ClientKeyData         string `json:"client-key-data"`
+			Username              string `json:"username"`
+			Password              string `json:"password"`
+			Exec                  *struct {
+				APIVersion string   `json:"apiVersion"`
+				Command    string   `json:"command"`
+				Args       []string `json:"args"`
+				Env        []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"env"`
+			} `json:"exec"`
+		} `json:"user"`
+	} `json:"users"`
+}
+
+// DefaultKubeconfigPath returns the first file of $KUBECONFIG, or ~/.kube/config,
+// of the user OCD runs as; under WSL the deploy scripts use another one (see
+// NewProvider)
+func DefaultKubeconfigPath() string {
+	if paths := filepath.SplitList(os.Getenv("KUBECONFIG")); len(paths) > 0 && paths[0] != "" {
+		return paths[0]
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+// LoadConfig resolves a context of the kubeconfig at path; an empty path uses
+// DefaultKubeconfigPath and an empty context the file's current context
+func LoadConfig(path, contextName string) (*Config, error) {
+	if path == "" {
+		path = DefaultKubeconfigPath()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	return ParseConfig(data, contextName, filepath.Dir(path))
+}
+
+// ParseConfig resolves a context of kubeconfig content, YAML or JSON; relative
+// file references are resolved against dir
+func ParseConfig(data []byte, contextName, dir string) (*Config, error) {
+	var file kubeconfig
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) {
+		if err := json.Unmarshal(trimmed, &file); err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+		}
+	} else {
+		decoded, err := decodeYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+		}
+		encoded, err := json.Marshal(decoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+		}
+		if err := json.Unmarshal(encoded, &file); err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+		}
+	}
+
+	if contextName == "" {
+		contextName = file.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("kubeconfig has no current context")
+	}
+	resolve := func(name string) string {
+		if name == "" || filepath.IsAbs(name) {
+			return name
+		}
+		return filepath.Join(dir, name)
+	}
+
+	config := &Config{}
+	var clusterName, userName string
+	found := false
+	for _, named := range file.Contexts {
+		if named.Name == contextName {
+			clusterName, userName = named.Context.Cluster, named.Context.User
+			config.Namespace = named.Context.Namespace
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+
+	found = false
+	for _, named := range file.Clusters {
+		if named.Name != clusterName {
+			continue
+		}
+		cluster := named.Cluster
+		config.Server = strings.TrimRight(cluster.Server, "/")
+		config.InsecureSkipVerify = cluster.InsecureSkipTLSVerify
+		config.ProxyURL = cluster.ProxyURL
+		var err error
+		if config.CAData, err = dataOrFile(cluster.CertificateAuthorityData, resolve(cluster.CertificateAuthority)); err != nil {
+			return nil, fmt.Errorf("cluster %q: %w", clusterName, err)
+		}
+		found = true
+		break
+	}
+	if !found || config.Server == "" {
+		return nil, fmt.Errorf("cluster %q of context %q not found in kubeconfig", clusterName, contextName)
+	}
+
+	for _, named := range file.Users {
+		if named.Name != userName {
+			continue
+		}
+		user := named.User
+		config.Token = user.Token
+		if config.Token == "" && user.TokenFile != "" {
+			token, err := os.ReadFile(resolve(user.TokenFile))
+			if err != nil {
+				return nil, fmt.Errorf("user %q: failed to read token file: %w", userName, err)
+			}
+			config.Token = strings.TrimSpace(string(token))
+		}
+		var err error
+		if config.ClientCertData, err = dataOrFile(user.ClientCertificateData, resolve(user.ClientCertificate)); err != nil {
+			return nil, fmt.Errorf("user %q: %w", userName, err)
+		}
+		if config.ClientKeyData, err = dataOrFile(user.ClientKeyData, resolve(user.ClientKey)); err != nil {
+			return nil, fmt.Errorf("user %q: %w", userName, err)
+		}
+		config.Username, config.Password = user.Username, user.Password
+		if user.Exec != nil && user.Exec.Command != "" {
+			config.Exec = &ExecConfig{
+				APIVersion: user.Exec.APIVersion,
+				Command:    user.Exec.Command,
+				Args:       user.Exec.Args,
+				Env:        make(map[string]string),
+				Dir:        dir,
+			}
+			for _, variable := range user.Exec.Env {
+				config.Exec.Env[variable.Name] = variable.Value
+			}
+		}
+		break
+	}
+	return config, nil
+}
+
+// dataOrFile returns base64 inline data, or the content of the file it refers to
+func dataOrFile(data, file string) ([]byte, error) {
+	if data != "" {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 data: %w", err)
+		}
+		return decoded, nil
+	}
+	if file == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return content, nil
+}
+
+// execTokenRefreshMargin is how long before its expiry a plugin token is renewed
+const execTokenRefreshMargin = time.Minute
+
+// execTokenDefaultLifetime is how long a token without an expiry is reused
+const execTokenDefaultLifetime = 10 * time.Minute
+
+// execCredentials caches the token of a credential plugin until it expires
+type execCredentials struct {
+	config *ExecConfig
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Token returns the cached token or runs the plugin for a new one
+func (c *execCredentials) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expires.Add(-execTokenRefreshMargin)) {
+		return c.token, nil
+	}
+
+	apiVersion := c.config.APIVersion
+	if apiVersion == "" {
+		apiVersion = "client.authentication.k8s.io/v1beta1"
+	}
+	execInfo, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]interface{}{"interactive": false},
+	})
+	env := []string{"KUBERNETES_EXEC_INFO=" + string(execInfo)}
+	for name, value := range c.config.Env {
+		env = append(env, name+"="+value)
+	}
+
+	var command *exec.Cmd
+	if c.config.Shell != nil {
+		words := append([]string{"env"}, env...)
+		words = append(words, c.config.Command)
+		words = append(words, c.config.Args...)
+		for i := range words {
+			words[i] = shellQuote(words[i])
+		}
+		command = c.config.Shell(ctx, strings.Join(words, " "))
+	} else {
+		name := c.config.Command
+		if strings.ContainsRune(name, filepath.Separator) && !filepath.IsAbs(name) {
+			name = filepath.Join(c.config.Dir, name)
+		}
+		command = exec.CommandContext(ctx, name, c.config.Args...)
+		command.Env = append(os.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		return "", fmt.Errorf("credential plugin %s failed: %v: %s", c.config.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credential struct {
+		Status struct {
+			Token               string    `json:"token"`
+			ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(output, &credential); err != nil || credential.Status.Token == "" {
+		return "", fmt.Errorf("credential plugin %s returned no token", c.config.Command)
+	}
+	c.token = credential.Status.Token
+	c.expires = credential.Status.ExpirationTimestamp
+	if c.expires.IsZero() {
+		c.expires = time.Now().Add(execTokenDefaultLifetime)
+	}
+	return c.token, nil
+}
+
+// Reset drops the cached token, e.g. after the server rejected it
+func (c *execCredentials) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+}
+
+// shellQuote quotes a word for bash so nothing in it is expanded
+func shellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
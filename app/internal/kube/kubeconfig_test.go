@@ -0,0 +1,308 @@
+package kube
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const clusterARN = "arn:aws:eks:us-east-1:123456789012:cluster/dop-dev"
+
+// eksKubeconfig is written the way `aws eks update-kubeconfig` writes it:
+// sequences at their key's indentation, unquoted ARNs and an exec credential
+var eksKubeconfig = `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: ` + base64.StdEncoding.EncodeToString([]byte("ca-pem")) + `
+    server: https://0123456789ABCDEF.gr7.us-east-1.eks.amazonaws.com
+  name: ` + clusterARN + `
+contexts:
+- context:
+    cluster: ` + clusterARN + `
+    user: ` + clusterARN + `
+  name: ` + clusterARN + `
+current-context: ` + clusterARN + `
+kind: Config
+preferences: {}
+users:
+- name: ` + clusterARN + `
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      args:
+      - --region
+      - us-east-1
+      - eks
+      - get-token
+      - --cluster-name
+      - dop-dev
+      - --output
+      - json
+      command: aws
+      env:
+      - name: AWS_PROFILE
+        value: dop
+`
+
+// quotedKubeconfig holds two contexts with quoted ARNs, indented sequences
+// and comments, as a kubeconfig edited by hand or merged by kubectl may
+const quotedKubeconfig = `# merged by kubectl
+apiVersion: v1
+kind: Config
+current-context: "arn:aws:eks:us-east-1:123456789012:cluster/dop-dev"
+clusters:
+  - name: "arn:aws:eks:us-east-1:123456789012:cluster/dop-dev"
+    cluster:
+      server: "https://dev.example.com:443/"
+      insecure-skip-tls-verify: true
+  - name: 'arn:aws:eks:us-east-1:123456789012:cluster/dop-qa'
+    cluster:
+      server: https://qa.example.com # the QA cluster
+      proxy-url: http://proxy.example.com:8080
+contexts:
+  - name: "arn:aws:eks:us-east-1:123456789012:cluster/dop-dev"
+    context:
+      cluster: "arn:aws:eks:us-east-1:123456789012:cluster/dop-dev"
+      user: dev-token
+      namespace: dop
+  - name: qa
+    context:
+      cluster: 'arn:aws:eks:us-east-1:123456789012:cluster/dop-qa'
+      user: qa-token-file
+users:
+  - name: dev-token
+    user:
+      token: "abc#123"
+  - name: qa-token-file
+    user:
+      tokenFile: token
+`
+
+func TestParseConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("qa-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		kubeconfig   string
+		context      string
+		wantServer   string
+		wantToken    string
+		wantNS       string
+		wantInsecure bool
+		wantProxy    string
+		wantCA       string
+		wantExec     *ExecConfig
+		wantErr      string
+	}{
+		{
+			name:       "aws eks update-kubeconfig output",
+			kubeconfig: eksKubeconfig,
+			wantServer: "https://0123456789ABCDEF.gr7.us-east-1.eks.amazonaws.com",
+			wantCA:     "ca-pem",
+			wantExec: &ExecConfig{
+				APIVersion: "client.authentication.k8s.io/v1beta1",
+				Command:    "aws",
+				Args:       []string{"--region", "us-east-1", "eks", "get-token", "--cluster-name", "dop-dev", "--output", "json"},
+				Env:        map[string]string{"AWS_PROFILE": "dop"},
+				Dir:        dir,
+			},
+		},
+		{
+			name:         "quoted ARNs and the current context",
+			kubeconfig:   quotedKubeconfig,
+			wantServer:   "https://dev.example.com:443",
+			wantToken:    "abc#123",
+			wantNS:       "dop",
+			wantInsecure: true,
+		},
+		{
+			name:       "named context with a relative token file",
+			kubeconfig: quotedKubeconfig,
+			context:    "qa",
+			wantServer: "https://qa.example.com",
+			wantToken:  "qa-token",
+			wantProxy:  "http://proxy.example.com:8080",
+		},
+		{
+			name:       "unknown context",
+			kubeconfig: eksKubeconfig,
+			context:    "arn:aws:eks:us-east-1:123456789012:cluster/missing",
+			wantErr:    "not found in kubeconfig",
+		},
+		{
+			name:       "no current context",
+			kubeconfig: "apiVersion: v1\nkind: Config\nclusters: []\n",
+			wantErr:    "no current context",
+		},
+		{
+			name:       "JSON",
+			kubeconfig: `{"current-context": "c", "contexts": [{"name": "c", "context": {"cluster": "k", "user": "u"}}], "clusters": [{"name": "k", "cluster": {"server": "https://json.example.com"}}], "users": [{"name": "u", "user": {"token": "t"}}]}`,
+			wantServer: "https://json.example.com",
+			wantToken:  "t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseConfig([]byte(tt.kubeconfig), tt.context, dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConfig: %v", err)
+			}
+			if config.Server != tt.wantServer {
+				t.Errorf("Server = %q, want %q", config.Server, tt.wantServer)
+			}
+			if config.Token != tt.wantToken {
+				t.Errorf("Token = %q, want %q", config.Token, tt.wantToken)
+			}
+			if config.Namespace != tt.wantNS {
+				t.Errorf("Namespace = %q, want %q", config.Namespace, tt.wantNS)
+			}
+			if config.InsecureSkipVerify != tt.wantInsecure {
+				t.Errorf("InsecureSkipVerify = %v, want %v", config.InsecureSkipVerify, tt.wantInsecure)
+			}
+			if config.ProxyURL != tt.wantProxy {
+				t.Errorf("ProxyURL = %q, want %q", config.ProxyURL, tt.wantProxy)
+			}
+			if string(config.CAData) != tt.wantCA {
+				t.Errorf("CAData = %q, want %q", config.CAData, tt.wantCA)
+			}
+			got, _ := json.Marshal(config.Exec)
+			want, _ := json.Marshal(tt.wantExec)
+			if string(got) != string(want) {
+				t.Errorf("Exec = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    string // the decoded value as JSON
+		wantErr string
+	}{
+		{
+			name: "sequence at the key's indentation",
+			yaml: "args:\n- get-token\n- --cluster-name\nname: x\n",
+			want: `{"args":["get-token","--cluster-name"],"name":"x"}`,
+		},
+		{
+			name: "indented sequence of mappings",
+			yaml: "env:\n  - name: A\n    value: \"1\"\n  - name: B\n    value: two\n",
+			want: `{"env":[{"name":"A","value":"1"},{"name":"B","value":"two"}]}`,
+		},
+		{
+			name: "bare dash opens a nested mapping",
+			yaml: "items:\n-\n  name: a\n",
+			want: `{"items":[{"name":"a"}]}`,
+		},
+		{
+			name: "colons inside values do not split",
+			yaml: "server: https://host:443\nname: " + clusterARN + "\n",
+			want: `{"name":"` + clusterARN + `","server":"https://host:443"}`,
+		},
+		{
+			name: "quoted keys and scalars",
+			yaml: "\"a: b\": 'it''s'\nc: \"tab\\tand # hash\"\n",
+			want: `{"a: b":"it's","c":"tab\tand # hash"}`,
+		},
+		{
+			name: "scalars, empty collections and flow sequences",
+			yaml: "t: true\nf: False\nn: null\nm: {}\ns: []\nflow: [a, \"b\"]\nempty:\n",
+			want: `{"empty":null,"f":false,"flow":["a","b"],"m":{},"n":null,"s":[],"t":true}`,
+		},
+		{
+			name: "comments, document marker and CRLF",
+			yaml: "---\r\n# header\r\nkey: value # trailing\r\n",
+			want: `{"key":"value"}`,
+		},
+		{
+			name: "empty document",
+			yaml: "# nothing\n",
+			want: `null`,
+		},
+		{
+			name:    "tab indentation",
+			yaml:    "a:\n\tb: c\n",
+			wantErr: "tabs are not allowed",
+		},
+		{
+			name:    "block scalar",
+			yaml:    "a: |\n  text\n",
+			wantErr: "unsupported YAML construct",
+		},
+		{
+			name:    "sequence item among keys",
+			yaml:    "a:\n  b: c\n  - d\n",
+			wantErr: "sequence item where a key was expected",
+		},
+		{
+			name:    "line without a key",
+			yaml:    "a: b\njust text\n",
+			wantErr: "expected \"key: value\"",
+		},
+		{
+			name:    "dedent below the document",
+			yaml:    "  a: b\nc: d\n",
+			wantErr: "unexpected indentation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := decodeYAML([]byte(tt.yaml))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeYAML: %v", err)
+			}
+			got, _ := json.Marshal(value)
+			if string(got) != tt.want {
+				t.Errorf("decoded %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitYAMLEntry(t *testing.T) {
+	tests := []struct {
+		text    string
+		key     string
+		rest    string
+		isEntry bool
+	}{
+		{text: "server: https://host:443", key: "server", rest: "https://host:443", isEntry: true},
+		{text: "name: " + clusterARN, key: "name", rest: clusterARN, isEntry: true},
+		{text: "clusters:", key: "clusters", isEntry: true},
+		{text: "\"" + clusterARN + "\": x", key: clusterARN, rest: "x", isEntry: true},
+		{text: "'quoted':", key: "quoted", isEntry: true},
+		{text: clusterARN},
+		{text: "\"unterminated: x"},
+		{text: "\"quoted\" trailing"},
+		{text: ":"},
+	}
+
+	for _, tt := range tests {
+		key, rest, isEntry := splitYAMLEntry(tt.text)
+		if key != tt.key || rest != tt.rest || isEntry != tt.isEntry {
+			t.Errorf("splitYAMLEntry(%q) = %q, %q, %v, want %q, %q, %v", tt.text, key, rest, isEntry, tt.key, tt.rest, tt.isEntry)
+		}
+	}
+}
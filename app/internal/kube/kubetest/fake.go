@@ -0,0 +1,528 @@
+// Package kubetest provides an in-process fake Kubernetes API server for
+// exercising the kube client and its callers without a cluster.
+package kubetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"app/internal/kube"
+)
+
+// MicroserviceAPI is the group/version the fake serves microservices under
+const MicroserviceAPI = "ocd.example.com/v1"
+
+// Token is the bearer token the fake expects from the client
+const Token = "kubetest-token"
+
+// FakeServer is an in-process API server serving discovery, namespaces,
+// microservices (get, list, JSON patch), pods with their events and logs, and
+// helm release secrets
+type FakeServer struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	microservices   map[string]map[string]interface{} // by namespace/name
+	pods            map[string]map[string]interface{}
+	secrets         map[string]map[string]interface{}
+	events          map[string]map[string]interface{}
+	namespaces      map[string]bool   // added without objects
+	logs            map[string]string // by namespace/pod/container, "/previous" appended for the last run
+	resourceVersion int
+	patches         int
+}
+
+// NewFakeServer starts an empty fake API server
+func NewFakeServer() *FakeServer {
+	fake := &FakeServer{
+		microservices: make(map[string]map[string]interface{}),
+		pods:          make(map[string]map[string]interface{}),
+		secrets:       make(map[string]map[string]interface{}),
+		events:        make(map[string]map[string]interface{}),
+		namespaces:    make(map[string]bool),
+		logs:          make(map[string]string),
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	return fake
+}
+
+// Config returns a client configuration for the fake
+func (f *FakeServer) Config() *kube.Config {
+	return &kube.Config{Server: f.URL, Token: Token}
+}
+
+// Client returns a kube client connected to the fake
+func (f *FakeServer) Client() *kube.Client {
+	client, err := kube.New(f.Config())
+	if err != nil {
+		panic(err) // the fake's configuration is always valid
+	}
+	return client
+}
+
+// AddNamespace adds an empty namespace; namespaces holding objects are listed
+// without being added
+func (f *FakeServer) AddNamespace(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.namespaces[name] = true
+}
+
+// AddMicroservice adds or replaces a microservice with the given init containers
+func (f *FakeServer) AddMicroservice(namespace, name string, initContainers ...kube.Container) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.microservices[namespace+"/"+name] = map[string]interface{}{
+		"apiVersion": MicroserviceAPI,
+		"kind":       "Microservice",
+		"metadata":   f.metadata(namespace, name, nil),
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"initContainers": toGeneric(initContainers),
+			"containers":     toGeneric([]kube.Container{{Name: name, Image: name + ":latest"}}),
+		}}},
+	}
+}
+
+// Microservice returns a microservice as the client would read it, or nil
+func (f *FakeServer) Microservice(namespace, name string) *kube.Microservice {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	object, ok := f.microservices[namespace+"/"+name]
+	if !ok {
+		return nil
+	}
+	spec := object["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	var microservice kube.Microservice
+	fromGeneric(spec["initContainers"], &microservice.InitContainers)
+	fromGeneric(spec["containers"], &microservice.Containers)
+	microservice.Name, microservice.Namespace = name, namespace
+	microservice.ResourceVersion = object["metadata"].(map[string]interface{})["resourceVersion"].(string)
+	return &microservice
+}
+
+// Patches returns how many patches were applied
+func (f *FakeServer) Patches() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.patches
+}
+
+// AddPod adds or replaces a pod; containers are reported with the given state
+func (f *FakeServer) AddPod(pod kube.Pod) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ready := "False"
+	if pod.Ready {
+		ready = "True"
+	}
+	created := pod.CreatedAt
+	if created.IsZero() {
+		created = time.Now()
+	}
+	metadata := f.metadata(pod.Namespace, pod.Name, pod.Labels)
+	metadata["creationTimestamp"] = created.UTC().Format(time.RFC3339)
+	specs := func(statuses []kube.ContainerStatus) []interface{} {
+		var containers []kube.Container
+		for _, status := range statuses {
+			containers = append(containers, kube.Container{Name: status.Name, Image: status.Image})
+		}
+		return toGeneric(containers)
+	}
+	f.pods[pod.Namespace+"/"+pod.Name] = map[string]interface{}{
+		"metadata": metadata,
+		"spec": map[string]interface{}{
+			"nodeName":       pod.NodeName,
+			"initContainers": specs(pod.InitContainers),
+			"containers":     specs(pod.Containers),
+		},
+		"status": map[string]interface{}{
+			"phase":                 pod.Phase,
+			"conditions":            []interface{}{map[string]interface{}{"type": "Ready", "status": ready}},
+			"initContainerStatuses": containerStatuses(pod.InitContainers),
+			"containerStatuses":     containerStatuses(pod.Containers),
+		},
+	}
+}
+
+// AddHelmRelease stores a release revision the way helm does, in a secret
+func (f *FakeServer) AddHelmRelease(release kube.HelmRelease) {
+	encoded, err := kube.EncodeHelmRelease(release)
+	if err != nil {
+		panic(err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := fmt.Sprintf("sh.helm.release.v1.%s.v%d", release.Name, release.Revision)
+	f.secrets[release.Namespace+"/"+name] = map[string]interface{}{
+		"metadata": f.metadata(release.Namespace, name, map[string]string{
+			"owner":   "helm",
+			"name":    release.Name,
+			"status":  release.Status,
+			"version": strconv.Itoa(release.Revision),
+		}),
+		"type": "helm.sh/release.v1",
+		"data": map[string]interface{}{"release": encoded}, // []byte marshals as base64, like the API
+	}
+}
+
+// AddEvent records an event about a pod or other object of a namespace
+func (f *FakeServer) AddEvent(namespace, objectName string, event kube.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if event.LastSeen.IsZero() {
+		event.LastSeen = time.Now()
+	}
+	name := fmt.Sprintf("%s.%d", objectName, f.resourceVersion+1)
+	f.events[namespace+"/"+name] = map[string]interface{}{
+		"metadata":       f.metadata(namespace, name, nil),
+		"involvedObject": map[string]interface{}{"name": objectName, "namespace": namespace},
+		"type":           event.Type,
+		"reason":         event.Reason,
+		"message":        event.Message,
+		"count":          event.Count,
+		"lastTimestamp":  event.LastSeen.UTC().Format(time.RFC3339),
+	}
+}
+
+// SetLogs sets the log of a container; previous sets the log of its last
+// terminated run
+func (f *FakeServer) SetLogs(namespace, pod, container, logs string, previous bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := namespace + "/" + pod + "/" + container
+	if previous {
+		key += "/previous"
+	}
+	f.logs[key] = logs
+}
+
+func (f *FakeServer) metadata(namespace, name string, labels map[string]string) map[string]interface{} {
+	f.resourceVersion++
+	metadata := map[string]interface{}{
+		"name":            name,
+		"namespace":       namespace,
+		"resourceVersion": strconv.Itoa(f.resourceVersion),
+	}
+	if labels != nil {
+		metadata["labels"] = labels
+	}
+	return metadata
+}
+
+func (f *FakeServer) serve(response http.ResponseWriter, request *http.Request) {
+	if request.Header.Get("Authorization") != "Bearer "+Token {
+		writeStatus(response, http.StatusUnauthorized, "Unauthorized", "Unauthorized")
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimSuffix(request.URL.Path, "/")
+	switch {
+	case path == "/apis":
+		group, version, _ := strings.Cut(MicroserviceAPI, "/")
+		writeJSON(response, map[string]interface{}{"groups": []interface{}{map[string]interface{}{
+			"name":             group,
+			"versions":         []interface{}{map[string]interface{}{"groupVersion": MicroserviceAPI, "version": version}},
+			"preferredVersion": map[string]interface{}{"groupVersion": MicroserviceAPI, "version": version},
+		}}})
+	case path == "/apis/"+MicroserviceAPI:
+		writeJSON(response, map[string]interface{}{
+			"groupVersion": MicroserviceAPI,
+			"resources":    []interface{}{map[string]interface{}{"name": kube.MicroserviceResource, "namespaced": true, "kind": "Microservice"}},
+		})
+	case strings.HasPrefix(path, "/apis/"+MicroserviceAPI+"/"):
+		f.serveObjects(response, request, strings.TrimPrefix(path, "/apis/"+MicroserviceAPI), kube.MicroserviceResource, f.microservices)
+	case path == "/api/v1/namespaces":
+		f.serveNamespaces(response)
+	case strings.HasPrefix(path, "/api/v1/namespaces/") && strings.HasSuffix(path, "/log"):
+		f.serveLogs(response, request, strings.Split(strings.TrimPrefix(path, "/api/v1/namespaces/"), "/"))
+	case strings.HasPrefix(path, "/api/v1/") && strings.HasSuffix(path, "/events"):
+		f.serveObjects(response, request, strings.TrimPrefix(path, "/api/v1"), "events", f.events)
+	case strings.HasPrefix(path, "/api/v1/") && strings.HasSuffix(path, "/pods"):
+		f.serveObjects(response, request, strings.TrimPrefix(path, "/api/v1"), "pods", f.pods)
+	case strings.HasPrefix(path, "/api/v1/") && strings.HasSuffix(path, "/secrets"):
+		f.serveObjects(response, request, strings.TrimPrefix(path, "/api/v1"), "secrets", f.secrets)
+	default:
+		writeStatus(response, http.StatusNotFound, "NotFound", "the server could not find the requested resource")
+	}
+}
+
+// serveNamespaces lists the added namespaces and those holding objects
+func (f *FakeServer) serveNamespaces(response http.ResponseWriter) {
+	names := make(map[string]bool, len(f.namespaces))
+	for name := range f.namespaces {
+		names[name] = true
+	}
+	for _, objects := range []map[string]map[string]interface{}{f.microservices, f.pods, f.secrets, f.events} {
+		for key := range objects {
+			namespace, _, _ := strings.Cut(key, "/")
+			names[namespace] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	items := []interface{}{}
+	for _, name := range sorted {
+		items = append(items, map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"status":   map[string]interface{}{"phase": "Active"},
+		})
+	}
+	writeJSON(response, map[string]interface{}{"items": items})
+}
+
+// serveLogs serves <namespace>/pods/<pod>/log as plain text
+func (f *FakeServer) serveLogs(response http.ResponseWriter, request *http.Request, parts []string) {
+	if len(parts) != 4 || parts[1] != "pods" {
+		writeStatus(response, http.StatusNotFound, "NotFound", "the server could not find the requested resource")
+		return
+	}
+	key := parts[0] + "/" + parts[2] + "/" + request.URL.Query().Get("container")
+	if request.URL.Query().Get("previous") == "true" {
+		key += "/previous"
+	}
+	logs, ok := f.logs[key]
+	if !ok {
+		writeStatus(response, http.StatusBadRequest, "BadRequest", fmt.Sprintf("no logs for container %q of pod %q", request.URL.Query().Get("container"), parts[2]))
+		return
+	}
+	lines := strings.SplitAfter(logs, "\n")
+	if tail, err := strconv.Atoi(request.URL.Query().Get("tailLines")); err == nil && tail >= 0 && tail < len(lines) {
+		lines = lines[len(lines)-tail:]
+	}
+	response.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(response, strings.Join(lines, ""))
+}
+
+// serveObjects serves /<resource>, /namespaces/<ns>/<resource> and
+// /namespaces/<ns>/<resource>/<name>
+func (f *FakeServer) serveObjects(response http.ResponseWriter, request *http.Request, path, resource string, objects map[string]map[string]interface{}) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	var namespace, name string
+	switch {
+	case len(parts) == 1 && parts[0] == resource:
+	case len(parts) == 3 && parts[0] == "namespaces" && parts[2] == resource:
+		namespace = parts[1]
+	case len(parts) == 4 && parts[0] == "namespaces" && parts[2] == resource:
+		namespace, name = parts[1], parts[3]
+	default:
+		writeStatus(response, http.StatusNotFound, "NotFound", "the server could not find the requested resource")
+		return
+	}
+
+	if name == "" {
+		if request.Method != http.MethodGet {
+			writeStatus(response, http.StatusMethodNotAllowed, "MethodNotAllowed", "method not allowed")
+			return
+		}
+		keys := make([]string, 0, len(objects))
+		for key := range objects {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := []interface{}{}
+		for _, key := range keys {
+			object := objects[key]
+			metadata := object["metadata"].(map[string]interface{})
+			if namespace != "" && metadata["namespace"] != namespace {
+				continue
+			}
+			if !matchesSelector(metadata["labels"], request.URL.Query().Get("labelSelector")) {
+				continue
+			}
+			if field, ok := strings.CutPrefix(request.URL.Query().Get("fieldSelector"), "involvedObject.name="); ok {
+				if involved, _ := object["involvedObject"].(map[string]interface{}); involved == nil || involved["name"] != field {
+					continue
+				}
+			}
+			items = append(items, object)
+		}
+		writeJSON(response, map[string]interface{}{"items": items})
+		return
+	}
+
+	object, ok := objects[namespace+"/"+name]
+	if !ok {
+		writeStatus(response, http.StatusNotFound, "NotFound", fmt.Sprintf("%s %q not found", resource, name))
+		return
+	}
+	switch request.Method {
+	case http.MethodGet:
+		writeJSON(response, object)
+	case http.MethodPatch:
+		if request.Header.Get("Content-Type") != "application/json-patch+json" {
+			writeStatus(response, http.StatusUnsupportedMediaType, "UnsupportedMediaType", "only JSON patches are supported")
+			return
+		}
+		body, _ := io.ReadAll(request.Body)
+		var operations []struct {
+			Op    string      `json:"op"`
+			Path  string      `json:"path"`
+			Value interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(body, &operations); err != nil {
+			writeStatus(response, http.StatusBadRequest, "BadRequest", err.Error())
+			return
+		}
+		// Apply to a copy so a failed test leaves the object untouched
+		var patched map[string]interface{}
+		fromGeneric(object, &patched)
+		for _, operation := range operations {
+			if err := applyOperation(patched, operation.Op, operation.Path, operation.Value); err != nil {
+				writeStatus(response, http.StatusUnprocessableEntity, "Invalid", err.Error())
+				return
+			}
+		}
+		f.resourceVersion++
+		patched["metadata"].(map[string]interface{})["resourceVersion"] = strconv.Itoa(f.resourceVersion)
+		objects[namespace+"/"+name] = patched
+		f.patches++
+		writeJSON(response, patched)
+	default:
+		writeStatus(response, http.StatusMethodNotAllowed, "MethodNotAllowed", "method not allowed")
+	}
+}
+
+// applyOperation applies one JSON patch operation; test, replace and add are
+// supported, which is what the kube client sends
+func applyOperation(object map[string]interface{}, op, pointer string, value interface{}) error {
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	var parent interface{} = object
+	for _, token := range tokens[:len(tokens)-1] {
+		child, err := pointerChild(parent, token)
+		if err != nil {
+			return fmt.Errorf("%s %s: %v", op, pointer, err)
+		}
+		parent = child
+	}
+	last := tokens[len(tokens)-1]
+	current, err := pointerChild(parent, last)
+	switch op {
+	case "test":
+		if err != nil {
+			return fmt.Errorf("test %s: %v", pointer, err)
+		}
+		if fmt.Sprint(current) != fmt.Sprint(value) {
+			return fmt.Errorf("testing value %s failed: test failed", pointer)
+		}
+		return nil
+	case "replace", "add":
+		if err != nil && op == "replace" {
+			return fmt.Errorf("replace %s: %v", pointer, err)
+		}
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			container[last] = value
+		case []interface{}:
+			index, _ := strconv.Atoi(last)
+			container[index] = value
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported patch operation %q", op)
+	}
+}
+
+func pointerChild(parent interface{}, token string) (interface{}, error) {
+	token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return nil, fmt.Errorf("missing key %q", token)
+		}
+		return child, nil
+	case []interface{}:
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index >= len(container) {
+			return nil, fmt.Errorf("index %q out of range", token)
+		}
+		return container[index], nil
+	}
+	return nil, fmt.Errorf("cannot descend into %q", token)
+}
+
+// matchesSelector supports equality selectors such as "owner=helm,name=dop"
+func matchesSelector(labels interface{}, selector string) bool {
+	if selector == "" {
+		return true
+	}
+	var values map[string]string
+	fromGeneric(labels, &values)
+	for _, requirement := range strings.Split(selector, ",") {
+		key, value, _ := strings.Cut(requirement, "=")
+		if values[strings.TrimSpace(key)] != strings.TrimSpace(strings.TrimPrefix(value, "=")) {
+			return false
+		}
+	}
+	return true
+}
+
+func containerStatuses(statuses []kube.ContainerStatus) []interface{} {
+	result := []interface{}{}
+	for _, status := range statuses {
+		state := map[string]interface{}{}
+		switch status.State {
+		case "running":
+			state["running"] = map[string]interface{}{}
+		case "terminated":
+			state["terminated"] = map[string]interface{}{"reason": status.Reason, "message": status.Message, "exitCode": status.ExitCode}
+		default:
+			state["waiting"] = map[string]interface{}{"reason": status.Reason, "message": status.Message}
+		}
+		result = append(result, map[string]interface{}{
+			"name":         status.Name,
+			"image":        status.Image,
+			"ready":        status.Ready,
+			"restartCount": status.RestartCount,
+			"state":        state,
+		})
+	}
+	return result
+}
+
+func toGeneric(value interface{}) []interface{} {
+	var generic []interface{}
+	fromGeneric(value, &generic)
+	if generic == nil {
+		generic = []interface{}{}
+	}
+	return generic
+}
+
+// fromGeneric converts between shapes through JSON
+func fromGeneric(value interface{}, out interface{}) {
+	data, _ := json.Marshal(value)
+	_ = json.Unmarshal(data, out)
+}
+
+func writeJSON(response http.ResponseWriter, payload interface{}) {
+	response.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(response).Encode(payload)
+}
+
+func writeStatus(response http.ResponseWriter, code int, reason, message string) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(code)
+	_ = json.NewEncoder(response).Encode(map[string]interface{}{
+		"kind": "Status", "status": "Failure", "reason": reason, "message": message, "code": code,
+	})
+}
@@ -0,0 +1,261 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// MicroserviceResource is the plural name of the microservice custom resource
+const MicroserviceResource = "microservices"
+
+// Init container patterns of the deploy scripts: the container an application
+// deploy patches, and the one a customization deploy patches
+const (
+	ApplicationContainerPattern   = "(copy-application-files|source-code)"
+	CustomizationContainerPattern = "customization"
+)
+
+// Container is the name and image of a container of a pod template
+type Container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+// Microservice is the part of a microservice custom resource OCD deploys to:
+// its pod template's init containers carry the application images
+type Microservice struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	ResourceVersion string            `json:"resource_version"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	InitContainers  []Container       `json:"init_containers"`
+	Containers      []Container       `json:"containers"`
+}
+
+// microserviceObject is the API shape of a microservice
+type microserviceObject struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		Template struct {
+			Spec struct {
+				InitContainers []Container `json:"initContainers"`
+				Containers     []Container `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+type objectMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	ResourceVersion string            `json:"resourceVersion"`
+	Labels          map[string]string `json:"labels"`
+}
+
+func (o *microserviceObject) microservice() Microservice {
+	microservice := Microservice{
+		Name:            o.Metadata.Name,
+		Namespace:       o.Metadata.Namespace,
+		ResourceVersion: o.Metadata.ResourceVersion,
+		Labels:          o.Metadata.Labels,
+		InitContainers:  o.Spec.Template.Spec.InitContainers,
+		Containers:      o.Spec.Template.Spec.Containers,
+	}
+	if microservice.InitContainers == nil {
+		microservice.InitContainers = []Container{}
+	}
+	if microservice.Containers == nil {
+		microservice.Containers = []Container{}
+	}
+	return microservice
+}
+
+// microserviceAPIPath returns /apis/<group>/<version> of the microservice
+// resource, asking the server which group serves it the first time
+func (c *Client) microserviceAPIPath(ctx context.Context) (string, error) {
+	c.discoverMu.Lock()
+	defer c.discoverMu.Unlock()
+	if c.microservicePath != "" {
+		return c.microservicePath, nil
+	}
+	if c.microserviceAPI != "" {
+		c.microservicePath = "/apis/" + c.microserviceAPI
+		return c.microservicePath, nil
+	}
+
+	var groups struct {
+		Groups []struct {
+			Name             string `json:"name"`
+			PreferredVersion struct {
+				GroupVersion string `json:"groupVersion"`
+			} `json:"preferredVersion"`
+		} `json:"groups"`
+	}
+	if err := c.get(ctx, "/apis", nil, &groups); err != nil {
+		return "", err
+	}
+	for _, group := range groups.Groups {
+		groupVersion := group.PreferredVersion.GroupVersion
+		if groupVersion == "" {
+			continue
+		}
+		var resources struct {
+			Resources []struct {
+				Name string `json:"name"`
+			} `json:"resources"`
+		}
+		if err := c.get(ctx, "/apis/"+groupVersion, nil, &resources); err != nil {
+			continue // an aggregated API that is down must not hide the others
+		}
+		for _, resource := range resources.Resources {
+			if resource.Name == MicroserviceResource {
+				c.microservicePath = "/apis/" + groupVersion
+				return c.microservicePath, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("the cluster serves no %s resource", MicroserviceResource)
+}
+
+// GetMicroservice reads one microservice
+func (c *Client) GetMicroservice(ctx context.Context, namespace, name string) (*Microservice, error) {
+	prefix, err := c.microserviceAPIPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var object microserviceObject
+	if err := c.get(ctx, namespaced(prefix, namespace, MicroserviceResource)+"/"+url.PathEscape(name), nil, &object); err != nil {
+		return nil, err
+	}
+	microservice := object.microservice()
+	return &microservice, nil
+}
+
+// ListMicroservices lists the microservices of a namespace, or of every
+// namespace when it is empty
+func (c *Client) ListMicroservices(ctx context.Context, namespace string) ([]Microservice, error) {
+	prefix, err := c.microserviceAPIPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Items []microserviceObject `json:"items"`
+	}
+	if err := c.get(ctx, namespaced(prefix, namespace, MicroserviceResource), nil, &list); err != nil {
+		return nil, err
+	}
+	microservices := make([]Microservice, 0, len(list.Items))
+	for i := range list.Items {
+		microservices = append(microservices, list.Items[i].microservice())
+	}
+	return microservices, nil
+}
+
+// FindInitContainer returns the index of the first init container whose image
+// matches pattern, else of the first whose name does, and how it was found
+// ("image" or "name"); -1 when none matches. pattern is a regular expression,
+// as in the deploy scripts, e.g. "(copy-application-files|source-code)".
+func (m *Microservice) FindInitContainer(pattern string) (int, string, error) {
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		return -1, "", fmt.Errorf("invalid init container pattern %q: %w", pattern, err)
+	}
+	for i, container := range m.InitContainers {
+		if expression.MatchString(container.Image) {
+			return i, "image", nil
+		}
+	}
+	for i, container := range m.InitContainers {
+		if expression.MatchString(container.Name) {
+			return i, "name", nil
+		}
+	}
+	return -1, "", nil
+}
+
+// jsonPatchOperation is one RFC 6902 operation
+type jsonPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// PatchInitContainerImage replaces the image of init container index with one
+// JSON patch. The patch first tests the container's name and current image,
+// so it fails with a conflict (see IsConflict) rather than patching the wrong
+// container when the microservice changed since it was read.
+func (c *Client) PatchInitContainerImage(ctx context.Context, namespace, name string, index int, container Container, image string) (*Microservice, error) {
+	prefix, err := c.microserviceAPIPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base := fmt.Sprintf("/spec/template/spec/initContainers/%d", index)
+	patch, err := json.Marshal([]jsonPatchOperation{
+		{Op: "test", Path: base + "/name", Value: container.Name},
+		{Op: "test", Path: base + "/image", Value: container.Image},
+		{Op: "replace", Path: base + "/image", Value: image},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var object microserviceObject
+	path := namespaced(prefix, namespace, MicroserviceResource) + "/" + url.PathEscape(name)
+	if err := c.do(ctx, http.MethodPatch, path, nil, "application/json-patch+json", patch, &object); err != nil {
+		return nil, err
+	}
+	microservice := object.microservice()
+	return &microservice, nil
+}
+
+// ImageUpdate is the outcome of UpdateInitContainerImage
+type ImageUpdate struct {
+	Microservice  string `json:"microservice"`
+	Namespace     string `json:"namespace"`
+	Container     string `json:"container"`
+	Index         int    `json:"index"`
+	DetectedBy    string `json:"detected_by"` // "image" or "name"
+	PreviousImage string `json:"previous_image"`
+	Image         string `json:"image"`
+}
+
+// UpdateInitContainerImage is the deploy patch step: it finds the init
+// container matching pattern and sets its image, atomically with respect to
+// concurrent changes of the microservice
+func (c *Client) UpdateInitContainerImage(ctx context.Context, namespace, name, pattern, image string) (*ImageUpdate, error) {
+	microservice, err := c.GetMicroservice(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	index, detectedBy, err := microservice.FindInitContainer(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 {
+		names := make([]string, 0, len(microservice.InitContainers))
+		for _, container := range microservice.InitContainers {
+			names = append(names, container.Name)
+		}
+		return nil, fmt.Errorf("no init container of microservice %s matches %q (init containers: %v)", name, pattern, names)
+	}
+	container := microservice.InitContainers[index]
+	patched, err := c.PatchInitContainerImage(ctx, namespace, name, index, container, image)
+	if err != nil {
+		return nil, err
+	}
+	if index >= len(patched.InitContainers) || patched.InitContainers[index].Image != image {
+		return nil, fmt.Errorf("microservice %s was patched but init container %s does not show image %s", name, container.Name, image)
+	}
+	return &ImageUpdate{
+		Microservice:  name,
+		Namespace:     namespace,
+		Container:     container.Name,
+		Index:         index,
+		DetectedBy:    detectedBy,
+		PreviousImage: container.Image,
+		Image:         image,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package kube
+
+import (
+	"context"
+	"sort"
+)
+
+// Namespace is a namespace of the cluster
+type Namespace struct {
+	Name   string            `json:"name"`
+	Phase  string            `json:"phase"` // Active or Terminating
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ListNamespaces lists the namespaces of the cluster by name
+func (c *Client) ListNamespaces(ctx context.Context) ([]Namespace, error) {
+	var list struct {
+		Items []struct {
+			Metadata objectMeta `json:"metadata"`
+			Status   struct {
+				Phase string `json:"phase"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := c.get(ctx, "/api/v1/namespaces", nil, &list); err != nil {
+		return nil, err
+	}
+	namespaces := make([]Namespace, 0, len(list.Items))
+	for _, item := range list.Items {
+		namespaces = append(namespaces, Namespace{Name: item.Metadata.Name, Phase: item.Status.Phase, Labels: item.Metadata.Labels})
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	return namespaces, nil
+}
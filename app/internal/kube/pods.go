@@ -0,0 +1,201 @@
+package kube
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ContainerStatus is the state of one container of a pod
+type ContainerStatus struct {
+	Name         string `json:"name"`
+	Image        string `json:"image"`
+	Ready        bool   `json:"ready"`
+	RestartCount int    `json:"restart_count"`
+	State        string `json:"state"`            // running, waiting or terminated
+	Reason       string `json:"reason,omitempty"` // e.g. CrashLoopBackOff, ErrImagePull, Completed
+	Message      string `json:"message,omitempty"`
+	ExitCode     int    `json:"exit_code,omitempty"`
+}
+
+// Pod is the part of a pod OCD checks after a deploy
+type Pod struct {
+	Name           string            `json:"name"`
+	Namespace      string            `json:"namespace"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Phase          string            `json:"phase"`
+	Ready          bool              `json:"ready"`
+	Terminating    bool              `json:"terminating,omitempty"` // being deleted, e.g. replaced by a rollout
+	NodeName       string            `json:"node_name,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	InitContainers []ContainerStatus `json:"init_containers"`
+	Containers     []ContainerStatus `json:"containers"`
+}
+
+// podObject is the API shape of a pod
+type podObject struct {
+	Metadata struct {
+		objectMeta
+		CreationTimestamp time.Time  `json:"creationTimestamp"`
+		DeletionTimestamp *time.Time `json:"deletionTimestamp"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName       string      `json:"nodeName"`
+		InitContainers []Container `json:"initContainers"`
+		Containers     []Container `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase      string `json:"phase"`
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+		InitContainerStatuses []containerStatusObject `json:"initContainerStatuses"`
+		ContainerStatuses     []containerStatusObject `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type containerStateObject struct {
+	Running *struct{} `json:"running"`
+	Waiting *struct {
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	} `json:"waiting"`
+	Terminated *struct {
+		Reason   string `json:"reason"`
+		Message  string `json:"message"`
+		ExitCode int    `json:"exitCode"`
+	} `json:"terminated"`
+}
+
+type containerStatusObject struct {
+	Name         string               `json:"name"`
+	Image        string               `json:"image"`
+	Ready        bool                 `json:"ready"`
+	RestartCount int                  `json:"restartCount"`
+	State        containerStateObject `json:"state"`
+}
+
+func (o *podObject) pod() Pod {
+	pod := Pod{
+		Name:        o.Metadata.Name,
+		Namespace:   o.Metadata.Namespace,
+		Labels:      o.Metadata.Labels,
+		Phase:       o.Status.Phase,
+		Terminating: o.Metadata.DeletionTimestamp != nil,
+		NodeName:    o.Spec.NodeName,
+		CreatedAt:   o.Metadata.CreationTimestamp,
+	}
+	for _, condition := range o.Status.Conditions {
+		if condition.Type == "Ready" {
+			pod.Ready = condition.Status == "True"
+		}
+	}
+	pod.InitContainers = containerStatuses(o.Spec.InitContainers, o.Status.InitContainerStatuses)
+	pod.Containers = containerStatuses(o.Spec.Containers, o.Status.ContainerStatuses)
+	return pod
+}
+
+// containerStatuses lists every container of the spec, with its status when
+// the kubelet reported one; the image is the spec's, as statuses may report
+// a resolved digest instead of the tag that was deployed
+func containerStatuses(spec []Container, statuses []containerStatusObject) []ContainerStatus {
+	byName := make(map[string]containerStatusObject, len(statuses))
+	for _, status := range statuses {
+		byName[status.Name] = status
+	}
+	result := make([]ContainerStatus, 0, len(spec))
+	for _, container := range spec {
+		status := ContainerStatus{Name: container.Name, Image: container.Image, State: "waiting"}
+		if reported, ok := byName[container.Name]; ok {
+			status.Ready = reported.Ready
+			status.RestartCount = reported.RestartCount
+			switch state := reported.State; {
+			case state.Running != nil:
+				status.State = "running"
+			case state.Terminated != nil:
+				status.State = "terminated"
+				status.Reason, status.Message, status.ExitCode = state.Terminated.Reason, state.Terminated.Message, state.Terminated.ExitCode
+			case state.Waiting != nil:
+				status.Reason, status.Message = state.Waiting.Reason, state.Waiting.Message
+			}
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// ListPods lists the pods of a namespace, or of every namespace when it is
+// empty, optionally filtered by a label selector such as "app=dop-backend"
+func (c *Client) ListPods(ctx context.Context, namespace, labelSelector string) ([]Pod, error) {
+	var list struct {
+		Items []podObject `json:"items"`
+	}
+	if err := c.get(ctx, namespaced("/api/v1", namespace, "pods"), listQuery(labelSelector), &list); err != nil {
+		return nil, err
+	}
+	pods := make([]Pod, 0, len(list.Items))
+	for i := range list.Items {
+		pods = append(pods, list.Items[i].pod())
+	}
+	return pods, nil
+}
+
+// Event is a Kubernetes event about an object, such as a failed image pull
+type Event struct {
+	Type     string    `json:"type"` // Normal or Warning
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// ListEvents lists the events about one object of a namespace, oldest first
+func (c *Client) ListEvents(ctx context.Context, namespace, objectName string) ([]Event, error) {
+	query := url.Values{}
+	query.Set("fieldSelector", "involvedObject.name="+objectName)
+	var list struct {
+		Items []struct {
+			Type          string    `json:"type"`
+			Reason        string    `json:"reason"`
+			Message       string    `json:"message"`
+			Count         int       `json:"count"`
+			LastTimestamp time.Time `json:"lastTimestamp"`
+			EventTime     time.Time `json:"eventTime"`
+		} `json:"items"`
+	}
+	if err := c.get(ctx, namespaced("/api/v1", namespace, "events"), query, &list); err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(list.Items))
+	for _, item := range list.Items {
+		event := Event{Type: item.Type, Reason: item.Reason, Message: item.Message, Count: item.Count, LastSeen: item.LastTimestamp}
+		if event.LastSeen.IsZero() {
+			event.LastSeen = item.EventTime
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeen.Before(events[j].LastSeen) })
+	return events, nil
+}
+
+// PodLogs returns the last lines of a container's log; previous reads the
+// log of the container's last terminated run, as for a crash-looping container
+func (c *Client) PodLogs(ctx context.Context, namespace, pod, container string, tailLines int, previous bool) (string, error) {
+	query := url.Values{}
+	query.Set("container", container)
+	if tailLines > 0 {
+		query.Set("tailLines", strconv.Itoa(tailLines))
+	}
+	if previous {
+		query.Set("previous", "true")
+	}
+	var logs []byte
+	path := namespaced("/api/v1", namespace, "pods") + "/" + url.PathEscape(pod) + "/log"
+	if err := c.get(ctx, path, query, &logs); err != nil {
+		return "", err
+	}
+	return string(logs), nil
+}
@@ -0,0 +1,121 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shellKubeconfigTTL is how long a kubeconfig read through the deploy shell is
+// used before it is read again, so every request does not start a shell
+const shellKubeconfigTTL = 30 * time.Second
+
+// shellKubeconfigTimeout bounds reading the kubeconfig through the deploy shell
+const shellKubeconfigTimeout = 30 * time.Second
+
+// Provider hands out the client for one kubeconfig context. The client is
+// reused until the kubeconfig changes, so a credential plugin such as
+// aws eks get-token is not run again for every request or deploy.
+type Provider struct {
+	path            string
+	context         string
+	microserviceAPI string
+	shell           Shell
+
+	mu       sync.Mutex
+	key      string
+	client   *Client
+	loadedAt time.Time
+}
+
+// NewProvider creates a provider for a context of the kubeconfig at path; an
+// empty context uses the file's current context. microserviceAPI is passed on
+// as Config.MicroserviceAPI.
+//
+// With an empty path and a shell, the kubeconfig is the one kubectl uses in
+// that shell, where the deploy scripts run, and credential plugins run there
+// too: on Windows that is the WSL user's, not the Windows user's. With an
+// empty path and no shell, DefaultKubeconfigPath is read directly.
+func NewProvider(path, contextName, microserviceAPI string, shell Shell) *Provider {
+	return &Provider{path: path, context: contextName, microserviceAPI: microserviceAPI, shell: shell}
+}
+
+// Client returns the cached client, loading the kubeconfig again first when
+// it changed since the client was built
+func (p *Provider) Client() (*Client, error) {
+	if p.path == "" && p.shell != nil {
+		return p.shellClient()
+	}
+
+	path := p.path
+	if path == "" {
+		path = DefaultKubeconfigPath()
+	}
+	key := path + "|" + p.context
+	if info, err := os.Stat(path); err == nil {
+		key += "|" + info.ModTime().Format(time.RFC3339Nano)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil && p.key == key {
+		return p.client, nil
+	}
+	config, err := LoadConfig(path, p.context)
+	if err != nil {
+		return nil, err
+	}
+	return p.build(key, config)
+}
+
+// shellClient returns the client for the kubeconfig kubectl sees in the shell.
+// `kubectl config view --flatten` merges $KUBECONFIG and inlines the files it
+// refers to, which may only exist on the shell's side.
+func (p *Provider) shellClient() (*Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil && time.Since(p.loadedAt) < shellKubeconfigTTL {
+		return p.client, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shellKubeconfigTimeout)
+	defer cancel()
+	command := p.shell(ctx, "kubectl config view --raw --flatten -o json")
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	data, err := command.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the kubeconfig of the deploy shell: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:]) + "|" + p.context
+	if p.client != nil && p.key == key {
+		p.loadedAt = time.Now()
+		return p.client, nil
+	}
+	config, err := ParseConfig(data, p.context, "")
+	if err != nil {
+		return nil, err
+	}
+	if config.Exec != nil {
+		config.Exec.Shell = p.shell
+	}
+	return p.build(key, config)
+}
+
+func (p *Provider) build(key string, config *Config) (*Client, error) {
+	config.MicroserviceAPI = p.microserviceAPI
+	client, err := New(config)
+	if err != nil {
+		return nil, err
+	}
+	p.key, p.client, p.loadedAt = key, client, time.Now()
+	return client, nil
+}
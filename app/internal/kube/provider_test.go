@@ -0,0 +1,77 @@
+package kube_test
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"app/internal/kube"
+	"app/internal/kube/kubetest"
+)
+
+func TestProviderThroughShell(t *testing.T) {
+	fake, _ := newFake(t)
+
+	// What `kubectl config view --raw --flatten -o json` prints in the shell:
+	// the token comes from a credential plugin that needs the shell's env
+	kubeconfig := fmt.Sprintf(`{
+  "current-context": "wsl",
+  "contexts": [{"name": "wsl", "context": {"cluster": "eks", "user": "aws"}}],
+  "clusters": [{"name": "eks", "cluster": {"server": %q}}],
+  "users": [{"name": "aws", "user": {"exec": {
+    "apiVersion": "client.authentication.k8s.io/v1beta1",
+    "command": "sh",
+    "args": ["-c", "[ \"$AWS_PROFILE\" = dop ] && printf '{\"status\": {\"token\": \"%s\"}}'"],
+    "env": [{"name": "AWS_PROFILE", "value": "dop"}]
+  }}}]
+}`, fake.URL, kubetest.Token)
+
+	var mu sync.Mutex
+	var lines []string
+	provider := kube.NewProvider("", "", "", func(ctx context.Context, commandLine string) *exec.Cmd {
+		mu.Lock()
+		lines = append(lines, commandLine)
+		mu.Unlock()
+		if commandLine == "kubectl config view --raw --flatten -o json" {
+			command := exec.CommandContext(ctx, "cat")
+			command.Stdin = strings.NewReader(kubeconfig)
+			return command
+		}
+		return exec.CommandContext(ctx, "sh", "-c", commandLine)
+	})
+
+	client, err := provider.Client()
+	if err != nil {
+		t.Fatalf("Client: %v", err)
+	}
+	if client.Server() != fake.URL {
+		t.Errorf("Server = %q, want %q", client.Server(), fake.URL)
+	}
+	microservices, err := client.ListMicroservices(context.Background(), "dop")
+	if err != nil {
+		t.Fatalf("ListMicroservices with the plugin's token: %v", err)
+	}
+	if len(microservices) != 2 {
+		t.Errorf("got %d microservices, want 2", len(microservices))
+	}
+
+	again, err := provider.Client()
+	if err != nil {
+		t.Fatalf("Client again: %v", err)
+	}
+	if again != client {
+		t.Error("the client was built again within the kubeconfig TTL")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lines) != 2 {
+		t.Fatalf("shell ran %q, want the kubeconfig read and one plugin run", lines)
+	}
+	if !strings.HasPrefix(lines[1], "'env' 'KUBERNETES_EXEC_INFO=") || !strings.Contains(lines[1], "'AWS_PROFILE=dop' 'sh' '-c'") {
+		t.Errorf("plugin command line = %s", lines[1])
+	}
+}
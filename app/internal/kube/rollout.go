@@ -0,0 +1,296 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RolloutTarget is an init container image set by a deploy patch
+type RolloutTarget struct {
+	Namespace    string `json:"namespace"`
+	Microservice string `json:"microservice"`
+	Container    string `json:"container"` // init container name; empty matches any init container
+	Image        string `json:"image"`
+}
+
+// Kinds of rollout events
+const (
+	RolloutCheck   = "check"   // a status check, sent when the pods changed
+	RolloutEvent   = "event"   // a Kubernetes event of a failing pod
+	RolloutLog     = "log"     // a log line of a failing container
+	RolloutReady   = "ready"   // every new pod is ready, watching ends
+	RolloutFailed  = "failed"  // a new pod is crash-looping or cannot start, watching ends
+	RolloutTimeout = "timeout" // gave up waiting, watching ends
+)
+
+// RolloutUpdate is one entry of a rollout timeline
+type RolloutUpdate struct {
+	Kind    string    `json:"kind"`
+	Time    time.Time `json:"time"`
+	Pod     string    `json:"pod,omitempty"`
+	Message string    `json:"message"`
+}
+
+// RolloutOptions controls WatchRollout
+type RolloutOptions struct {
+	Interval    time.Duration
+	Timeout     time.Duration
+	MaxRestarts int // restarts after which a container counts as crash-looping
+	LogLines    int // log lines shown of a failing container
+}
+
+// failingWaitReasons are container waiting reasons that do not resolve by themselves
+var failingWaitReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// RolloutError is returned by WatchRollout when the new pods failed or did
+// not become ready in time
+type RolloutError struct {
+	Target  RolloutTarget
+	Pod     string
+	Reason  string
+	Timeout bool
+}
+
+// Error implements the error interface
+func (e *RolloutError) Error() string {
+	if e.Pod == "" {
+		return fmt.Sprintf("microservice %s: %s", e.Target.Microservice, e.Reason)
+	}
+	return fmt.Sprintf("microservice %s: pod %s %s", e.Target.Microservice, e.Pod, e.Reason)
+}
+
+// WatchRollout follows the pods running a patched init container image until
+// they are all ready, one of them fails (crash-looping, image not pullable) or
+// the timeout passes, sending the timeline to emit. On failure the pod's
+// events and the last log lines of the failing container are sent
+// before the final update. It returns nil once the rollout is ready.
+func (c *Client) WatchRollout(ctx context.Context, target RolloutTarget, options RolloutOptions, emit func(RolloutUpdate)) error {
+	if options.Interval <= 0 {
+		options.Interval = 5 * time.Second
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 10 * time.Minute
+	}
+	if options.MaxRestarts <= 0 {
+		options.MaxRestarts = 3
+	}
+	if options.LogLines <= 0 {
+		options.LogLines = 50
+	}
+	send := func(kind, pod, message string) {
+		emit(RolloutUpdate{Kind: kind, Time: time.Now(), Pod: pod, Message: message})
+	}
+
+	started := time.Now()
+	deadline := time.NewTimer(options.Timeout)
+	defer deadline.Stop()
+	var last string
+	var pods []Pod
+	for {
+		all, err := c.ListPods(ctx, target.Namespace, "")
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if message := fmt.Sprintf("Pod check failed: %v", err); message != last {
+				send(RolloutCheck, "", message)
+				last = message
+			}
+		default:
+			pods = rolloutPods(all, target)
+			if summary := describeRollout(pods, target); summary != last {
+				send(RolloutCheck, "", summary)
+				last = summary
+			}
+			if pod, container, reason := failingPod(pods, options.MaxRestarts); pod != nil {
+				c.sendDiagnostics(ctx, target, pod, container, options.LogLines, send)
+				send(RolloutFailed, pod.Name, fmt.Sprintf("Pod %s %s", pod.Name, reason))
+				return &RolloutError{Target: target, Pod: pod.Name, Reason: reason}
+			}
+			if rolloutReady(pods) {
+				send(RolloutReady, "", fmt.Sprintf("%d pod(s) of %s ready with %s after %s", len(pods), target.Microservice, target.Image, time.Since(started).Round(time.Second)))
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			reason := fmt.Sprintf("no pod running %s after %s", target.Image, options.Timeout)
+			if len(pods) > 0 {
+				reason = fmt.Sprintf("not ready after %s", options.Timeout)
+				for i := range pods {
+					if !pods[i].Ready {
+						c.sendDiagnostics(ctx, target, &pods[i], pendingContainer(&pods[i]), options.LogLines, send)
+						send(RolloutTimeout, pods[i].Name, fmt.Sprintf("Pod %s %s", pods[i].Name, reason))
+						return &RolloutError{Target: target, Pod: pods[i].Name, Reason: reason, Timeout: true}
+					}
+				}
+			}
+			send(RolloutTimeout, "", reason)
+			return &RolloutError{Target: target, Reason: reason, Timeout: true}
+		case <-time.After(options.Interval):
+		}
+	}
+}
+
+// rolloutPods returns the live pods whose init container runs the target
+// image, preferring those named after the microservice when other
+// microservices share the image
+func rolloutPods(all []Pod, target RolloutTarget) []Pod {
+	var matching, named []Pod
+	for _, pod := range all {
+		if pod.Terminating {
+			continue
+		}
+		for _, container := range pod.InitContainers {
+			if container.Image == target.Image && (target.Container == "" || container.Name == target.Container) {
+				matching = append(matching, pod)
+				if strings.HasPrefix(pod.Name, target.Microservice+"-") {
+					named = append(named, pod)
+				}
+				break
+			}
+		}
+	}
+	if len(named) > 0 {
+		matching = named
+	}
+	sort.Slice(matching, func(i, j int) bool { return matching[i].Name < matching[j].Name })
+	return matching
+}
+
+func rolloutReady(pods []Pod) bool {
+	if len(pods) == 0 {
+		return false
+	}
+	for _, pod := range pods {
+		if !pod.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// failingPod returns the first pod that will not become ready by itself, the
+// container at fault and why
+func failingPod(pods []Pod, maxRestarts int) (*Pod, string, string) {
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Phase == "Failed" {
+			return pod, pendingContainer(pod), "failed"
+		}
+		for _, container := range pod.allContainers() {
+			switch {
+			case failingWaitReasons[container.Reason] && container.State == "waiting":
+				return pod, container.Name, fmt.Sprintf("container %s is in %s", container.Name, container.Reason)
+			case container.RestartCount >= maxRestarts && !container.Ready && container.Reason != "Completed":
+				return pod, container.Name, fmt.Sprintf("container %s restarted %d times", container.Name, container.RestartCount)
+			}
+		}
+	}
+	return nil, "", ""
+}
+
+// allContainers returns the init containers followed by the containers
+func (p *Pod) allContainers() []ContainerStatus {
+	return append(append([]ContainerStatus(nil), p.InitContainers...), p.Containers...)
+}
+
+// pendingContainer returns the first container of a pod that is not done or
+// ready, the one whose log explains the wait
+func pendingContainer(pod *Pod) string {
+	for _, container := range pod.InitContainers {
+		if container.Reason != "Completed" {
+			return container.Name
+		}
+	}
+	for _, container := range pod.Containers {
+		if !container.Ready {
+			return container.Name
+		}
+	}
+	return ""
+}
+
+// sendDiagnostics sends a pod's events and the end of a container's log
+func (c *Client) sendDiagnostics(ctx context.Context, target RolloutTarget, pod *Pod, container string, logLines int, send func(kind, pod, message string)) {
+	if events, err := c.ListEvents(ctx, target.Namespace, pod.Name); err == nil {
+		for _, event := range events {
+			message := fmt.Sprintf("%s %s: %s", event.Type, event.Reason, event.Message)
+			if event.Count > 1 {
+				message += fmt.Sprintf(" (x%d)", event.Count)
+			}
+			send(RolloutEvent, pod.Name, message)
+		}
+	} else {
+		send(RolloutEvent, pod.Name, fmt.Sprintf("Could not read events: %v", err))
+	}
+	if container == "" {
+		return
+	}
+
+	// A restarted container's useful log is the one of the run that crashed
+	restarted := false
+	for _, status := range pod.allContainers() {
+		if status.Name == container && status.RestartCount > 0 {
+			restarted = true
+		}
+	}
+	logs, err := c.PodLogs(ctx, target.Namespace, pod.Name, container, logLines, restarted)
+	if err != nil && restarted {
+		logs, err = c.PodLogs(ctx, target.Namespace, pod.Name, container, logLines, false)
+	}
+	if err != nil {
+		send(RolloutLog, pod.Name, fmt.Sprintf("Could not read logs of %s: %v", container, err))
+		return
+	}
+	if strings.TrimSpace(logs) == "" {
+		send(RolloutLog, pod.Name, fmt.Sprintf("Container %s has no log output", container))
+		return
+	}
+	send(RolloutLog, pod.Name, fmt.Sprintf("Last log lines of %s:", container))
+	for _, line := range strings.Split(strings.TrimRight(logs, "\n"), "\n") {
+		send(RolloutLog, pod.Name, line)
+	}
+}
+
+// describeRollout summarises the new pods for the timeline
+func describeRollout(pods []Pod, target RolloutTarget) string {
+	if len(pods) == 0 {
+		return fmt.Sprintf("Waiting for pods of %s running %s", target.Microservice, target.Image)
+	}
+	ready := 0
+	var pending []string
+	for _, pod := range pods {
+		if pod.Ready {
+			ready++
+			continue
+		}
+		state := pod.Phase
+		for _, container := range pod.allContainers() {
+			if container.Reason != "" && container.Reason != "Completed" {
+				state = container.Name + " " + container.Reason
+				break
+			}
+		}
+		pending = append(pending, fmt.Sprintf("%s (%s)", pod.Name, state))
+	}
+	summary := fmt.Sprintf("%d/%d pod(s) ready", ready, len(pods))
+	if len(pending) > 0 {
+		summary += ": " + strings.Join(pending, ", ")
+	}
+	return summary
+}
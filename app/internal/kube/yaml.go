@@ -0,0 +1,223 @@
+package kube
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is one meaningful line of a YAML document
+type yamlLine struct {
+	number int
+	indent int
+	text   string // without indentation and trailing comment
+}
+
+// decodeYAML reads the block-style YAML subset that kubectl and
+// `aws eks update-kubeconfig` write: nested mappings, sequences (indented or
+// not below their key), plain and quoted scalars and empty {} / [] collections.
+// The result is made of map[string]interface{}, []interface{}, string, bool
+// and nil, ready to be re-encoded as JSON.
+func decodeYAML(data []byte) (interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		text := strings.TrimRight(stripYAMLComment(trimmed), " \t")
+		if text == "" || text == "---" {
+			continue
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(raw) - len(trimmed), text: text})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	value, next, err := parseYAMLBlock(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[next].number)
+	}
+	return value, nil
+}
+
+// stripYAMLComment drops a # comment that is not inside a quoted scalar
+func stripYAMLComment(text string) string {
+	var quote rune
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || text[i-1] == ' '):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseYAMLBlock parses the mapping or sequence whose lines start at column indent
+func parseYAMLBlock(lines []yamlLine, start, indent int) (interface{}, int, error) {
+	if isYAMLSequenceItem(lines[start].text) {
+		return parseYAMLSequence(lines, start, indent)
+	}
+	return parseYAMLMapping(lines, start, indent)
+}
+
+func parseYAMLSequence(lines []yamlLine, start, indent int) (interface{}, int, error) {
+	items := []interface{}{}
+	i := start
+	for i < len(lines) && lines[i].indent == indent && isYAMLSequenceItem(lines[i].text) {
+		content := strings.TrimLeft(strings.TrimPrefix(lines[i].text, "-"), " ")
+		if content == "" {
+			value, next, err := parseYAMLNested(lines, i+1, indent, true)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, value)
+			i = next
+			continue
+		}
+		if _, _, isEntry := splitYAMLEntry(content); isEntry {
+			// "- key: value" opens a mapping whose keys line up with "key"
+			column := indent + len(lines[i].text) - len(content)
+			rewritten := append([]yamlLine(nil), lines...)
+			rewritten[i] = yamlLine{number: lines[i].number, indent: column, text: content}
+			value, next, err := parseYAMLMapping(rewritten, i, column)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, value)
+			i = next
+			continue
+		}
+		value, err := parseYAMLScalar(content, lines[i].number)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, value)
+		i++
+	}
+	return items, i, nil
+}
+
+func parseYAMLMapping(lines []yamlLine, start, indent int) (interface{}, int, error) {
+	entries := make(map[string]interface{})
+	i := start
+	for i < len(lines) && lines[i].indent == indent {
+		if isYAMLSequenceItem(lines[i].text) {
+			return nil, 0, fmt.Errorf("line %d: sequence item where a key was expected", lines[i].number)
+		}
+		key, rest, ok := splitYAMLEntry(lines[i].text)
+		if !ok {
+			return nil, 0, fmt.Errorf("line %d: expected \"key: value\"", lines[i].number)
+		}
+		if rest != "" {
+			value, err := parseYAMLScalar(rest, lines[i].number)
+			if err != nil {
+				return nil, 0, err
+			}
+			entries[key] = value
+			i++
+			continue
+		}
+		value, next, err := parseYAMLNested(lines, i+1, indent, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries[key] = value
+		i = next
+	}
+	return entries, i, nil
+}
+
+// parseYAMLNested parses the block below a key or a bare "-". Below a key, a
+// sequence may sit at the key's own indentation, as kubectl writes it.
+func parseYAMLNested(lines []yamlLine, start, parentIndent int, inSequence bool) (interface{}, int, error) {
+	if start >= len(lines) {
+		return nil, start, nil
+	}
+	next := lines[start]
+	if next.indent > parentIndent || (!inSequence && next.indent == parentIndent && isYAMLSequenceItem(next.text)) {
+		return parseYAMLBlock(lines, start, next.indent)
+	}
+	return nil, start, nil
+}
+
+// splitYAMLEntry splits "key: value" or "key:"; colons inside values such as
+// ARNs and URLs are not followed by a space and do not split
+func splitYAMLEntry(text string) (string, string, bool) {
+	var key, rest string
+	if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return "", "", false
+		}
+		key, rest = text[1:end+1], text[end+2:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(strings.TrimPrefix(rest, ":")), true
+	}
+	if index := strings.Index(text, ": "); index > 0 {
+		return text[:index], strings.TrimSpace(text[index+2:]), true
+	}
+	if strings.HasSuffix(text, ":") && len(text) > 1 {
+		return text[:len(text)-1], "", true
+	}
+	return "", "", false
+}
+
+func parseYAMLScalar(text string, lineNumber int) (interface{}, error) {
+	switch {
+	case text == "{}":
+		return map[string]interface{}{}, nil
+	case text == "[]":
+		return []interface{}{}, nil
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("line %d: unterminated flow sequence", lineNumber)
+		}
+		items := []interface{}{}
+		for _, part := range strings.Split(text[1:len(text)-1], ",") {
+			value, err := parseYAMLScalar(strings.TrimSpace(part), lineNumber)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case strings.HasPrefix(text, "{"), text == "|", text == ">", strings.HasPrefix(text, "|-"), strings.HasPrefix(text, ">-"):
+		return nil, fmt.Errorf("line %d: unsupported YAML construct %q", lineNumber, text)
+	case strings.HasPrefix(text, "\""):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid double-quoted string", lineNumber)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("line %d: invalid single-quoted string", lineNumber)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	switch strings.ToLower(text) {
+	case "null", "~":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return text, nil
+}
@@ -0,0 +1,263 @@
+// Package nexus is a client for the Nexus repository manager: component
+// search, Maven metadata and snapshot resolution, and rewriting download URLs
+// to the internal proxy.
+package nexus
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// maxSearchPages bounds how many continuation pages Search follows
+	maxSearchPages = 50
+)
+
+// Config describes how to reach a Nexus server
+type Config struct {
+	BaseURL            string // scheme://host[:port] of the Nexus server
+	Username           string // optional; anonymous access when empty
+	Password           string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+}
+
+// Client talks to the Nexus REST API and to repository content
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// StatusError is returned when Nexus answers with an unexpected HTTP status
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Nexus returned HTTP %d for %s", e.StatusCode, e.URL)
+}
+
+// IsNotFound reports whether err is a 404 from Nexus
+func IsNotFound(err error) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.StatusCode == http.StatusNotFound
+}
+
+// New creates a Nexus client
+func New(config Config) *Client {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	if config.InsecureSkipVerify {
+		httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		username:   config.Username,
+		password:   config.Password,
+		httpClient: httpClient,
+	}
+}
+
+// BaseURL returns the Nexus server URL
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// RepositoryURL returns the content URL of a hosted or proxy repository
+func (c *Client) RepositoryURL(repository string) string {
+	return c.baseURL + "/repository/" + repository + "/"
+}
+
+// SearchPage returns one page of components matching query. Pass the
+// continuation token of the previous page, or "" for the first.
+func (c *Client) SearchPage(ctx context.Context, query SearchQuery, continuationToken string) (*SearchPage, error) {
+	params := url.Values{}
+	setParam(params, "repository", query.Repository)
+	setParam(params, "group", query.Group)
+	setParam(params, "name", query.Artifact)
+	setParam(params, "version", query.Version)
+	setParam(params, "maven.classifier", query.Classifier)
+	setParam(params, "maven.extension", query.Extension)
+	setParam(params, "q", query.Keyword)
+	setParam(params, "continuationToken", continuationToken)
+
+	body, err := c.get(ctx, c.baseURL+"/service/rest/v1/search?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var page SearchPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode Nexus search response: %w", err)
+	}
+	return &page, nil
+}
+
+// Search returns every component matching query, following continuation tokens
+func (c *Client) Search(ctx context.Context, query SearchQuery) ([]Component, error) {
+	var components []Component
+	token := ""
+	for page := 0; page < maxSearchPages; page++ {
+		result, err := c.SearchPage(ctx, query, token)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, result.Items...)
+		if result.ContinuationToken == "" {
+			return components, nil
+		}
+		token = result.ContinuationToken
+	}
+	return components, fmt.Errorf("Nexus search returned more than %d pages", maxSearchPages)
+}
+
+// mavenMetadata mirrors maven-metadata.xml at artifact and version level
+type mavenMetadata struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Versioning struct {
+		Latest      string   `xml:"latest"`
+		Release     string   `xml:"release"`
+		Versions    []string `xml:"versions>version"`
+		LastUpdated string   `xml:"lastUpdated"`
+		Snapshot    struct {
+			Timestamp   string `xml:"timestamp"`
+			BuildNumber int    `xml:"buildNumber"`
+		} `xml:"snapshot"`
+		SnapshotVersions []struct {
+			Classifier string `xml:"classifier"`
+			Extension  string `xml:"extension"`
+			Value      string `xml:"value"`
+			Updated    string `xml:"updated"`
+		} `xml:"snapshotVersions>snapshotVersion"`
+	} `xml:"versioning"`
+}
+
+// Versions lists the versions of an artifact from its maven-metadata.xml,
+// together with the latest and release versions it records
+func (c *Client) Versions(ctx context.Context, repository, group, artifact string) (versions []string, latest, release string, err error) {
+	metadata, err := c.metadata(ctx, repository, strings.ReplaceAll(group, ".", "/")+"/"+artifact)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return metadata.Versioning.Versions, metadata.Versioning.Latest, metadata.Versioning.Release, nil
+}
+
+// Resolve locates the file for coordinates in a repository. SNAPSHOT versions
+// are resolved to their latest timestamped build through the version-level
+// maven-metadata.xml; release versions map directly to their file.
+func (c *Client) Resolve(ctx context.Context, repository string, coordinates Coordinates) (*ResolvedArtifact, error) {
+	if coordinates.Extension == "" {
+		coordinates.Extension = "jar"
+	}
+	resolved := &ResolvedArtifact{Coordinates: coordinates, ResolvedVersion: coordinates.Version}
+
+	if coordinates.IsSnapshot() {
+		metadata, err := c.metadata(ctx, repository, coordinates.VersionPath())
+		if err != nil {
+			return nil, err
+		}
+		value, updated := snapshotValue(metadata, coordinates)
+		if value == "" {
+			return nil, fmt.Errorf("no snapshot of %s:%s:%s with classifier '%s' and extension '%s' in %s",
+				coordinates.Group, coordinates.Artifact, coordinates.Version, coordinates.Classifier, coordinates.Extension, repository)
+		}
+		resolved.ResolvedVersion = value
+		resolved.Updated = updated
+	}
+
+	resolved.URL = c.RepositoryURL(repository) + coordinates.VersionPath() + "/" + coordinates.FileName(resolved.ResolvedVersion)
+	return resolved, nil
+}
+
+// snapshotValue picks the timestamped version matching classifier and extension,
+// falling back to the <snapshot> element for metadata without snapshotVersions.
+// Metadata that lists its files but not this one has no such file.
+func snapshotValue(metadata *mavenMetadata, coordinates Coordinates) (string, time.Time) {
+	for _, snapshot := range metadata.Versioning.SnapshotVersions {
+		if snapshot.Classifier == coordinates.Classifier && snapshot.Extension == coordinates.Extension {
+			return snapshot.Value, parseMavenTimestamp(snapshot.Updated)
+		}
+	}
+	if len(metadata.Versioning.SnapshotVersions) > 0 {
+		return "", time.Time{}
+	}
+
+	snapshot := metadata.Versioning.Snapshot
+	if snapshot.Timestamp == "" || snapshot.BuildNumber == 0 {
+		return "", time.Time{}
+	}
+	base := strings.TrimSuffix(coordinates.Version, "-SNAPSHOT")
+	return fmt.Sprintf("%s-%s-%d", base, snapshot.Timestamp, snapshot.BuildNumber), parseMavenTimestamp(metadata.Versioning.LastUpdated)
+}
+
+// parseMavenTimestamp parses the yyyyMMddHHmmss timestamps of maven-metadata.xml
+func parseMavenTimestamp(value string) time.Time {
+	parsed, err := time.Parse("20060102150405", value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+// metadata fetches and parses maven-metadata.xml below a repository path
+func (c *Client) metadata(ctx context.Context, repository, path string) (*mavenMetadata, error) {
+	body, err := c.get(ctx, c.RepositoryURL(repository)+path+"/maven-metadata.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata mavenMetadata
+	if err := xml.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse maven-metadata.xml of %s: %w", path, err)
+	}
+	return &metadata, nil
+}
+
+// get performs an authenticated GET and returns the body of a 200 response
+func (c *Client) get(ctx context.Context, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	req.Header.Set("Accept", "application/json, application/xml")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Nexus request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{URL: target, StatusCode: resp.StatusCode}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func setParam(params url.Values, key, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
@@ -0,0 +1,217 @@
+package nexus_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"app/internal/nexus"
+	"app/internal/nexus/nexustest"
+)
+
+const artifactMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>com.amdocs.oss.att.customization</groupId>
+  <artifactId>att-orchestration</artifactId>
+  <versioning>
+    <latest>10.4-main-SNAPSHOT</latest>
+    <release>10.3.2</release>
+    <versions>
+      <version>10.3.2</version>
+      <version>10.4-feature-x-SNAPSHOT</version>
+      <version>10.4-main-SNAPSHOT</version>
+    </versions>
+    <lastUpdated>20240611093000</lastUpdated>
+  </versioning>
+</metadata>`
+
+const snapshotMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata modelVersion="1.1.0">
+  <groupId>com.amdocs.oss.att.customization</groupId>
+  <artifactId>att-orchestration</artifactId>
+  <version>10.4-main-SNAPSHOT</version>
+  <versioning>
+    <snapshot>
+      <timestamp>20240611.093000</timestamp>
+      <buildNumber>7</buildNumber>
+    </snapshot>
+    <lastUpdated>20240611093000</lastUpdated>
+    <snapshotVersions>
+      <snapshotVersion>
+        <extension>pom</extension>
+        <value>10.4-main-20240611.093000-7</value>
+        <updated>20240611093000</updated>
+      </snapshotVersion>
+      <snapshotVersion>
+        <classifier>src</classifier>
+        <extension>zip</extension>
+        <value>10.4-main-20240610.170512-6</value>
+        <updated>20240610170512</updated>
+      </snapshotVersion>
+    </snapshotVersions>
+  </versioning>
+</metadata>`
+
+// legacyMetadata has only the <snapshot> element, as older deployers write it
+const legacyMetadata = `<metadata>
+  <version>10.4-feature-x-SNAPSHOT</version>
+  <versioning>
+    <snapshot>
+      <timestamp>20240501.080000</timestamp>
+      <buildNumber>3</buildNumber>
+    </snapshot>
+    <lastUpdated>20240501080000</lastUpdated>
+  </versioning>
+</metadata>`
+
+const metadataDir = "att.maven.snapshot/com/amdocs/oss/att/customization/att-orchestration/"
+
+func newFake(t *testing.T) (*nexustest.FakeServer, *nexus.Client) {
+	t.Helper()
+	fake := nexustest.NewFakeServer(2)
+	t.Cleanup(fake.Close)
+	fake.AddFile(metadataDir+"maven-metadata.xml", []byte(artifactMetadata))
+	fake.AddFile(metadataDir+"10.4-main-SNAPSHOT/maven-metadata.xml", []byte(snapshotMetadata))
+	fake.AddFile(metadataDir+"10.4-feature-x-SNAPSHOT/maven-metadata.xml", []byte(legacyMetadata))
+	return fake, nexus.New(nexus.Config{BaseURL: fake.URL + "/"})
+}
+
+func TestVersions(t *testing.T) {
+	_, client := newFake(t)
+
+	versions, latest, release, err := client.Versions(context.Background(), "att.maven.snapshot", "com.amdocs.oss.att.customization", "att-orchestration")
+	if err != nil {
+		t.Fatalf("Versions: %v", err)
+	}
+	want := []string{"10.3.2", "10.4-feature-x-SNAPSHOT", "10.4-main-SNAPSHOT"}
+	if fmt.Sprint(versions) != fmt.Sprint(want) {
+		t.Errorf("versions = %v, want %v", versions, want)
+	}
+	if latest != "10.4-main-SNAPSHOT" || release != "10.3.2" {
+		t.Errorf("latest, release = %q, %q", latest, release)
+	}
+
+	_, _, _, err = client.Versions(context.Background(), "att.maven.snapshot", "com.amdocs.oss.att.customization", "missing")
+	if !nexus.IsNotFound(err) {
+		t.Errorf("missing artifact error = %v, want a 404", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	fake, client := newFake(t)
+	coordinates := nexus.Coordinates{
+		Group:    "com.amdocs.oss.att.customization",
+		Artifact: "att-orchestration",
+	}
+	base := fake.URL + "/repository/" + metadataDir
+
+	tests := []struct {
+		name        string
+		version     string
+		classifier  string
+		extension   string
+		wantVersion string
+		wantURL     string
+		wantUpdated time.Time
+		wantErr     bool
+	}{
+		{
+			name:    "snapshot picks the classifier and extension",
+			version: "10.4-main-SNAPSHOT", classifier: "src", extension: "zip",
+			wantVersion: "10.4-main-20240610.170512-6",
+			wantURL:     base + "10.4-main-SNAPSHOT/att-orchestration-10.4-main-20240610.170512-6-src.zip",
+			wantUpdated: time.Date(2024, 6, 10, 17, 5, 12, 0, time.UTC),
+		},
+		{
+			name:    "snapshot without classifier",
+			version: "10.4-main-SNAPSHOT", extension: "pom",
+			wantVersion: "10.4-main-20240611.093000-7",
+			wantURL:     base + "10.4-main-SNAPSHOT/att-orchestration-10.4-main-20240611.093000-7.pom",
+			wantUpdated: time.Date(2024, 6, 11, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:    "snapshot file missing from snapshotVersions",
+			version: "10.4-main-SNAPSHOT", classifier: "docs", extension: "zip",
+			wantErr: true,
+		},
+		{
+			name:    "legacy snapshot element",
+			version: "10.4-feature-x-SNAPSHOT", classifier: "src", extension: "zip",
+			wantVersion: "10.4-feature-x-20240501.080000-3",
+			wantURL:     base + "10.4-feature-x-SNAPSHOT/att-orchestration-10.4-feature-x-20240501.080000-3-src.zip",
+			wantUpdated: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "release maps directly and defaults to jar",
+			version:     "10.3.2",
+			wantVersion: "10.3.2",
+			wantURL:     base + "10.3.2/att-orchestration-10.3.2.jar",
+		},
+		{
+			name:    "snapshot without metadata",
+			version: "10.5-SNAPSHOT", extension: "zip",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := coordinates
+			request.Version, request.Classifier, request.Extension = tt.version, tt.classifier, tt.extension
+
+			resolved, err := client.Resolve(context.Background(), "att.maven.snapshot", request)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Resolve = %+v, want an error", resolved)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if resolved.ResolvedVersion != tt.wantVersion {
+				t.Errorf("ResolvedVersion = %q, want %q", resolved.ResolvedVersion, tt.wantVersion)
+			}
+			if resolved.URL != tt.wantURL {
+				t.Errorf("URL = %q, want %q", resolved.URL, tt.wantURL)
+			}
+			if !resolved.Updated.Equal(tt.wantUpdated) {
+				t.Errorf("Updated = %v, want %v", resolved.Updated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestSearchFollowsContinuationTokens(t *testing.T) {
+	fake, client := newFake(t)
+	for i := 1; i <= 5; i++ {
+		fake.AddComponent(nexus.Component{
+			Repository: "att.maven.snapshot",
+			Group:      "com.amdocs.oss.att.customization",
+			Name:       "att-orchestration",
+			Version:    fmt.Sprintf("10.4-main-20240610.17051%d-%d", i, i),
+			Assets: []nexus.Asset{{
+				Path:   fmt.Sprintf("com/amdocs/oss/att/customization/att-orchestration/10.4-main-SNAPSHOT/att-orchestration-%d-src.zip", i),
+				Maven2: &nexus.MavenAsset{Extension: "zip", Classifier: "src"},
+			}},
+		})
+	}
+	fake.AddComponent(nexus.Component{Repository: "att.maven.snapshot", Group: "com.amdocs.oss.att.customization", Name: "att-other", Version: "1.0"})
+
+	components, err := client.Search(context.Background(), nexus.SearchQuery{
+		Repository: "att.maven.snapshot",
+		Artifact:   "att-orchestration",
+		Classifier: "src",
+		Extension:  "zip",
+	})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(components) != 5 {
+		t.Fatalf("got %d components over pages of 2, want 5", len(components))
+	}
+	if got := components[0].Assets[0].DownloadURL; got != fake.URL+"/repository/att.maven.snapshot/"+components[0].Assets[0].Path {
+		t.Errorf("DownloadURL = %q", got)
+	}
+}
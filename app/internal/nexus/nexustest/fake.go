@@ -0,0 +1,144 @@
+// Package nexustest provides an in-process fake Nexus for exercising the
+// nexus client and its callers without a real server.
+package nexustest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"app/internal/nexus"
+)
+
+// FakeServer is an in-process Nexus serving the search API with continuation
+// tokens and arbitrary repository files such as maven-metadata.xml
+type FakeServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	pageSize   int
+	components []nexus.Component
+	files      map[string][]byte // by path below /repository/
+}
+
+// NewFakeServer starts a fake Nexus returning pageSize components per search page
+func NewFakeServer(pageSize int) *FakeServer {
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	fake := &FakeServer{pageSize: pageSize, files: make(map[string][]byte)}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	return fake
+}
+
+// AddComponent adds a search result. Assets without a download URL are given
+// one on the fake server, below the component's repository.
+func (f *FakeServer) AddComponent(component nexus.Component) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range component.Assets {
+		asset := &component.Assets[i]
+		if asset.DownloadURL == "" {
+			asset.DownloadURL = f.URL + "/repository/" + component.Repository + "/" + asset.Path
+		}
+	}
+	f.components = append(f.components, component)
+}
+
+// AddFile serves content at /repository/<path>, e.g. "repo/com/a/b/1.0-SNAPSHOT/maven-metadata.xml"
+func (f *FakeServer) AddFile(path string, content []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.files[strings.TrimPrefix(path, "/")] = content
+}
+
+func (f *FakeServer) serve(response http.ResponseWriter, request *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case request.URL.Path == "/service/rest/v1/search":
+		f.serveSearch(response, request)
+	case request.URL.Path == "/service/rest/v1/status":
+		response.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(request.URL.Path, "/repository/"):
+		content, ok := f.files[strings.TrimPrefix(request.URL.Path, "/repository/")]
+		if !ok {
+			http.NotFound(response, request)
+			return
+		}
+		_, _ = response.Write(content)
+	default:
+		http.NotFound(response, request)
+	}
+}
+
+// serveSearch filters components like Nexus does and pages them; the
+// continuation token is the offset of the next page
+func (f *FakeServer) serveSearch(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	var matches []nexus.Component
+	for _, component := range f.components {
+		if matchesFake(query.Get("repository"), component.Repository) &&
+			matchesFake(query.Get("group"), component.Group) &&
+			matchesFake(query.Get("name"), component.Name) &&
+			matchesFake(query.Get("version"), component.Version) &&
+			(query.Get("q") == "" || strings.Contains(component.Name, query.Get("q"))) &&
+			hasFakeAsset(component, query.Get("maven.classifier"), query.Get("maven.extension")) {
+			matches = append(matches, component)
+		}
+	}
+
+	offset := 0
+	if token := query.Get("continuationToken"); token != "" {
+		parsed, err := strconv.Atoi(token)
+		if err != nil || parsed < 0 || parsed > len(matches) {
+			http.Error(response, "invalid continuation token", http.StatusBadRequest)
+			return
+		}
+		offset = parsed
+	}
+
+	end := offset + f.pageSize
+	page := nexus.SearchPage{Items: []nexus.Component{}}
+	if end < len(matches) {
+		page.ContinuationToken = strconv.Itoa(end)
+	} else {
+		end = len(matches)
+	}
+	page.Items = append(page.Items, matches[offset:end]...)
+
+	response.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(response).Encode(page)
+}
+
+// matchesFake compares a search parameter, supporting a trailing * wildcard
+func matchesFake(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(value, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == value
+}
+
+func hasFakeAsset(component nexus.Component, classifier, extension string) bool {
+	if classifier == "" && extension == "" {
+		return true
+	}
+	for _, asset := range component.Assets {
+		if asset.Maven2 == nil {
+			continue
+		}
+		if matchesFake(classifier, asset.Maven2.Classifier) && matchesFake(extension, asset.Maven2.Extension) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package nexus
+
+import "strings"
+
+// ProxyRewriter maps download URLs of the public Nexus repository onto the
+// internal proxy, which is reachable from the build machines
+type ProxyRewriter struct {
+	from string
+	to   string
+}
+
+// NewProxyRewriter creates a rewriter from the public repository base URL to
+// the internal proxy base URL. Either being empty disables rewriting.
+func NewProxyRewriter(from, to string) *ProxyRewriter {
+	return &ProxyRewriter{from: withTrailingSlash(from), to: withTrailingSlash(to)}
+}
+
+// Rewrite returns the proxy URL for rawURL, or rawURL unchanged when it does
+// not live below the public repository
+func (r *ProxyRewriter) Rewrite(rawURL string) string {
+	if r == nil || r.from == "/" || r.to == "/" {
+		return rawURL
+	}
+	if strings.HasPrefix(rawURL, r.from) {
+		return r.to + strings.TrimPrefix(rawURL, r.from)
+	}
+	return rawURL
+}
+
+func withTrailingSlash(value string) string {
+	return strings.TrimRight(strings.TrimSpace(value), "/") + "/"
+}
@@ -0,0 +1,96 @@
+package nexus
+
+import (
+	"strings"
+	"time"
+)
+
+// SearchQuery selects components in the Nexus search API. Empty fields are not filtered on.
+type SearchQuery struct {
+	Repository string
+	Group      string
+	Artifact   string // maven artifactId, "name" in the Nexus API
+	Version    string
+	Classifier string
+	Extension  string
+	Keyword    string // free text, "q" in the Nexus API
+}
+
+// Component is one search result: a Maven artifact version and its files
+type Component struct {
+	ID         string  `json:"id"`
+	Repository string  `json:"repository"`
+	Format     string  `json:"format"`
+	Group      string  `json:"group"`
+	Name       string  `json:"name"`
+	Version    string  `json:"version"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is one file of a component
+type Asset struct {
+	ID           string            `json:"id"`
+	Repository   string            `json:"repository"`
+	DownloadURL  string            `json:"downloadUrl"`
+	Path         string            `json:"path"`
+	Format       string            `json:"format"`
+	Checksum     map[string]string `json:"checksum,omitempty"`
+	LastModified *time.Time        `json:"lastModified,omitempty"`
+	Maven2       *MavenAsset       `json:"maven2,omitempty"`
+}
+
+// MavenAsset holds the Maven coordinates Nexus reports for an asset
+type MavenAsset struct {
+	Extension  string `json:"extension"`
+	Classifier string `json:"classifier,omitempty"`
+	GroupID    string `json:"groupId"`
+	ArtifactID string `json:"artifactId"`
+	Version    string `json:"version"`
+}
+
+// SearchPage is one page of search results
+type SearchPage struct {
+	Items             []Component `json:"items"`
+	ContinuationToken string      `json:"continuationToken"`
+}
+
+// Coordinates identify a Maven artifact file
+type Coordinates struct {
+	Group      string `json:"group"`
+	Artifact   string `json:"artifact"`
+	Version    string `json:"version"` // base version, e.g. "10.4-x-SNAPSHOT"
+	Classifier string `json:"classifier,omitempty"`
+	Extension  string `json:"extension"`
+}
+
+// IsSnapshot reports whether the coordinates refer to a SNAPSHOT version
+func (c Coordinates) IsSnapshot() bool {
+	return strings.HasSuffix(c.Version, "-SNAPSHOT")
+}
+
+// VersionPath returns the repository path of the version directory
+func (c Coordinates) VersionPath() string {
+	return strings.ReplaceAll(c.Group, ".", "/") + "/" + c.Artifact + "/" + c.Version
+}
+
+// FileName returns the file name for a resolved version, which differs from
+// the base version for timestamped snapshots
+func (c Coordinates) FileName(resolvedVersion string) string {
+	extension := c.Extension
+	if extension == "" {
+		extension = "jar"
+	}
+	name := c.Artifact + "-" + resolvedVersion
+	if c.Classifier != "" {
+		name += "-" + c.Classifier
+	}
+	return name + "." + extension
+}
+
+// ResolvedArtifact is an artifact file located in a repository
+type ResolvedArtifact struct {
+	Coordinates
+	ResolvedVersion string    `json:"resolved_version"` // timestamped version for snapshots
+	URL             string    `json:"url"`
+	Updated         time.Time `json:"updated,omitempty"`
+}
@@ -7,7 +7,9 @@ type Response struct {
 }
 
 type DeployRequest struct {
-    FolderPath string `json:"folderPath"`
+    FolderPath    string            `json:"folderPath"`
+    Namespace     string            `json:"namespace,omitempty"`     // default: the scripts' "dop"
+    Microservices map[string]string `json:"microservices,omitempty"` // service -> microservice to patch, "customization" for the customization backend
 }
 
 type BrowseResponse struct {
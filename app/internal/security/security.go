@@ -41,4 +41,35 @@ func SanitizePath(path string) string {
     return absPath
 }
 
+var (
+    kubernetesName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+    serviceName    = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// ValidateKubernetesName ensures a namespace or microservice name is a valid
+// DNS label, which also makes it safe to pass to the deploy scripts
+func ValidateKubernetesName(name string) error {
+    if len(name) > 63 || !kubernetesName.MatchString(name) {
+        return fmt.Errorf("invalid Kubernetes name %q", name)
+    }
+    return nil
+}
 
+// ValidateDeployTarget checks the namespace and the service to microservice
+// overrides of a deploy; both are optional
+func ValidateDeployTarget(namespace string, microservices map[string]string) error {
+    if namespace != "" {
+        if err := ValidateKubernetesName(namespace); err != nil {
+            return fmt.Errorf("namespace: %v", err)
+        }
+    }
+    for service, microservice := range microservices {
+        if !serviceName.MatchString(service) {
+            return fmt.Errorf("invalid service name %q", service)
+        }
+        if err := ValidateKubernetesName(microservice); err != nil {
+            return fmt.Errorf("microservice for %s: %v", service, err)
+        }
+    }
+    return nil
+}
@@ -1,5 +1,6 @@
 export const CONFIG = {
     HISTORY_KEY: 'ocd-folder-history',
+    NAMESPACE_KEY: 'ocd-deploy-namespace',
     MAX_HISTORY_ITEMS: 10,
     WEBSOCKET_TIMEOUT: 30000,
     STATUS_DISPLAY_TIME: 5000
@@ -10,7 +11,8 @@ export const STAGE_LABELS = {
     settings: 'Maven Settings XML Update',
     build: 'Building Microservices',
     deploy: 'Docker Image Creation',
-    patch: 'Kubernetes Deployment'
+    patch: 'Kubernetes Deployment',
+    verify: 'Rollout Verification'
 };
 
 export const PROGRESS_STAGES = [
@@ -18,5 +20,6 @@ export const PROGRESS_STAGES = [
     { id: 'settings', label: 'Maven Settings XML Update', status: 'pending' },
     { id: 'build', label: 'Building Microservices', status: 'pending' },
     { id: 'deploy', label: 'Docker Image Creation', status: 'pending' },
-    { id: 'patch', label: 'Kubernetes Deployment', status: 'pending' }
+    { id: 'patch', label: 'Kubernetes Deployment', status: 'pending' },
+    { id: 'verify', label: 'Rollout Verification', status: 'pending' }
 ];
\ No newline at end of file
@@ -120,8 +120,10 @@ export function initializeHFAdoption() {
             payload.dry_run = false;
             res = await fetch('/api/hf/update-pom', { method: 'POST', headers: headers, body: JSON.stringify(payload) });
             data = await res.json();
-            if (data.success) {
-                showStatus('POM updated and pushed successfully', 'success');
+            if (data.success && data.pr_url) {
+                showStatus(`Pull request opened: ${data.pr_url}`, 'success');
+            } else if (data.success) {
+                showStatus(data.message || 'POM updated and pushed successfully', 'success');
             } else {
                 showStatus(data.message || 'Apply failed', 'error');
             }
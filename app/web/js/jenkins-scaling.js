@@ -2,9 +2,9 @@
 import { areCredentialsConfigured, showSetupModal, getSavedCredentials } from './settings.js';
 import { getSelectedClusters, clearSelectedClusters } from './cluster-selector.js';
 
-let currentJobNumber = null;
-let currentQueueURL = null;
+let currentBatchID = null;
 let statusPollingInterval = null;
+const verifyStreams = new Map();
 
 export function initializeScaling() {
     const scaleUpBtn = document.getElementById('scale-up-btn');
@@ -35,86 +35,62 @@ async function handleScaleAction(scaleType) {
     
     const clusterCount = selectedClusters.length;
     const clusterText = clusterCount === 1 ? selectedClusters[0] : `${clusterCount} clusters`;
-    showScalingStatus('queued', `Triggering scale up for ${clusterText}...`);
+    showScalingStatus('queued', `Triggering scale ${scaleType} for ${clusterText}...`);
 
     try {
         const credentials = getSavedCredentials();
-        const scalingPromises = selectedClusters.map(clusterName => 
-            triggerClusterScale(clusterName, scaleType, credentials)
-        );
-
-        const results = await Promise.allSettled(scalingPromises);
-
-        const successful = results.filter(r => r.status === 'fulfilled' && r.value.success);
-        const failed = results.filter(r => r.status === 'rejected' || !r.value.success);
-
-        console.log(`[SCALING] Results summary:`, {
-            total: selectedClusters.length,
-            successful: successful.length,
-            failed: failed.length,
-            failedReasons: failed.map(f => ({
-                status: f.status,
-                reason: f.status === 'rejected' ? f.reason.message : 'Success=false in response',
-                value: f.status === 'fulfilled' ? f.value : undefined
-            }))
-        });
+        const batch = await triggerBatchScale(selectedClusters, scaleType, credentials);
+        const triggered = batch.items.filter(item => item.state === 'triggered');
+        const problems = batch.items.filter(item => item.state === 'failed' || item.state === 'refused');
 
-        if (successful.length === selectedClusters.length) {
-            const clusterNames = selectedClusters.join(', ');
-            showScalingMessage(`Successfully triggered scale up for ${clusterNames}!`, 'success');
-            showScalingStatus('success', `Scale up triggered for ${clusterNames}`);
-        } else if (successful.length > 0) {
-            const failedClusters = failed.map(f => f.status === 'rejected' ? 'Unknown' : 'Response error');
-            console.warn(`[SCALING] Partial failure - failed clusters:`, failedClusters);
-            showScalingMessage(`Scale up triggered for ${successful.length}/${clusterCount} clusters`, 'warning');
-            showScalingStatus('warning', `Partial success: ${successful.length}/${clusterCount} clusters`);
-        } else {
-            // Collect all error messages for detailed logging
-            const errorMessages = failed.map(f =>
-                f.status === 'rejected' ? f.reason.message : 'Success=false in response'
-            );
+        console.log(`[SCALING] Batch ${batch.batch_id} summary:`, batch.counts);
+
+        if (triggered.length === 0) {
+            const errorMessages = problems.map(item => `${item.cluster_name}: ${item.message}`);
             console.error(`[SCALING] All clusters failed. Errors:`, errorMessages);
             throw new Error(`Failed to trigger scaling for any clusters. Errors: ${errorMessages.join(', ')}`);
         }
 
-        // Show the first successful Jenkins link and start polling for queue updates
-        const firstSuccess = successful.find(r => r.value.job_status?.url);
-        if (firstSuccess) {
-            const jobStatus = firstSuccess.value.job_status;
-            showJenkinsLink(jobStatus.url);
-            
-            // If it's a queue URL, start queue polling to get the actual job URL
-            if (jobStatus.url.includes('/queue/item/')) {
-                currentQueueURL = jobStatus.url;
-                startQueuePolling();
-            } else if (jobStatus.number) {
-                // If we already have a job number, start regular polling
-                currentJobNumber = jobStatus.number;
-                startStatusPolling();
-            }
+        if (problems.length === 0) {
+            showScalingMessage(`Successfully triggered scale ${scaleType} for ${triggered.map(item => item.cluster_name).join(', ')}!`, 'success');
+        } else {
+            console.warn(`[SCALING] Partial failure:`, problems);
+            showScalingMessage(
+                `Scale ${scaleType} triggered for ${triggered.length}/${clusterCount} clusters. ` +
+                problems.map(item => `${item.cluster_name}: ${item.message}`).join('; '),
+                'warning'
+            );
         }
+        showScalingStatus('queued', describeBatch(batch));
 
-        // Clear selection after successful scaling
-        if (successful.length > 0) {
-            setTimeout(() => {
-                clearSelectedClusters();
-            }, 2000);
+        const firstJob = triggered.find(item => item.job_status?.url);
+        if (firstJob) {
+            showJenkinsLink(firstJob.job_status.url);
         }
 
+        currentBatchID = batch.batch_id;
+        startBatchPolling(scaleType);
+
+        // Clear selection after successful scaling
+        setTimeout(() => {
+            clearSelectedClusters();
+        }, 2000);
     } catch (error) {
         console.error('Scaling error:', error);
-        showScalingMessage(`Failed to trigger scale up: ${error.message}`, 'error');
-        showScalingStatus('failed', 'Failed to start scale up');
-    } finally {
+        showScalingMessage(`Failed to trigger scale ${scaleType}: ${error.message}`, 'error');
+        showScalingStatus('failed', `Failed to start scale ${scaleType}`);
         setScalingButtonsState(false);
     }
 }
 
-async function triggerClusterScale(clusterName, scaleType, credentials) {
+// triggerBatchScale scales all clusters in one batch request and returns the
+// aggregate result
+async function triggerBatchScale(clusters, scaleType, credentials, policyAnswers) {
     const requestBody = {
-        cluster_name: clusterName,
+        clusters: clusters,
         scale_type: scaleType,
-        account: 'ATT'
+        account: 'ATT',
+        ...(policyAnswers || {})
     };
 
     // Add credentials to request if available
@@ -123,10 +99,9 @@ async function triggerClusterScale(clusterName, scaleType, credentials) {
         requestBody.token = credentials.token;
     }
 
-    console.log(`[SCALING] Attempting to scale ${scaleType} cluster: ${clusterName}`);
-    console.log(`[SCALING] Request payload:`, requestBody);
+    console.log(`[SCALING] Attempting to scale ${scaleType} clusters: ${clusters.join(', ')}`);
 
-    const response = await fetch('/api/jenkins/scale', {
+    const response = await fetch('/api/scaling/batch', {
         method: 'POST',
         headers: {
             'Content-Type': 'application/json',
@@ -135,149 +110,222 @@ async function triggerClusterScale(clusterName, scaleType, credentials) {
     });
 
     const result = await response.json();
-    console.log(`[SCALING] Response for cluster ${clusterName}:`, {
-        status: response.status,
-        ok: response.ok,
-        result: result
-    });
 
-    if (!response.ok) {
-        const errorMsg = result.message || `HTTP ${response.status}`;
-        console.error(`[SCALING] Failed to scale cluster ${clusterName}:`, errorMsg);
-        console.error(`[SCALING] Full error response:`, result);
-        throw new Error(`${clusterName}: ${errorMsg}`);
+    // The scaling policy refused the batch: ask for what it needs and try once more
+    if (response.status === 403 && Array.isArray(result.violations) && !policyAnswers) {
+        const label = clusters.length === 1 ? clusters[0] : `${clusters.length} clusters`;
+        const answers = askForPolicyAnswers(label, result.violations);
+        if (answers) {
+            return triggerBatchScale(clusters, scaleType, credentials, answers);
+        }
     }
 
-    if (!result.success) {
-        const errorMsg = result.message || 'Unknown error';
-        console.error(`[SCALING] Scaling failed for cluster ${clusterName}:`, errorMsg);
-        console.error(`[SCALING] Full error response:`, result);
-        throw new Error(`${clusterName}: ${errorMsg}`);
+    if (!response.ok || !result.batch) {
+        const errorMsg = result.message || result.error || `HTTP ${response.status}`;
+        console.error(`[SCALING] Batch scaling failed:`, result);
+        throw new Error(errorMsg);
     }
 
-    console.log(`[SCALING] Successfully triggered scaling for cluster ${clusterName}`);
-    return result;
+    return result.batch;
+}
+
+// askForPolicyAnswers prompts for the reason and confirmation phrase the scaling
+// policy asked for. Returns null when a violation cannot be fixed by the user
+// (protected cluster, business hours) or the user cancels.
+function askForPolicyAnswers(label, violations) {
+    const answers = {};
+    for (const violation of violations) {
+        if (violation.rule === 'reason_required') {
+            const reason = window.prompt(`Why are you scaling ${label}?`);
+            if (!reason || !reason.trim()) return null;
+            answers.reason = reason.trim();
+        } else if (violation.rule === 'confirmation_required') {
+            const typed = window.prompt(`${violation.message}\n\nType: ${violation.confirmation_phrase}`);
+            if (!typed) return null;
+            answers.confirmation = typed;
+        } else {
+            return null;
+        }
+    }
+    return answers;
 }
 
-function startQueuePolling() {
-    if (!currentQueueURL) return;
+// startBatchPolling follows every cluster of the current batch from the Jenkins
+// queue to its build, then verifies the clusters whose job succeeded
+function startBatchPolling(scaleType) {
+    if (!currentBatchID) return;
 
     // Clear any existing polling
     if (statusPollingInterval) {
         clearInterval(statusPollingInterval);
     }
 
-    // Poll every 5 seconds (more frequent for queue items)
+    const verified = new Set();
+
+    // Poll every 10 seconds
     statusPollingInterval = setInterval(async () => {
         try {
             const credentials = getSavedCredentials();
             if (!credentials) {
-                console.error('No credentials available for queue polling');
+                console.error('No credentials available for status polling');
                 return;
             }
 
-            const response = await fetch('/api/jenkins/queue', {
+            const response = await fetch('/api/scaling/batch/status', {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
                 },
                 body: JSON.stringify({
-                    queue_url: currentQueueURL,
+                    batch_id: currentBatchID,
                     username: credentials.username,
                     token: credentials.token
                 })
             });
             const result = await response.json();
-
-            if (result.success && result.job_status) {
-                const status = result.job_status.status;
-                const description = result.job_status.description;
-
-                updateScalingStatus(status, description);
-
-                // If job has started (got a job number), switch to regular polling
-                if (result.job_status.number && result.job_status.url && !result.job_status.url.includes('/queue/item/')) {
-                    currentJobNumber = result.job_status.number;
-                    currentQueueURL = null;
-                    
-                    // Update the Jenkins link to the actual job
-                    showJenkinsLink(result.job_status.url);
-                    
-                    // Clear queue polling and start job polling
-                    clearInterval(statusPollingInterval);
-                    startStatusPolling();
-                    return;
-                }
-
-                // Stop polling if job is cancelled or failed
-                if (status === 'failed') {
-                    clearInterval(statusPollingInterval);
-                    statusPollingInterval = null;
-                    setScalingButtonsState(false);
-                    showScalingMessage('Environment scaling was cancelled or failed.', 'error');
+            if (!result.success || !result.batch) return;
+
+            const batch = result.batch;
+            updateScalingStatus(batch.done ? 'success' : 'running', describeBatch(batch));
+
+            batch.items
+                .filter(item => item.state === 'succeeded' && !verified.has(item.cluster_name))
+                .forEach(item => {
+                    verified.add(item.cluster_name);
+                    startClusterVerification(item.cluster_name, scaleType);
+                });
+
+            // Stop polling once every job has finished
+            if (batch.done) {
+                clearInterval(statusPollingInterval);
+                statusPollingInterval = null;
+                setScalingButtonsState(false);
+
+                const failed = (batch.counts.failed || 0) + (batch.counts.refused || 0);
+                if (failed === 0) {
+                    showScalingMessage('Scaling jobs finished, verifying the clusters...', 'success');
+                } else {
+                    updateScalingStatus('failed', describeBatch(batch));
+                    showScalingMessage(`Scaling failed for ${failed}/${batch.total} clusters. Check Jenkins for details.`, 'error');
                 }
             }
         } catch (error) {
-            console.error('Queue polling error:', error);
+            console.error('Status polling error:', error);
             // Don't stop polling on single errors, but log them
         }
-    }, 5000); // Poll every 5 seconds
+    }, 10000); // Poll every 10 seconds
+}
+
+// describeBatch summarises the per-cluster states of a batch
+function describeBatch(batch) {
+    const labels = ['succeeded', 'running', 'triggered', 'unknown', 'failed', 'refused'];
+    const parts = labels
+        .filter(label => batch.counts?.[label])
+        .map(label => `${batch.counts[label]} ${label === 'triggered' ? 'queued' : label}`);
+    return `${batch.total} cluster${batch.total === 1 ? '' : 's'}: ${parts.join(', ')}`;
 }
 
-function startStatusPolling() {
-    if (!currentJobNumber) return;
+// startClusterVerification follows the server-side check that a cluster's
+// node groups, nodes and dop pods caught up with the scaling job
+async function startClusterVerification(clusterName, scaleType) {
+    const container = document.getElementById('scaling-verify');
+    if (!container) return;
 
-    // Clear any existing polling
-    if (statusPollingInterval) {
-        clearInterval(statusPollingInterval);
+    try {
+        const response = await fetch('/api/scaling/verify', {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ cluster_name: clusterName, scale_type: scaleType })
+        });
+        const result = await response.json();
+        if (!response.ok || !result.success) {
+            throw new Error(result.message || result.error || `HTTP ${response.status}`);
+        }
+        followVerification(container, clusterName, result.session_id);
+    } catch (error) {
+        console.error(`[SCALING] Could not start verification for ${clusterName}:`, error);
+        showScalingMessage(`Could not verify ${clusterName}: ${error.message}`, 'warning');
     }
+}
 
-    // Poll every 10 seconds
-    statusPollingInterval = setInterval(async () => {
-        try {
-            const credentials = getSavedCredentials();
-            if (!credentials) {
-                console.error('No credentials available for status polling');
+function followVerification(container, clusterName, sessionId) {
+    verifyStreams.get(clusterName)?.close();
+
+    let card = container.querySelector(`[data-cluster="${CSS.escape(clusterName)}"]`);
+    if (!card) {
+        card = document.createElement('div');
+        card.className = 'verify-cluster';
+        card.dataset.cluster = clusterName;
+        card.innerHTML = `
+            <div class="verify-header">
+                <span class="verify-name"></span>
+                <span class="verify-state">Verifying...</span>
+            </div>
+            <div class="verify-progress"></div>
+            <ul class="verify-timeline"></ul>`;
+        card.querySelector('.verify-name').textContent = clusterName;
+        container.appendChild(card);
+    }
+    container.style.display = 'flex';
+    const state = card.querySelector('.verify-state');
+    const progress = card.querySelector('.verify-progress');
+    const timeline = card.querySelector('.verify-timeline');
+    timeline.innerHTML = '';
+
+    const stream = new EventSource(`/api/scaling/verify/stream/${sessionId}`);
+    verifyStreams.set(clusterName, stream);
+
+    stream.onmessage = (message) => {
+        const event = JSON.parse(message.data);
+        switch (event.kind) {
+            case 'check': {
+                const waiting = event.status?.not_ready_pods || [];
+                progress.textContent = waiting.length
+                    ? `${event.message}. Waiting on: ${waiting.join(', ')}`
+                    : event.message;
                 return;
             }
+            case 'keepalive':
+                return;
+            case 'end':
+                stream.close();
+                verifyStreams.delete(clusterName);
+                return;
+        }
 
-            const response = await fetch('/api/jenkins/status', {
-                method: 'POST',
-                headers: {
-                    'Content-Type': 'application/json',
-                },
-                body: JSON.stringify({
-                    job_number: currentJobNumber,
-                    username: credentials.username,
-                    token: credentials.token
-                })
-            });
-            const result = await response.json();
-
-            if (result.success && result.job_status) {
-                const status = result.job_status.status;
-                const description = result.job_status.description || `Job #${currentJobNumber}`;
-
-                updateScalingStatus(status, description);
-
-                // Stop polling if job is finished
-                if (status === 'success' || status === 'failed') {
-                    clearInterval(statusPollingInterval);
-                    statusPollingInterval = null;
-                    setScalingButtonsState(false);
-
-                    if (status === 'success') {
-                        showScalingMessage('Environment scaling completed successfully!', 'success');
-                    } else {
-                        showScalingMessage('Environment scaling failed. Check Jenkins for details.', 'error');
-                    }
-                }
-            }
-        } catch (error) {
-            console.error('Status polling error:', error);
-            // Don't stop polling on single errors, but log them
+        const item = document.createElement('li');
+        item.className = event.kind;
+        const elapsed = document.createElement('span');
+        elapsed.className = 'verify-elapsed';
+        elapsed.textContent = formatElapsed(event.elapsed_seconds || 0);
+        const text = document.createElement('span');
+        text.textContent = event.message;
+        item.append(elapsed, text);
+        timeline.appendChild(item);
+
+        if (event.kind === 'ready') {
+            state.textContent = 'Cluster ready';
+            state.className = 'verify-state ready';
+            showScalingMessage(`${clusterName}: ${event.message}`, 'success');
+        } else if (event.kind === 'timeout') {
+            state.textContent = 'Not ready';
+            state.className = 'verify-state failed';
         }
-    }, 10000); // Poll every 10 seconds
+    };
+    stream.onerror = () => {
+        // The stream ends with an "end" event; an error before that is a lost connection
+        if (stream.readyState === EventSource.CLOSED) {
+            verifyStreams.delete(clusterName);
+        }
+    };
+}
+
+function formatElapsed(seconds) {
+    const minutes = Math.floor(seconds / 60);
+    const rest = Math.floor(seconds % 60);
+    return `${minutes}:${String(rest).padStart(2, '0')}`;
 }
 
 function showScalingStatus(status, text) {
@@ -334,4 +382,5 @@ window.addEventListener('beforeunload', () => {
     if (statusPollingInterval) {
         clearInterval(statusPollingInterval);
     }
+    verifyStreams.forEach(stream => stream.close());
 });
\ No newline at end of file
@@ -0,0 +1,298 @@
+import { CONFIG } from './constants.js';
+
+// Chooses where a deploy patches: the namespace, and the exact microservice a
+// service goes to when OCD cannot tell it by name. Chosen microservices are
+// remembered per project folder and namespace for later deploys.
+export class KubeTargetSelector {
+    constructor(namespaceSelect, browseButton, browser, mappingsList, note, folderInput, chooser) {
+        this.namespaceSelect = namespaceSelect;
+        this.browseButton = browseButton;
+        this.browser = browser;
+        this.mappingsList = mappingsList;
+        this.note = note;
+        this.folderInput = folderInput;
+        this.chooser = chooser;
+        this.mappings = [];
+        this.microservices = [];
+        this.mappingsTimer = null;
+
+        this.namespaceSelect.addEventListener('change', () => this.handleNamespaceChange());
+        this.browseButton.addEventListener('click', () => this.toggleBrowser());
+    }
+
+    isCustomization() {
+        return this.folderInput.value.toLowerCase().includes('customization');
+    }
+
+    async loadNamespaces() {
+        const saved = localStorage.getItem(CONFIG.NAMESPACE_KEY);
+        try {
+            const response = await fetch('/api/kube/namespaces');
+            const data = await response.json();
+            if (!data.success) {
+                throw new Error(data.message);
+            }
+            const selected = saved || data.default_namespace;
+            this.namespaceSelect.innerHTML = '';
+            const names = data.namespaces.map(namespace => namespace.name);
+            if (selected && !names.includes(selected)) {
+                data.namespaces.unshift({ name: selected, microservices: -1 });
+            }
+            data.namespaces.forEach(namespace => {
+                const option = document.createElement('option');
+                option.value = namespace.name;
+                option.textContent = namespace.microservices >= 0 ?
+                    `${namespace.name} (${namespace.microservices} microservices)` :
+                    namespace.name;
+                option.selected = namespace.name === selected;
+                this.namespaceSelect.appendChild(option);
+            });
+            this.showNote(data.message || `Cluster: ${data.server}`);
+        } catch (error) {
+            // Without cluster access the scripts' default namespace still works
+            this.namespaceSelect.innerHTML = '';
+            const option = document.createElement('option');
+            option.value = saved || 'dop';
+            option.textContent = option.value;
+            this.namespaceSelect.appendChild(option);
+            this.showNote(`Cluster not reachable, microservices are matched by name: ${error.message}`);
+        }
+        this.loadMappings();
+    }
+
+    handleNamespaceChange() {
+        localStorage.setItem(CONFIG.NAMESPACE_KEY, this.namespaceSelect.value);
+        this.clearChooser();
+        this.loadMappings();
+        if (this.browser.style.display !== 'none') {
+            this.loadMicroservices();
+        }
+    }
+
+    // The remembered microservices belong to the folder, so they are reloaded
+    // once the user stops typing it
+    handleFolderChange() {
+        clearTimeout(this.mappingsTimer);
+        this.mappingsTimer = setTimeout(() => {
+            this.clearChooser();
+            this.loadMappings();
+        }, 400);
+    }
+
+    toggleBrowser() {
+        if (this.browser.style.display === 'none') {
+            this.browser.style.display = 'block';
+            this.loadMicroservices();
+        } else {
+            this.browser.style.display = 'none';
+        }
+    }
+
+    async loadMicroservices() {
+        const namespace = this.namespaceSelect.value;
+        this.browser.textContent = `Loading microservices of ${namespace}...`;
+        try {
+            const response = await fetch(`/api/kube/microservices?namespace=${encodeURIComponent(namespace)}`);
+            const data = await response.json();
+            if (!data.success) {
+                throw new Error(data.message);
+            }
+            this.microservices = data.microservices;
+            this.renderMicroservices();
+        } catch (error) {
+            this.browser.textContent = `Failed to load microservices: ${error.message}`;
+        }
+    }
+
+    renderMicroservices() {
+        this.browser.innerHTML = '';
+        if (this.microservices.length === 0) {
+            this.browser.textContent = `No microservices in ${this.namespaceSelect.value}`;
+            return;
+        }
+        const customization = this.isCustomization();
+        this.microservices.forEach(microservice => {
+            const target = customization ? microservice.customization_target : microservice.application_target;
+            const row = document.createElement('div');
+            row.className = 'microservice-row';
+
+            const name = document.createElement('span');
+            name.className = 'microservice-name';
+            name.textContent = microservice.name;
+
+            const image = document.createElement('span');
+            image.className = 'microservice-image';
+            image.textContent = target ?
+                `${target.container} [${target.index}]: ${target.image}` :
+                `no ${customization ? 'customization' : 'application'} init container`;
+            image.title = microservice.init_containers.map(container => `${container.name}: ${container.image}`).join('\n');
+
+            const pin = document.createElement('button');
+            pin.type = 'button';
+            pin.className = 'btn-secondary';
+            pin.textContent = 'Patch this';
+            pin.disabled = !target;
+            pin.addEventListener('click', () => this.pinMicroservice(microservice.name));
+
+            row.append(name, image, pin);
+            this.browser.appendChild(row);
+        });
+    }
+
+    pinMicroservice(microservice) {
+        let service = 'customization';
+        if (!this.isCustomization()) {
+            service = window.prompt(`Which service should be deployed to ${microservice}?`, microservice);
+            if (!service || !/^[a-zA-Z0-9_-]+$/.test(service.trim())) {
+                return;
+            }
+            service = service.trim();
+        }
+        this.confirmMapping(service, microservice);
+    }
+
+    mappingsQuery() {
+        return `folderPath=${encodeURIComponent(this.folderInput.value.trim())}&namespace=${encodeURIComponent(this.namespaceSelect.value)}`;
+    }
+
+    async loadMappings() {
+        if (!this.folderInput.value.trim() || !this.namespaceSelect.value) {
+            this.mappings = [];
+            this.renderMappings();
+            return;
+        }
+        try {
+            const response = await fetch(`/api/deploy/mappings?${this.mappingsQuery()}`);
+            const data = await response.json();
+            this.mappings = data.success ? data.mappings : [];
+        } catch (error) {
+            console.error('Failed to load microservice mappings:', error);
+            this.mappings = [];
+        }
+        this.renderMappings();
+    }
+
+    async confirmMapping(service, microservice) {
+        try {
+            const response = await fetch('/api/deploy/mappings', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({
+                    folderPath: this.folderInput.value.trim(),
+                    namespace: this.namespaceSelect.value,
+                    service: service,
+                    microservice: microservice
+                })
+            });
+            const data = await response.json();
+            if (!data.success) {
+                throw new Error(data.message);
+            }
+            this.showNote(data.message);
+            await this.loadMappings();
+            return true;
+        } catch (error) {
+            this.showNote(`Failed to remember the microservice of ${service}: ${error.message}`);
+            return false;
+        }
+    }
+
+    async forgetMapping(service) {
+        try {
+            const response = await fetch(`/api/deploy/mappings?${this.mappingsQuery()}&service=${encodeURIComponent(service)}`, {
+                method: 'DELETE'
+            });
+            const data = await response.json();
+            if (!data.success) {
+                throw new Error(data.message);
+            }
+        } catch (error) {
+            this.showNote(`Failed to forget the microservice of ${service}: ${error.message}`);
+        }
+        await this.loadMappings();
+    }
+
+    renderMappings() {
+        this.mappingsList.innerHTML = '';
+        this.mappings.forEach(mapping => {
+            const item = document.createElement('div');
+            item.className = 'microservice-override';
+            item.textContent = `${mapping.service} → ${mapping.microservice}`;
+            item.title = `Chosen ${new Date(mapping.confirmed_at).toLocaleString()}`;
+
+            const remove = document.createElement('button');
+            remove.type = 'button';
+            remove.className = 'history-delete';
+            remove.textContent = '×';
+            remove.title = 'Forget, match by name again';
+            remove.addEventListener('click', () => this.forgetMapping(mapping.service));
+
+            item.appendChild(remove);
+            this.mappingsList.appendChild(item);
+        });
+    }
+
+    // Asks which microservice a service goes to after a deploy stopped on it
+    showChooser(data) {
+        const card = document.createElement('div');
+        card.className = 'microservice-chooser';
+
+        const title = document.createElement('div');
+        title.className = 'microservice-chooser-title';
+        title.textContent = data.candidates.length > 0 ?
+            `Several microservices in ${data.namespace} could receive ${data.service}. Which one should be patched?` :
+            `No microservice in ${data.namespace} could receive ${data.service}.`;
+        card.appendChild(title);
+
+        data.candidates.forEach(candidate => {
+            const row = document.createElement('div');
+            row.className = 'microservice-row';
+
+            const name = document.createElement('span');
+            name.className = 'microservice-name';
+            name.textContent = `${candidate.microservice} (score ${candidate.score})`;
+
+            const reasons = document.createElement('span');
+            reasons.className = 'microservice-image';
+            reasons.textContent = candidate.reasons.join(', ');
+            reasons.title = `${candidate.container}: ${candidate.image}`;
+
+            const choose = document.createElement('button');
+            choose.type = 'button';
+            choose.className = 'btn-secondary';
+            choose.textContent = 'Patch this';
+            choose.addEventListener('click', async () => {
+                if (await this.confirmMapping(data.service, candidate.microservice)) {
+                    card.remove();
+                    this.showNote(`${data.service} will be deployed to ${candidate.microservice}. Deploy again to continue.`);
+                }
+            });
+
+            row.append(name, reasons, choose);
+            card.appendChild(row);
+        });
+
+        this.chooser.appendChild(card);
+        this.chooser.style.display = 'block';
+    }
+
+    clearChooser() {
+        this.chooser.innerHTML = '';
+        this.chooser.style.display = 'none';
+    }
+
+    showNote(message) {
+        this.note.textContent = message;
+        this.note.style.display = message ? 'block' : 'none';
+    }
+
+    // Namespace of a deploy request; the remembered microservices are applied
+    // by the server
+    getTarget() {
+        const target = {};
+        if (this.namespaceSelect.value) {
+            target.namespace = this.namespaceSelect.value;
+        }
+        return target;
+    }
+}
@@ -1,6 +1,7 @@
 import { CONFIG } from './constants.js';
 import { generateTimestamp, downloadTextFile, ansiToHtml } from './utils.js';
 import { HistoryManager } from './history-manager.js';
+import { KubeTargetSelector } from './kube-target.js';
 import { ProgressManager } from './progress-manager.js';
 import { initializeScaling } from './jenkins-scaling.js';
 import { initializeRNCreation, enableTriggerButton } from './jenkins-rn-creation.js';
@@ -28,6 +29,12 @@ class OCDApp {
         this.statusMessage = document.getElementById('status-message');
         this.manualInputNote = document.getElementById('manual-input-note');
         this.historyDropdown = document.getElementById('history-dropdown');
+        this.namespaceSelect = document.getElementById('deploy-namespace');
+        this.browseMicroservicesBtn = document.getElementById('browse-microservices-btn');
+        this.microserviceBrowser = document.getElementById('microservice-browser');
+        this.microserviceMappings = document.getElementById('microservice-mappings');
+        this.microserviceChooser = document.getElementById('microservice-chooser');
+        this.kubeTargetNote = document.getElementById('kube-target-note');
         this.deploymentSection = document.getElementById('deployment-section');
         this.progressOverview = document.getElementById('progress-overview');
         this.outputWindow = document.getElementById('output-window');
@@ -42,6 +49,15 @@ class OCDApp {
 
     initializeManagers() {
         this.historyManager = new HistoryManager(this.historyDropdown, this.folderInput);
+        this.kubeTarget = new KubeTargetSelector(
+            this.namespaceSelect,
+            this.browseMicroservicesBtn,
+            this.microserviceBrowser,
+            this.microserviceMappings,
+            this.kubeTargetNote,
+            this.folderInput,
+            this.microserviceChooser
+        );
         this.progressManager = new ProgressManager(
             this.progressOverview,
             this.progressBarFill,
@@ -168,6 +184,7 @@ class OCDApp {
     validateFolderPath() {
         const path = this.folderInput.value.trim();
         this.deployBtn.disabled = !path;
+        this.kubeTarget.handleFolderChange();
     }
 
     showStatus(message, type, persistent = false) {
@@ -240,6 +257,7 @@ class OCDApp {
         
         // Clear previous output and reset progress
         this.clearOutput();
+        this.kubeTarget.clearChooser();
         this.progressManager.reset();
         this.progressManager.initialize();
 
@@ -252,7 +270,7 @@ class OCDApp {
             const response = await fetch('/api/deploy/start', {
                 method: 'POST',
                 headers: { 'Content-Type': 'application/json' },
-                body: JSON.stringify({ folderPath: folderPath })
+                body: JSON.stringify({ folderPath: folderPath, ...this.kubeTarget.getTarget() })
             });
 
             if (!response.ok) {
@@ -314,6 +332,11 @@ class OCDApp {
                     this.progressManager.handleProgressUpdate(data);
                     break;
 
+                case 'mapping':
+                    console.log('Processing microservice choice:', data);
+                    this.kubeTarget.showChooser(data);
+                    break;
+
                 case 'complete':
                     console.log('Processing completion:', data);
                     this.handleDeploymentComplete(data);
@@ -431,6 +454,8 @@ class OCDApp {
 
     initialize() {
         this.validateFolderPath();
+        // Load the deploy namespaces; without cluster access the default is kept
+        this.kubeTarget.loadNamespaces();
         // Initialize Jenkins scaling functionality (for the scaling page)
         initializeScaling();
         // fetch version info and update UI footer
@@ -475,7 +500,7 @@ class OCDApp {
             this.loadBranches();
 
             // Set up event listeners
-            refreshBranchesBtn.addEventListener('click', () => this.loadBranches());
+            refreshBranchesBtn.addEventListener('click', () => this.loadBranches(true));
             branchSearch.addEventListener('click', () => this.toggleBranchDropdown());
             branchDropdownBtn.addEventListener('click', () => this.toggleBranchDropdown());
             // Click handler now managed by jenkins-rn-creation.js module
@@ -507,7 +532,7 @@ class OCDApp {
         }
     }
 
-    async loadBranches() {
+    async loadBranches(refresh = false) {
         const branchSearch = document.getElementById('branch-search');
         const branchLoading = document.getElementById('branch-loading');
         const branchError = document.getElementById('branch-error');
@@ -535,7 +560,7 @@ class OCDApp {
                 headers['X-Bitbucket-Token'] = credentials.token;
             }
 
-            const response = await fetch('/api/git/branches/customization', {
+            const response = await fetch('/api/git/branches/customization' + (refresh ? '?refresh=1' : ''), {
                 method: 'GET',
                 headers: headers
             });
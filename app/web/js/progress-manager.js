@@ -13,7 +13,8 @@ export class ProgressManager {
             settings: 'pending',
             build: 'pending',
             deploy: 'pending',
-            patch: 'pending'
+            patch: 'pending',
+            verify: 'pending'
         };
     }
 
@@ -26,7 +27,8 @@ export class ProgressManager {
             settings: 'pending',
             build: 'pending',
             deploy: 'pending',
-            patch: 'pending'
+            patch: 'pending',
+            verify: 'pending'
         };
 
         PROGRESS_STAGES.forEach(stage => {
@@ -113,6 +115,8 @@ export class ProgressManager {
                     this.updateStageProgress(data.stage, 'success');
                 } else if (data.status === 'error') {
                     this.updateStageProgress(data.stage, 'error');
+                } else if (data.status === 'warning') {
+                    this.updateStageProgress(data.stage, 'warning');
                 }
             } else {
                 this.updateProgressItem(data.stage, data.message, data.status, data.details);
@@ -128,7 +132,8 @@ export class ProgressManager {
             settings: { running: 7, success: 10 },
             build: { running: 30, success: 50 },
             deploy: { running: 60, success: 80 },
-            patch: { running: 85, success: 100 }
+            patch: { running: 85, success: 90 },
+            verify: { running: 92, success: 100 }
         };
 
         const textMap = {
@@ -136,7 +141,8 @@ export class ProgressManager {
             settings: { running: 'Updating Maven settings...', success: 'Maven settings updated' },
             build: { running: 'Building microservices...', success: 'Build completed' },
             deploy: { running: 'Creating Docker images...', success: 'Docker images created' },
-            patch: { running: 'Deploying to Kubernetes...', success: 'Deployment completed' }
+            patch: { running: 'Deploying to Kubernetes...', success: 'Deployment completed' },
+            verify: { running: 'Waiting for pods to become ready...', success: 'Rollout verified' }
         };
 
         if (progressMap[stage] && progressMap[stage][status]) {
@@ -161,7 +167,8 @@ export class ProgressManager {
             settings: 'pending',
             build: 'pending',
             deploy: 'pending',
-            patch: 'pending'
+            patch: 'pending',
+            verify: 'pending'
         };
         
         // Clear progress overview
@@ -163,6 +163,7 @@ async function testConnection() {
                 cluster_name: 'test-connection',
                 scale_type: 'up',
                 account: 'ATT',
+                reason: 'Connection test from Settings',
                 username: credentials.username,
                 token: credentials.token
             })
@@ -208,6 +208,12 @@ if [[ ${#changed_services[@]} -eq 0 && "$FORCE" != "true" ]]; then
     exit 0
 fi
 
+# Every service is deployed to the backend microservice; stop before building
+# when OCD could not tell which one that is
+if [[ ${#changed_services[@]} -gt 0 ]]; then
+    stop_on_ambiguous_microservices "customization"
+fi
+
 # Confirmation prompt if requested
 if [[ "$CONFIRM" == "true" ]]; then
     confirm_deployment "${changed_services[@]}"
@@ -364,34 +364,43 @@ update_kubernetes_microservice() {
 
     local found_service=""
 
-    # Search for microservice by pattern matching instead of hardcoded guesses
-    write_colored_output "Searching for microservice containing '$microservice_name' in namespace '$namespace'..." "blue"
+    # A microservice picked in OCD replaces the name matching below
+    if found_service=$(microservice_override "$microservice_name"); then
+        write_colored_output "Using microservice selected in OCD: $found_service" "green"
+        update_kubernetes_microservice_generic "$image_tag" "$namespace" "$found_service" "(copy-application-files|source-code)" "application"
+        return
+    fi
+
+    # Without a mapping from OCD only an unambiguous name is used: the
+    # microservice named like the service, else the only one containing its name
+    write_colored_output "Searching for microservice '$microservice_name' in namespace '$namespace'..." "blue"
 
-    # Get all microservices and filter by pattern
     local all_services=$(bash -l -c "proxy on 2>/dev/null || true && kubectl get microservice -n '$namespace' --no-headers -o custom-columns=NAME:.metadata.name" 2>/dev/null || echo "")
 
     if [[ -n "$all_services" ]]; then
-        # Try to find a service that contains key parts of the microservice name
-        local key_parts=($(echo "$microservice_name" | tr '-' ' '))
-
+        local containing=()
         for service in $all_services; do
-            local match_count=0
-            for part in "${key_parts[@]}"; do
-                if [[ "$service" == *"$part"* ]]; then
-                    ((match_count++))
-                fi
-            done
-
-            # If most parts match, use this service
-            if [[ $match_count -ge $((${#key_parts[@]} - 1)) ]]; then
+            if [[ "$service" == "$microservice_name" ]]; then
                 found_service="$service"
-                write_colored_output "Found microservice: $found_service (matched $match_count/${#key_parts[@]} parts)" "green"
                 break
             fi
+            if [[ "-$service-" == *"-$microservice_name-"* ]]; then
+                containing+=("$service")
+            fi
         done
+
+        if [[ -z "$found_service" && ${#containing[@]} -eq 1 ]]; then
+            found_service="${containing[0]}"
+        elif [[ -z "$found_service" && ${#containing[@]} -gt 1 ]]; then
+            write_colored_output "Error: Several microservices match '$microservice_name': ${containing[*]}" "red"
+            write_colored_output "Choose the microservice in OCD before deploying" "red"
+            return 1
+        fi
     fi
 
-    if [[ -z "$found_service" ]]; then
+    if [[ -n "$found_service" ]]; then
+        write_colored_output "Found microservice: $found_service" "green"
+    else
         write_colored_output "Error: Could not find microservice matching '$microservice_name' in namespace '$namespace'" "red"
         write_colored_output "Available microservices in namespace:" "red"
         if [[ -n "$all_services" ]]; then
@@ -504,6 +513,9 @@ if [[ ${#changed_microservices[@]} -eq 0 && "$FORCE" != "true" ]]; then
     exit 0
 fi
 
+# Stop before building when OCD could not tell which microservice to patch
+stop_on_ambiguous_microservices "${changed_microservices[@]}"
+
 # Confirmation prompt if requested
 if [[ "$CONFIRM" == "true" ]]; then
     confirm_deployment "${changed_microservices[@]}"
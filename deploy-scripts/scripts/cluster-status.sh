@@ -0,0 +1,70 @@
+#!/bin/bash
+# Print the scaling state of an EKS cluster for post-scale verification:
+# node group sizes, node readiness and pod readiness in one namespace.
+# Connects with `aws eks update-kubeconfig`, like get-helm-charts.sh, into a
+# private kubeconfig.
+#
+# Usage: cluster-status.sh <cluster_name> [--namespace dop] [--region us-east-1]
+#
+# Output sections, each starting with a "### <name>" line:
+#   ### nodegroups   one `aws eks describe-nodegroup` JSON document per node group
+#   ### nodes        <name> TAB <Ready condition status>
+#   ### pods         <name> TAB <phase> TAB <Ready condition status>
+#   ### error <section> <message>   when a section could not be read
+
+proxy on 2>/dev/null || true
+
+CLUSTER_NAME=""
+NAMESPACE="dop"
+REGION=""
+while [[ $# -gt 0 ]]; do
+  case "$1" in
+    --namespace) NAMESPACE="$2"; shift 2 ;;
+    --region) REGION="$2"; shift 2 ;;
+    -*) echo "Unknown arg: $1" 1>&2; exit 2 ;;
+    *) CLUSTER_NAME="$1"; shift ;;
+  esac
+done
+
+if [[ -z "$CLUSTER_NAME" ]]; then
+  echo "Usage: $0 <cluster_name> [--namespace dop] [--region us-east-1]" 1>&2
+  exit 2
+fi
+
+# A private kubeconfig keeps concurrent checks of different clusters apart
+TMP_DIR="$(mktemp -d)"
+trap 'rm -rf "$TMP_DIR"' EXIT
+export KUBECONFIG="$TMP_DIR/kubeconfig"
+
+REGION_ARGS=()
+if [[ -n "$REGION" ]]; then
+  REGION_ARGS=(--region "$REGION")
+fi
+
+echo "### nodegroups"
+if nodegroups="$(aws eks list-nodegroups "${REGION_ARGS[@]}" --cluster-name "$CLUSTER_NAME" --query 'nodegroups[]' --output text 2>"$TMP_DIR/nodegroups.err")"; then
+  for nodegroup in $nodegroups; do
+    [[ "$nodegroup" == "None" ]] && continue
+    aws eks describe-nodegroup "${REGION_ARGS[@]}" --cluster-name "$CLUSTER_NAME" --nodegroup-name "$nodegroup" --output json 2>/dev/null
+  done
+else
+  echo "### error nodegroups $(tr '\n' ' ' < "$TMP_DIR/nodegroups.err")"
+fi
+
+# A scaled-down cluster may have no reachable API; report it and keep the node group data
+if ! kubeconfig_output="$(aws eks update-kubeconfig "${REGION_ARGS[@]}" --name "$CLUSTER_NAME" 2>&1)"; then
+  echo "### error kubeconfig $(echo "$kubeconfig_output" | tr '\n' ' ')"
+  exit 0
+fi
+
+echo "### nodes"
+if ! kubectl get nodes --request-timeout=20s \
+    -o jsonpath='{range .items[*]}{.metadata.name}{"\t"}{.status.conditions[?(@.type=="Ready")].status}{"\n"}{end}' 2>"$TMP_DIR/nodes.err"; then
+  echo "### error nodes $(tr '\n' ' ' < "$TMP_DIR/nodes.err")"
+fi
+
+echo "### pods"
+if ! kubectl get pods -n "$NAMESPACE" --request-timeout=20s \
+    -o jsonpath='{range .items[*]}{.metadata.name}{"\t"}{.status.phase}{"\t"}{.status.conditions[?(@.type=="Ready")].status}{"\n"}{end}' 2>"$TMP_DIR/pods.err"; then
+  echo "### error pods $(tr '\n' ' ' < "$TMP_DIR/pods.err")"
+fi
@@ -31,9 +31,9 @@ get_image_versions_from_cluster() {
     
     write_colored_output "Getting image versions from cluster: $cluster_name" "blue"
     
-    # Update kubeconfig to connect to the specified cluster
+    # Connect to the specified cluster through a private kubeconfig
     write_colored_output "Updating kubeconfig for cluster: $cluster_name" "blue"
-    if ! aws eks update-kubeconfig --name "$cluster_name" > /dev/null 2>&1; then
+    if ! use_private_kubeconfig "$cluster_name"; then
         write_colored_output "Error: Failed to update kubeconfig for cluster: $cluster_name" "red"
         exit 1
     fi
@@ -8,6 +8,7 @@ BRANCH=""
 MESSAGE=""
 USERNAME=""
 TOKEN=""
+NEW_BRANCH=""
 
 while [[ $# -gt 0 ]]; do
   case "$1" in
@@ -16,6 +17,7 @@ while [[ $# -gt 0 ]]; do
     --message) MESSAGE="$2"; shift 2 ;;
     --username) USERNAME="$2"; shift 2 ;;
     --token) TOKEN="$2"; shift 2 ;;
+    --new-branch) NEW_BRANCH="$2"; shift 2 ;;
     *) echo "Unknown arg: $1" 1>&2; exit 2 ;;
   esac
 done
@@ -33,6 +35,12 @@ if [[ -n "$USERNAME" && -n "$TOKEN" ]]; then
   AUTH_ARGS=(-c "http.extraHeader=Authorization: Basic $BASIC")
 fi
 
+# With --new-branch the commit goes to a fresh branch cut from BRANCH instead
+if [[ -n "$NEW_BRANCH" ]]; then
+  git checkout -b "$NEW_BRANCH"
+  BRANCH="$NEW_BRANCH"
+fi
+
 echo "[HF][git-push] REPO_DIR=$REPO_DIR BRANCH=$BRANCH" 1>&2
 echo "[HF][git-push] NO_PROXY=${NO_PROXY:-}" 1>&2
 
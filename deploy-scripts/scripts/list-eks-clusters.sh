@@ -1,5 +1,54 @@
 #!/bin/bash
-# List EKS clusters with proper proxy setup
+# List EKS clusters as a stream of `aws eks describe-cluster` JSON documents,
+# one per cluster, across the given regions (default: the configured region)
+#
+# Usage: list-eks-clusters.sh [--regions us-east-1,eu-west-1]
 
-# Execute AWS CLI command with proxy setup like other scripts
-bash -l -c "proxy on 2>/dev/null || true && aws eks list-clusters --query 'clusters[*]' --output text" 2>/dev/null
\ No newline at end of file
+proxy on 2>/dev/null || true
+
+REGIONS=""
+while [[ $# -gt 0 ]]; do
+  case "$1" in
+    --regions) REGIONS="$2"; shift 2 ;;
+    *) echo "Unknown arg: $1" 1>&2; exit 2 ;;
+  esac
+done
+
+if [[ -z "$REGIONS" ]]; then
+  REGIONS="${AWS_REGION:-${AWS_DEFAULT_REGION:-$(aws configure get region 2>/dev/null || true)}}"
+fi
+if [[ -z "$REGIONS" ]]; then
+  echo "ERROR: no AWS region configured; pass --regions or set OCD_EKS_REGIONS" 1>&2
+  exit 1
+fi
+
+TMP_DIR="$(mktemp -d)"
+trap 'rm -rf "$TMP_DIR"' EXIT
+
+failed=0
+i=0
+for region in ${REGIONS//,/ }; do
+  if ! names="$(aws eks list-clusters --region "$region" --query 'clusters[]' --output text)"; then
+    echo "ERROR: failed to list clusters in $region" 1>&2
+    failed=$((failed + 1))
+    continue
+  fi
+  # EKS cluster names never contain whitespace, so the text output splits safely
+  for name in $names; do
+    [[ "$name" == "None" ]] && continue
+    i=$((i + 1))
+    (
+      aws eks describe-cluster --region "$region" --name "$name" --output json 2>/dev/null ||
+        printf '{"cluster":{"name":"%s","status":"UNKNOWN"},"region":"%s"}\n' "$name" "$region"
+    ) > "$TMP_DIR/$i.json" &
+  done
+done
+wait
+
+cat "$TMP_DIR"/*.json 2>/dev/null || true
+
+# Fail only when no region could be listed
+region_count=$(echo ${REGIONS//,/ } | wc -w)
+if [[ $failed -eq $region_count ]]; then
+  exit 1
+fi
@@ -5,6 +5,44 @@
 # KUBERNETES UPDATE FUNCTIONS
 # =============================================================================
 
+# Print the microservice chosen in OCD for a service, from OCD_MICROSERVICE_MAP
+# ("service=microservice,..."); fails when the service has no override
+microservice_override() {
+    local service_name="$1"
+    local entry
+    local IFS=','
+
+    for entry in $OCD_MICROSERVICE_MAP; do
+        if [[ "${entry%%=*}" == "$service_name" && -n "${entry#*=}" ]]; then
+            echo "${entry#*=}"
+            return 0
+        fi
+    done
+    return 1
+}
+
+# Stop the deploy when OCD found several microservices a changed service could
+# be patched into (OCD_AMBIGUOUS_SERVICES, comma separated). The marker lines
+# tell OCD to ask which one is meant.
+stop_on_ambiguous_microservices() {
+    local service
+    local ambiguous=false
+
+    for service in "$@"; do
+        if [[ ",$OCD_AMBIGUOUS_SERVICES," == *",$service,"* ]]; then
+            write_colored_output "Several microservices in namespace $NAMESPACE could receive $service" "red"
+            echo "OCD_AMBIGUOUS service=$service namespace=$NAMESPACE"
+            ambiguous=true
+        fi
+    done
+
+    if [[ "$ambiguous" == "true" ]]; then
+        write_colored_output "Choose the microservice to patch in OCD and deploy again" "red"
+        exit 1
+    fi
+}
+
+# Print the backend microservice of a namespace, only when there is exactly one
 find_backend_microservice() {
     local namespace="$1"
     
@@ -14,23 +52,14 @@ find_backend_microservice() {
     if [[ -z "$backend_microservices" ]]; then
         return 1
     fi
-    
-    # Priority order for backend microservice matching
-    local priority_patterns=(
-        "dop-backend-oso"
-        "dop-backend"
-        "backend-oso" 
-        "oso-backend"
-        "backend"
-    )
-    
-    # Check priority patterns first
-    for pattern in "${priority_patterns[@]}"; do
-        echo "$backend_microservices" | grep -q "^$pattern$" && echo "$pattern" && return 0
-    done
-    
-    # If no exact match, return the first backend microservice found
-    echo "$backend_microservices" | head -n 1
+
+    if [[ $(echo "$backend_microservices" | wc -l) -gt 1 ]]; then
+        write_colored_output "Error: Several backend microservices in namespace $namespace: $(echo $backend_microservices)" "red" >&2
+        write_colored_output "Choose the microservice in OCD before deploying" "red" >&2
+        return 1
+    fi
+
+    echo "$backend_microservices"
     return 0
 }
 
@@ -66,6 +95,63 @@ find_init_container_by_pattern() {
     echo "CONTAINERS_END"
 }
 
+# Ask OCD to patch the init container through the Kubernetes API and wait for
+# its answer in OCD_PATCH_REPLY_DIR: "ok container=<name> previous=<image>" or
+# "error <message>". OCD checks the container's name and current image in the
+# same patch, so a concurrent change is not overwritten, and refuses when its
+# client talks to another API server than this shell's kubectl.
+OCD_PATCH_ID=0
+request_ocd_patch() {
+    local image_tag="$1"
+    local namespace="$2"
+    local microservice_name="$3"
+    local container_pattern="$4"
+    local description="$5"
+
+    local server=$(bash -l -c "proxy on 2>/dev/null || true && kubectl config view --minify -o jsonpath='{.clusters[0].cluster.server}'" 2>/dev/null)
+    if [[ -z "$server" ]]; then
+        write_colored_output "Error: Could not read the API server of the current kubectl context" "red"
+        return 1
+    fi
+
+    OCD_PATCH_ID=$((OCD_PATCH_ID + 1))
+    local reply_file="$OCD_PATCH_REPLY_DIR/$OCD_PATCH_ID"
+    echo "OCD_PATCH id=$OCD_PATCH_ID namespace=$namespace microservice=$microservice_name pattern=$container_pattern image=$image_tag server=$server"
+
+    local waited=0
+    while [[ ! -f "$reply_file" ]]; do
+        if [[ $waited -ge ${OCD_PATCH_TIMEOUT:-180} ]]; then
+            write_colored_output "Error: OCD did not answer the patch request for $microservice_name" "red"
+            return 1
+        fi
+        sleep 1
+        waited=$((waited + 1))
+    done
+
+    local reply
+    reply=$(cat "$reply_file")
+    if [[ "${reply%% *}" != "ok" ]]; then
+        write_colored_output "Failed to patch microservice: ${reply#error }" "red"
+        return 1
+    fi
+
+    local container_name="" previous_image="" field
+    for field in ${reply#ok}; do
+        case "$field" in
+            container=*) container_name="${field#container=}" ;;
+            previous=*) previous_image="${field#previous=}" ;;
+        esac
+    done
+
+    write_colored_output "Current image: $previous_image" "blue"
+    write_colored_output "Microservice $microservice_name patched with new $description image" "green"
+    write_colored_output "Updated image: $image_tag" "green"
+
+    # Tell OCD which pods to watch for the rollout verification
+    echo "OCD_PATCHED namespace=$namespace microservice=$microservice_name container=$container_name image=$image_tag"
+    return 0
+}
+
 update_kubernetes_microservice_generic() {
     local image_tag="$1"
     local namespace="$2"
@@ -75,6 +161,12 @@ update_kubernetes_microservice_generic() {
 
     write_colored_output "Updating Kubernetes microservice $microservice_name with $description image: $image_tag" "blue"
 
+    # Run from OCD, the patch goes through its Kubernetes client
+    if [[ -n "$OCD_PATCH_REPLY_DIR" ]]; then
+        request_ocd_patch "$image_tag" "$namespace" "$microservice_name" "$container_pattern" "$description"
+        return
+    fi
+
     # Get the initContainer index using the generic function
     local container_info=$(find_init_container_by_pattern "$microservice_name" "$namespace" "$container_pattern")
     
@@ -119,6 +211,10 @@ update_kubernetes_microservice_generic() {
         local updated_image=$(bash -l -c "proxy on 2>/dev/null || true && kubectl get microservice '$microservice_name' -n '$namespace' -o jsonpath='{.spec.template.spec.initContainers[$init_container_index].image}'" 2>/dev/null)
         write_colored_output "Updated image: $updated_image" "green"
 
+        # Tell OCD which pods to watch for the rollout verification
+        local container_name=$(echo "$init_containers_output" | sed -n "$((init_container_index + 1))p")
+        echo "OCD_PATCHED namespace=$namespace microservice=$microservice_name container=$container_name image=$image_tag"
+
         return 0
     else
         write_colored_output "Failed to patch microservice" "red"
@@ -130,14 +226,20 @@ update_kubernetes_microservice_customization() {
     local image_tag="$1"
     local namespace="$2"
 
+    local microservice_name
+    if microservice_name=$(microservice_override "customization"); then
+        write_colored_output "Using backend microservice selected in OCD: $microservice_name" "green"
+        update_kubernetes_microservice_generic "$image_tag" "$namespace" "$microservice_name" "customization" "customization"
+        return
+    fi
+
     write_colored_output "Finding backend microservice in namespace $namespace..." "blue"
-    
+
     # Find the best backend microservice match
-    local microservice_name
     microservice_name=$(find_backend_microservice "$namespace")
-    
+
     if [[ -z "$microservice_name" ]]; then
-        write_colored_output "Error: Could not find any microservice containing 'backend' in namespace $namespace" "red"
+        write_colored_output "Error: Could not find a single microservice containing 'backend' in namespace $namespace" "red"
         return 1
     fi
 
@@ -145,4 +247,21 @@ update_kubernetes_microservice_customization() {
     
     # Use the generic function to update the customization container
     update_kubernetes_microservice_generic "$image_tag" "$namespace" "$microservice_name" "customization" "customization"
+}
+
+# =============================================================================
+# KUBECONFIG FUNCTIONS
+# =============================================================================
+
+use_private_kubeconfig() {
+    local cluster_name="$1"
+    
+    # Connect through a throwaway kubeconfig so the user's ~/.kube/config and
+    # current context are left alone, and concurrent queries of different
+    # clusters cannot overwrite each other's context
+    PRIVATE_KUBECONFIG_DIR="$(mktemp -d)" || return 1
+    trap 'rm -rf "$PRIVATE_KUBECONFIG_DIR"' EXIT
+    export KUBECONFIG="$PRIVATE_KUBECONFIG_DIR/kubeconfig"
+    
+    aws eks update-kubeconfig --name "$cluster_name" --kubeconfig "$KUBECONFIG" > /dev/null 2>&1
 }
\ No newline at end of file